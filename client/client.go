@@ -0,0 +1,222 @@
+// Package client 为 rss2json 服务的 Go 客户端：构造查询参数、附加 API Key，
+// 并将响应（含错误包络）解码为 model.Response 或 *Error。
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+// maxResponseBytes 为读取单个响应体的上限，防止异常响应占满内存。
+const maxResponseBytes = 64 << 20
+
+// Client 调用 rss2json 服务。零值不可用，请使用 New 构造。
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// Option 配置 Client。
+type Option func(*Client)
+
+// WithAPIKey 以 Authorization: Bearer 发送服务端配置的 API_KEY。
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = strings.TrimSpace(key)
+	}
+}
+
+// WithHTTPClient 替换默认的 http.Client（30 秒超时）。
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// New 构造指向 baseURL（如 https://rss.example.com）的客户端。
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ConvertOptions 对应 /api/v1/rss2json 的常用查询参数，零值表示使用服务端默认值。
+// 其余参数可通过 Query 传入，与前面的字段重复时以 Query 为准。
+type ConvertOptions struct {
+	// Count 为返回的条目数，受服务端 DEFAULT_COUNT/MAX_COUNT 约束。
+	Count int
+	// Cursor 为上次响应中 meta.cursor 的值，只返回更新的条目。
+	Cursor string
+	// OmitContent 为 true 时省略条目的 content 与 description（include_content=0）。
+	OmitContent bool
+	// Sanitize 为 true 时移除内容中的脚本等元素。
+	Sanitize bool
+	// StripTracking 为 true 时移除链接中的跟踪参数。
+	StripTracking bool
+	// Prefer 为 content 或 description，为条目附加统一的 body 字段。
+	Prefer string
+	// ContentFormat 为 html 或 markdown。
+	ContentFormat string
+	// SummaryLen 为条目 summary 的最大字符数。
+	SummaryLen int
+	// Query 为其他查询参数。
+	Query url.Values
+}
+
+// values 将选项编码为查询参数。
+func (o ConvertOptions) values(feedURL string) url.Values {
+	q := url.Values{}
+	q.Set("url", feedURL)
+	if o.Count > 0 {
+		q.Set("count", strconv.Itoa(o.Count))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	if o.OmitContent {
+		q.Set("include_content", "0")
+	}
+	if o.Sanitize {
+		q.Set("sanitize", "1")
+	}
+	if o.StripTracking {
+		q.Set("strip_tracking", "1")
+	}
+	if o.Prefer != "" {
+		q.Set("prefer", o.Prefer)
+	}
+	if o.ContentFormat != "" {
+		q.Set("content_format", o.ContentFormat)
+	}
+	if o.SummaryLen > 0 {
+		q.Set("summary_len", strconv.Itoa(o.SummaryLen))
+	}
+	for key, values := range o.Query {
+		q[key] = values
+	}
+	return q
+}
+
+// Error 为服务端返回的错误响应。Code 为稳定的错误码（如 fetch_failed、dns_error），
+// 客户端应据此区分失败原因；响应不是 JSON 错误包络时 Code 为空。
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// UpstreamStatus 为上游返回的 HTTP 状态码，仅 upstream_status 时非 0。
+	UpstreamStatus int
+	// RetryAfter 为建议重试前等待的时长，未知时为 0。
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("rss2json: HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("rss2json: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// CodeOf 返回错误中 *Error 的错误码，其他错误返回空串。
+func CodeOf(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// Convert 调用 GET /api/v1/rss2json 转换 feedURL。
+func (c *Client) Convert(ctx context.Context, feedURL string, opts ConvertOptions) (model.Response, error) {
+	var resp model.Response
+	err := c.get(ctx, "/api/v1/rss2json", opts.values(feedURL), &resp)
+	return resp, err
+}
+
+// Health 为 /health 的响应。
+type Health struct {
+	Status string `json:"status"`
+	// Uptime 为服务已运行的秒数。
+	Uptime float64 `json:"uptime"`
+}
+
+// Health 调用 GET /health 检查服务是否存活。
+func (c *Client) Health(ctx context.Context) (Health, error) {
+	var health Health
+	err := c.get(ctx, "/health", nil, &health)
+	return health, err
+}
+
+// get 发送 GET 请求，2xx 时将响应体解码到 out，否则返回 *Error。
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeError(resp, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("rss2json: decode response: %w", err)
+	}
+	return nil
+}
+
+// decodeError 将非 2xx 响应解码为 *Error；不是 JSON 错误包络时以状态文本作为 Message。
+func decodeError(resp *http.Response, body []byte) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var envelope model.Response
+	if json.Unmarshal(body, &envelope) == nil {
+		switch {
+		case envelope.Error != nil:
+			apiErr.Code = envelope.Error.Code
+			apiErr.Message = envelope.Error.Message
+			if details := envelope.Error.Details; details != nil {
+				apiErr.UpstreamStatus = details.UpstreamStatus
+				apiErr.RetryAfter = time.Duration(details.RetryAfter) * time.Second
+			}
+		case envelope.Message != "":
+			apiErr.Message = envelope.Message
+		}
+	}
+	if apiErr.RetryAfter == 0 {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/server"
+)
+
+const sampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Client Feed</title>
+    <link>https://example.com/</link>
+    <item>
+      <title>First</title>
+      <link>https://example.com/1</link>
+      <guid>https://example.com/1</guid>
+      <author>alice@example.com (Alice)</author>
+      <description>&lt;p&gt;first item&lt;/p&gt;</description>
+      <pubDate>Mon, 05 Jan 2026 10:00:00 GMT</pubDate>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>https://example.com/2</link>
+      <guid>https://example.com/2</guid>
+    </item>
+  </channel>
+</rss>`
+
+// newTestService 启动本地 feed 服务器与使用真实 Handler 的 rss2json 服务。
+func newTestService(t *testing.T, opts server.Options) (feeds, api *httptest.Server) {
+	t.Helper()
+	feeds = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rss" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(sampleRSS))
+	}))
+	t.Cleanup(feeds.Close)
+	api = httptest.NewServer(server.NewHandler(opts))
+	t.Cleanup(api.Close)
+	return feeds, api
+}
+
+func TestConvert(t *testing.T) {
+	feeds, api := newTestService(t, server.Options{APIKey: "secret"})
+	c := New(api.URL+"/", WithAPIKey("secret"))
+
+	resp, err := c.Convert(context.Background(), feeds.URL+"/rss", ConvertOptions{Count: 1, OmitContent: true, Query: url.Values{"summary_len": {"5"}}})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if resp.Status != "ok" || resp.Feed == nil || resp.Feed.Title != "Client Feed" || len(resp.Items) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	item := resp.Items[0]
+	if item.Title != "First" || item.Description != "" || item.Summary != "first…" {
+		t.Fatalf("expected count, include_content and summary_len applied, got %+v", item.Item)
+	}
+	if item.Author == nil || item.Author.Name != "Alice" || item.PublishedParsed == nil || item.PublishedParsed.Day() != 5 {
+		t.Fatalf("expected author and published time decoded, got %+v %v", item.Author, item.PublishedParsed)
+	}
+	if resp.Meta == nil || resp.Meta.TotalItems != 2 || resp.Meta.Cursor == "" {
+		t.Fatalf("unexpected meta %+v", resp.Meta)
+	}
+
+	if _, err := c.Convert(context.Background(), feeds.URL+"/rss", ConvertOptions{Cursor: resp.Meta.Cursor}); err != nil {
+		t.Fatalf("convert with cursor: %v", err)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	feeds, api := newTestService(t, server.Options{APIKey: "secret"})
+
+	_, err := New(api.URL).Convert(context.Background(), feeds.URL+"/rss", ConvertOptions{})
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "unauthorized" {
+		t.Fatalf("expected unauthorized error, got %#v", err)
+	}
+
+	c := New(api.URL, WithAPIKey("secret"))
+	_, err = c.Convert(context.Background(), feeds.URL+"/missing", ConvertOptions{})
+	apiErr, ok = err.(*Error)
+	if !ok || apiErr.Code != "upstream_status" || apiErr.UpstreamStatus != http.StatusNotFound || apiErr.Message == "" {
+		t.Fatalf("expected upstream_status error, got %#v", err)
+	}
+
+	if _, err := c.Convert(context.Background(), "ftp://example.com/rss", ConvertOptions{}); CodeOf(err) != "invalid_url" {
+		t.Fatalf("expected invalid_url, got %v", err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	_, api := newTestService(t, server.Options{})
+	health, err := New(api.URL).Health(context.Background())
+	if err != nil || health.Status != "ok" {
+		t.Fatalf("unexpected health %+v err=%v", health, err)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer down.Close()
+	_, err = New(down.URL).Health(context.Background())
+	if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusBadGateway || apiErr.Code != "" {
+		t.Fatalf("expected non-JSON error wrapped, got %#v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/internal/server"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// configFileEnv 指向 KEY=VALUE 格式的配置文件，键名与环境变量相同。启动时覆盖同名环境变量，
+// 收到 SIGHUP 或 POST /admin/reload 时重新读取。
+const configFileEnv = "CONFIG_FILE"
+
+// reloadableKeys 为无需重启即可生效的配置项，其余配置项变化时只报告需要重启。
+var reloadableKeys = map[string]bool{
+	"API_KEY":            true,
+	"CALLBACK_ALLOWLIST": true,
+	"CACHE_TTL":          true,
+	"RSS_PROXY":          true,
+}
+
+// configReloader 重新读取配置文件，校验通过后原子替换服务端的可热更新配置与出站代理。
+type configReloader struct {
+	path string
+	// base 为应用配置文件前的环境变量，文件中删除的配置项回退到这里的值。
+	base map[string]string
+
+	mu sync.Mutex
+	// file 为当前生效的配置文件取值：需要重启的配置项保留上次生效的值，直到重启前每次 reload 都会继续报告。
+	file    map[string]string
+	runtime *server.Runtime
+	// cacheEnabled 为启动时是否创建了缓存；未创建时 CACHE_TTL 从 0 调大需要重启。
+	cacheEnabled bool
+}
+
+// applyConfigFile 读取 CONFIG_FILE 并写入环境变量，使后续按环境变量读取的配置以文件为准；
+// 未配置时返回 nil。文件不可读、格式错误或可热更新的配置项不合法时返回错误。
+func applyConfigFile() (*configReloader, error) {
+	path := strings.TrimSpace(os.Getenv(configFileEnv))
+	if path == "" {
+		return nil, nil
+	}
+	c := &configReloader{path: path, base: environMap()}
+	file, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := runtimeConfigFrom(c.lookup(file)); err != nil {
+		return nil, err
+	}
+	for key, val := range file {
+		if err := os.Setenv(key, val); err != nil {
+			return nil, err
+		}
+	}
+	// rss 包初始化时已按原环境变量构造出站客户端，按文件中的代理与连接池等配置重建。
+	if err := rss.SetProxy(rss.Proxy()); err != nil {
+		return nil, err
+	}
+	c.file = file
+	return c, nil
+}
+
+// attach 绑定启动后构造的 Runtime，此后 reload 才会生效。
+func (c *configReloader) attach(rt *server.Runtime, cacheEnabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runtime = rt
+	c.cacheEnabled = cacheEnabled
+}
+
+// reload 重新读取配置文件：校验失败时返回错误并保留原配置；成功时替换可热更新的配置项，
+// 并列出已变化但需要重启才能生效的配置项（如 LISTEN_ADDR）。
+func (c *configReloader) reload() (model.ConfigReload, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := readConfigFile(c.path)
+	if err != nil {
+		return model.ConfigReload{}, err
+	}
+	cfg, proxy, err := runtimeConfigFrom(c.lookup(file))
+	if err != nil {
+		return model.ConfigReload{}, err
+	}
+	result := model.ConfigReload{Applied: []string{}, RestartRequired: []string{}}
+	prev, next := c.lookup(c.file), c.lookup(file)
+	for _, key := range changedKeys(c.file, file, prev, next) {
+		switch {
+		case key == "CACHE_TTL" && !c.cacheEnabled && cfg.CacheTTL > 0:
+			cfg.CacheTTL = 0
+			result.RestartRequired = append(result.RestartRequired, key)
+		case reloadableKeys[key]:
+			result.Applied = append(result.Applied, key)
+		default:
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	if proxy != rss.Proxy() {
+		if err := rss.SetProxy(proxy); err != nil {
+			return model.ConfigReload{}, err
+		}
+	}
+	if c.runtime != nil {
+		c.runtime.Store(cfg)
+	}
+	for _, key := range result.Applied {
+		_ = os.Setenv(key, next(key))
+	}
+	for _, key := range result.RestartRequired {
+		if val, ok := c.file[key]; ok {
+			file[key] = val
+		} else {
+			delete(file, key)
+		}
+	}
+	c.file = file
+	log.Printf("[config] reloaded %s: applied=%v restart_required=%v", c.path, result.Applied, result.RestartRequired)
+	return result, nil
+}
+
+// watchSIGHUP 收到 SIGHUP 时重新加载配置文件，失败时记录原因并保留原配置。
+func (c *configReloader) watchSIGHUP() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for range ch {
+			if _, err := c.reload(); err != nil {
+				log.Printf("[config] reload %s failed, keeping previous configuration: %v", c.path, err)
+			}
+		}
+	}()
+}
+
+// lookup 返回以文件为准、文件中没有时取启动环境变量的取值函数。
+func (c *configReloader) lookup(file map[string]string) func(string) string {
+	return func(key string) string {
+		if val, ok := file[key]; ok {
+			return val
+		}
+		return c.base[key]
+	}
+}
+
+// changedKeys 返回在两份配置文件中出现过、且生效值发生变化的配置项，按名称排序。
+func changedKeys(prevFile, nextFile map[string]string, prev, next func(string) string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, file := range []map[string]string{prevFile, nextFile} {
+		for key := range file {
+			if !seen[key] && prev(key) != next(key) {
+				keys = append(keys, key)
+			}
+			seen[key] = true
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runtimeConfigFrom 校验并构造可热更新的配置，返回的 proxy 为 RSS_PROXY 的取值。
+func runtimeConfigFrom(get func(string) string) (server.RuntimeConfig, string, error) {
+	cfg := server.RuntimeConfig{
+		APIKey:            strings.TrimSpace(get("API_KEY")),
+		CallbackAllowlist: splitList(get("CALLBACK_ALLOWLIST")),
+	}
+	var errs []error
+	if raw := strings.TrimSpace(get("CACHE_TTL")); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			errs = append(errs, fmt.Errorf("CACHE_TTL must be a non-negative number of seconds, got %q", raw))
+		}
+		cfg.CacheTTL = time.Duration(seconds) * time.Second
+	}
+	proxy := strings.TrimSpace(get("RSS_PROXY"))
+	if err := rss.ValidateProxy(proxy); err != nil {
+		errs = append(errs, err)
+	}
+	return cfg, proxy, errors.Join(errs...)
+}
+
+// readConfigFile 解析配置文件：每行一个 KEY=VALUE，忽略空行与 # 开头的注释，值两侧成对的引号会被去除。
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		values[key] = val
+	}
+	return values, scanner.Err()
+}
+
+// environMap 返回当前环境变量的副本。
+func environMap() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, val, ok := strings.Cut(kv, "="); ok {
+			env[key] = val
+		}
+	}
+	return env
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/server"
+	"github.com/zdev0x/rss2json/model"
+)
+
+func writeConfig(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rss2json.env")
+	writeConfig(t, path, "# comment", "", "API_KEY=abc", `export CALLBACK_ALLOWLIST="hooks.example.com, b.example.com"`, "EMPTY=")
+	values, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if values["API_KEY"] != "abc" || values["CALLBACK_ALLOWLIST"] != "hooks.example.com, b.example.com" || len(values) != 3 {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	writeConfig(t, path, "API_KEY=abc", "not a pair")
+	if _, err := readConfigFile(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Fatalf("expected error with line number, got %v", err)
+	}
+}
+
+// reloadRequest 以给定 API Key 请求 /admin/reload，返回状态码与解码后的结果。
+func reloadRequest(t *testing.T, baseURL, key string) (int, model.ConfigReload) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, baseURL+"/admin/reload", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("reload request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result model.ConfigReload
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func healthStatus(t *testing.T, baseURL, key string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/health", nil)
+	req.Header.Set("X-Api-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestConfigReloadSwapsKeysWithoutRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rss2json.env")
+	writeConfig(t, path, "API_KEY=old-key", "LISTEN_ADDR=:8080")
+	file, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	config := &configReloader{path: path, base: map[string]string{}, file: file}
+	rt := server.NewRuntime(server.RuntimeConfig{APIKey: "old-key"})
+	config.attach(rt, false)
+	srv := httptest.NewServer(server.NewHandler(server.Options{Runtime: rt, Reload: config.reload}))
+	defer srv.Close()
+
+	writeConfig(t, path, "API_KEY=new-key", "LISTEN_ADDR=:9090", "CACHE_TTL=60")
+	code, result := reloadRequest(t, srv.URL, "old-key")
+	if code != http.StatusOK {
+		t.Fatalf("expected reload to succeed, got %d", code)
+	}
+	if strings.Join(result.Applied, ",") != "API_KEY" || strings.Join(result.RestartRequired, ",") != "CACHE_TTL,LISTEN_ADDR" {
+		t.Fatalf("unexpected reload result %+v", result)
+	}
+	if healthStatus(t, srv.URL, "old-key") != http.StatusUnauthorized || healthStatus(t, srv.URL, "new-key") != http.StatusOK {
+		t.Fatal("expected the new API key to replace the old one")
+	}
+
+	// 未重启前再次 reload，需要重启的配置项仍然报告。
+	code, result = reloadRequest(t, srv.URL, "new-key")
+	if code != http.StatusOK || len(result.Applied) != 0 || strings.Join(result.RestartRequired, ",") != "CACHE_TTL,LISTEN_ADDR" {
+		t.Fatalf("expected pending restart keys to stay reported, got %d %+v", code, result)
+	}
+
+	// 新配置不合法时保留原配置。
+	writeConfig(t, path, "API_KEY=bad-key", "CACHE_TTL=-1", "RSS_PROXY=ftp://proxy.example.com")
+	if code, _ := reloadRequest(t, srv.URL, "new-key"); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected invalid config to be rejected, got %d", code)
+	}
+	if healthStatus(t, srv.URL, "new-key") != http.StatusOK || healthStatus(t, srv.URL, "bad-key") != http.StatusUnauthorized {
+		t.Fatal("expected the previous configuration to stay active")
+	}
+}
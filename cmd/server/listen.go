@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const unixAddrPrefix = "unix:"
+
+// unixSocketPath 返回 unix:/path 形式地址中的路径，非 unix 地址返回空字符串。
+func unixSocketPath(addr string) string {
+	if !strings.HasPrefix(addr, unixAddrPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(addr, unixAddrPrefix)
+}
+
+// listen 按地址创建监听器：unix:/path 使用 Unix 域套接字，其余按 TCP 处理。
+// 返回的 cleanup 在关闭服务后调用，用于删除套接字文件。
+func listen(addr string) (net.Listener, func(), error) {
+	path := unixSocketPath(addr)
+	if path == "" {
+		ln, err := net.Listen("tcp", addr)
+		return ln, func() {}, err
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 由我们自行删除套接字文件，避免 Close 与 cleanup 重复删除。
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	cleanup := func() { _ = os.Remove(path) }
+	if mode, ok := socketMode(); ok {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			cleanup()
+			return nil, nil, fmt.Errorf("chmod socket: %w", err)
+		}
+	}
+	return ln, cleanup, nil
+}
+
+// removeStaleSocket 删除上次未清理的套接字文件；同名的非套接字文件不会被删除。
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+// socketMode 读取 SOCKET_MODE（八进制，如 0660），未设置或不合法时返回 false。
+func socketMode() (os.FileMode, bool) {
+	raw := strings.TrimSpace(os.Getenv("SOCKET_MODE"))
+	if raw == "" {
+		return 0, false
+	}
+	val, err := strconv.ParseUint(raw, 8, 32)
+	if err != nil {
+		return 0, false
+	}
+	return os.FileMode(val), true
+}
@@ -1,27 +1,211 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
+	// 内置时区数据，tz 参数在没有 zoneinfo 的精简镜像中也可用。
+	_ "time/tzdata"
 
+	"github.com/zdev0x/rss2json/internal/cache"
 	"github.com/zdev0x/rss2json/internal/server"
+	"github.com/zdev0x/rss2json/model"
 )
 
 func main() {
+	config, err := applyConfigFile()
+	if err != nil {
+		log.Fatalf("%s: %v", configFileEnv, err)
+	}
+	lifecycle := server.NewLifecycle(logStateChange)
 	addr := resolveListenAddr()
 	opts := server.Options{
-		APIKey:           strings.TrimSpace(os.Getenv("API_KEY")),
-		EnableRequestLog: shouldLogRequest(),
+		APIKey:                 strings.TrimSpace(os.Getenv("API_KEY")),
+		EnableRequestLog:       shouldLogRequest(),
+		AllowRequestHeaders:    envEnabled("ALLOW_REQUEST_HEADERS"),
+		AllowRequestAuthHeader: envEnabled("ALLOW_REQUEST_AUTH_HEADER"),
+		HealthCanaryURL:        strings.TrimSpace(os.Getenv("HEALTH_CANARY_URL")),
+		ResponseMaxAge:         envSeconds("RESPONSE_MAX_AGE"),
+		DefaultCount:           envInt("DEFAULT_COUNT"),
+		MaxCount:               envInt("MAX_COUNT"),
+		MaxURLs:                envInt("MAX_URLS"),
+		MaxBodyBytes:           int64(envInt("MAX_BODY_BYTES")),
+		MaxHeaderBytes:         envInt("MAX_HEADER_BYTES"),
+		ReadHeaderTimeout:      envSeconds("READ_HEADER_TIMEOUT"),
+		ReadTimeout:            envSeconds("READ_TIMEOUT"),
+		MinStreamInterval:      envSeconds("MIN_STREAM_INTERVAL"),
+		MaxStreams:             envInt("MAX_STREAMS"),
+		MaxActive:              envInt("MAX_ACTIVE"),
+		MaxQueue:               envInt("MAX_QUEUE"),
+		QueueTimeout:           envSeconds("QUEUE_TIMEOUT"),
+		ForceSanitize:          envEnabled("FORCE_SANITIZE"),
+		ForceStripTracking:     envEnabled("FORCE_STRIP_TRACKING"),
+		AllowRequestMaxBytes:   envEnabled("ALLOW_REQUEST_MAX_BYTES"),
+		MaxBytesLimit:          int64(envInt("MAX_BYTES_LIMIT")),
+		AllowRequestInsecure:   envEnabled("ALLOW_REQUEST_INSECURE"),
+		CacheTTL:               envSeconds("CACHE_TTL"),
+		DebugEndpoints:         envEnabled("DEBUG_ENDPOINTS"),
+		AllowCallbacks:         envEnabled("ALLOW_CALLBACKS"),
+		DisableDataURLs:        envEnabled("DISABLE_DATA_URLS"),
+		CallbackAllowlist:      envList("CALLBACK_ALLOWLIST"),
+		CallbackSecret:         os.Getenv("CALLBACK_SECRET"),
+		ErrorStatusStyle:       errorStatusStyle(),
+		Lifecycle:              lifecycle,
+	}
+	if opts.AllowCallbacks && opts.CallbackSecret == "" {
+		log.Fatalf("ALLOW_CALLBACKS requires CALLBACK_SECRET")
+	}
+	feedSets, err := server.ParseFeedSets(os.Getenv("FEED_SETS"))
+	if err != nil {
+		log.Fatalf("FEED_SETS: %v", err)
+	}
+	opts.FeedSets = feedSets
+	fieldMap, err := model.ParseFieldMap(os.Getenv("FIELD_MAP"))
+	if err != nil {
+		log.Fatalf("FIELD_MAP: %v", err)
+	}
+	opts.FieldMap = fieldMap
+	opts.FeedSetRefresh = envSeconds("FEED_SETS_REFRESH")
+	if opts.FeedSetRefresh <= 0 {
+		opts.FeedSetRefresh = defaultFeedSetRefresh
+	}
+	prefetchURLs, err := server.ParsePrefetchURLs(os.Getenv("PREFETCH_URLS"))
+	if err != nil {
+		log.Fatalf("PREFETCH_URLS: %v", err)
+	}
+	if len(prefetchURLs) > 0 && opts.CacheTTL <= 0 {
+		log.Fatalf("PREFETCH_URLS requires CACHE_TTL")
+	}
+	opts.PrefetchURLs = prefetchURLs
+	opts.PrefetchInterval = envSeconds("PREFETCH_INTERVAL")
+	if opts.CacheTTL > 0 {
+		store, err := newCacheStore()
+		if err != nil {
+			log.Fatalf("cache setup failed: %v", err)
+		}
+		opts.Cache = store
+	}
+	opts.Runtime = server.NewRuntime(server.RuntimeConfig{APIKey: opts.APIKey, CallbackAllowlist: opts.CallbackAllowlist, CacheTTL: opts.CacheTTL})
+	if config != nil {
+		config.attach(opts.Runtime, opts.Cache != nil)
+		opts.Reload = config.reload
+		config.watchSIGHUP()
+	}
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatalf("tracing setup failed: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
+
+	tlsConfig, err := resolveTLSConfig()
+	if err != nil {
+		log.Fatalf("tls config failed: %v", err)
+	}
+	printBanner(addr, opts, tlsConfig != nil)
+
+	ln, cleanup, err := listen(addr)
+	if err != nil {
+		log.Fatalf("listen failed: %v", err)
+	}
+	defer cleanup()
+
+	shutdown := make(chan struct{})
+	opts.StreamShutdown = shutdown
+	opts.Shutdown = shutdown
+	srv := server.NewServer(opts)
+	srv.TLSConfig = tlsConfig
+	srv.RegisterOnShutdown(func() { close(shutdown) })
+	serveErr := make(chan error, 1)
+	go func() {
+		if tlsConfig == nil {
+			serveErr <- srv.Serve(ln)
+			return
+		}
+		serveErr <- srv.ServeTLS(ln, "", "")
+	}()
+	lifecycle.SetReady()
+	// HTTP 跳转 HTTPS 的监听沿用主服务的读取超时与请求头上限，并随主服务一同关闭。
+	var redirectSrv *http.Server
+	if redirectAddr := strings.TrimSpace(os.Getenv("HTTP_REDIRECT_ADDR")); tlsConfig != nil && redirectAddr != "" {
+		redirectSrv = server.NewLimitedServer(redirectToHTTPS(addr), opts)
+		redirectSrv.Addr = redirectAddr
+		go func() {
+			if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("redirect listener failed: %v", err)
+			}
+		}()
 	}
-	printBanner(addr, opts)
 
-	if err := http.ListenAndServe(addr, server.NewHandler(opts)); err != nil {
-		log.Fatalf("server failed: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cleanup()
+			log.Fatalf("server failed: %v", err)
+		}
+	case <-ctx.Done():
+		drainDelay := envSeconds("DRAIN_DELAY")
+		log.Printf("shutting down (drain delay %s)", drainDelay)
+		err := lifecycle.Drain(drainDelay, func() error {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if redirectSrv != nil {
+				if err := redirectSrv.Shutdown(shutdownCtx); err != nil {
+					log.Printf("redirect listener shutdown: %v", err)
+				}
+			}
+			return srv.Shutdown(shutdownCtx)
+		})
+		if err != nil {
+			log.Printf("shutdown: %v", err)
+		}
+	}
+}
+
+// logStateChange 输出生命周期状态变化，与启动横幅使用相同的配色。
+func logStateChange(from, to server.State) {
+	log.Printf("%sstate:%s %s -> %s", colorYellow, colorReset, from, to)
+}
+
+// newCacheStore 构造内存 LRU 缓存；配置 CACHE_DIR 时在其后叠加磁盘缓存，重启后仍可命中；
+// 配置 CACHE_REDIS_URL 时再叠加多实例共享的 Redis 缓存。
+func newCacheStore() (cache.Store, error) {
+	var store cache.Store = cache.NewMemory(envInt("CACHE_MAX_ENTRIES"))
+	if dir := strings.TrimSpace(os.Getenv("CACHE_DIR")); dir != "" {
+		disk, err := cache.NewDisk(dir, int64(envInt("CACHE_MAX_DISK_MB"))<<20)
+		if err != nil {
+			return nil, err
+		}
+		store = cache.NewTiered(store, disk)
+	}
+	if redisURL := strings.TrimSpace(os.Getenv("CACHE_REDIS_URL")); redisURL != "" {
+		shared, err := cache.NewRedisFromURL(redisURL)
+		if err != nil {
+			return nil, err
+		}
+		store = cache.NewTiered(store, shared)
 	}
+	return store, nil
 }
 
+// defaultFeedSetRefresh 为未配置 FEED_SETS_REFRESH 时集合的后台刷新周期。
+const defaultFeedSetRefresh = 5 * time.Minute
+
+// shutdownTimeout 为收到退出信号后等待进行中请求完成的时长。
+const shutdownTimeout = 10 * time.Second
+
 const (
 	colorReset  = "\033[0m"
 	colorCyan   = "\033[36m"
@@ -30,7 +214,8 @@ const (
 	colorGray   = "\033[90m"
 )
 
-// resolveListenAddr 支持通过环境变量配置监听地址，便于容器暴露端口。
+// resolveListenAddr 支持通过环境变量配置监听地址，便于容器暴露端口；
+// LISTEN_ADDR=unix:/path 时监听 Unix 域套接字。
 func resolveListenAddr() string {
 	if addr := strings.TrimSpace(os.Getenv("LISTEN_ADDR")); addr != "" {
 		return addr
@@ -47,7 +232,7 @@ func resolveListenAddr() string {
 }
 
 // printBanner 输出启动信息，突出 rss2json。
-func printBanner(addr string, opts server.Options) {
+func printBanner(addr string, opts server.Options, secure bool) {
 	border := strings.Repeat("#", 56)
 	logStatus := "off"
 	if opts.EnableRequestLog {
@@ -62,6 +247,13 @@ func printBanner(addr string, opts server.Options) {
 		hostForURL = "127.0.0.1" + hostForURL
 	}
 	httpBase := "http://" + hostForURL
+	if secure {
+		httpBase = "https://" + hostForURL
+	}
+	if unixSocketPath(addr) != "" {
+		// Unix 套接字没有 TCP host:port，直接展示套接字地址。
+		httpBase = addr
+	}
 	logo := []string{
 		"   ____  ____  ____  ____   ___   ___   _   _ ",
 		"  |  _ \\|  _ \\| ___||___ \\ / _ \\ / _ \\ | \\ | |",
@@ -83,6 +275,55 @@ func printBanner(addr string, opts server.Options) {
 
 // shouldLogRequest 通过环境变量控制请求日志开关，默认关闭。
 func shouldLogRequest() bool {
-	val := strings.ToLower(strings.TrimSpace(os.Getenv("REQUEST_LOG")))
+	return envEnabled("REQUEST_LOG")
+}
+
+// envSeconds 读取以秒为单位的非负整数环境变量，未设置或不合法时返回 0。
+func envSeconds(key string) time.Duration {
+	return time.Duration(envInt(key)) * time.Second
+}
+
+// envInt 读取非负整数环境变量，未设置或不合法时返回 0。
+func envInt(key string) int {
+	val, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || val < 0 {
+		return 0
+	}
+	return val
+}
+
+// envList 读取逗号分隔的环境变量，忽略空项。
+func envList(key string) []string {
+	return splitList(os.Getenv(key))
+}
+
+// splitList 按逗号分割并去除空项。
+func splitList(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// errorStatusStyle 读取 ERROR_STATUS_STYLE（strict/legacy），未设置或无法识别时使用 strict。
+func errorStatusStyle() string {
+	style := strings.ToLower(strings.TrimSpace(os.Getenv("ERROR_STATUS_STYLE")))
+	switch style {
+	case "", server.ErrorStatusStrict:
+		return server.ErrorStatusStrict
+	case server.ErrorStatusLegacy:
+		return server.ErrorStatusLegacy
+	default:
+		log.Printf("[config] unknown ERROR_STATUS_STYLE %q, using %s", style, server.ErrorStatusStrict)
+		return server.ErrorStatusStrict
+	}
+}
+
+// envEnabled 判断布尔型环境变量是否开启，支持 1/true/on。
+func envEnabled(key string) bool {
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
 	return val == "1" || val == "true" || val == "on"
 }
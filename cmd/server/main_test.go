@@ -1,8 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/zdev0x/rss2json/internal/server"
@@ -32,3 +41,142 @@ func TestWithAPIKeyAuthFail(t *testing.T) {
 		t.Fatalf("expected 401, got %d", rr.Code)
 	}
 }
+
+func TestSelfSignedTLSListener(t *testing.T) {
+	cert, err := selfSignedCert([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("generate cert: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(server.NewHandler(server.Options{}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatalf("tls request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writePair := func() *x509.Certificate {
+		cert, err := selfSignedCert([]string{"localhost"})
+		if err != nil {
+			t.Fatalf("generate cert: %v", err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+		if err != nil {
+			t.Fatalf("marshal key: %v", err)
+		}
+		_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600)
+		_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+		return cert.Leaf
+	}
+
+	writePair()
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("load cert: %v", err)
+	}
+	second := writePair()
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	got, _ := reloader.GetCertificate(nil)
+	if !bytes.Equal(got.Certificate[0], second.Raw) {
+		t.Fatal("expected reloaded certificate to be served")
+	}
+
+	_ = os.WriteFile(certFile, []byte("garbage"), 0o600)
+	if err := reloader.reload(); err == nil {
+		t.Fatal("expected reload error for invalid cert")
+	}
+	if got, _ := reloader.GetCertificate(nil); !bytes.Equal(got.Certificate[0], second.Raw) {
+		t.Fatal("failed reload must keep previous certificate")
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	cases := map[string]string{
+		"0.0.0.0:8443": "https://example.com:8443/api/v1/rss2json?url=x",
+		":443":         "https://example.com/api/v1/rss2json?url=x",
+	}
+	for addr, want := range cases {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://example.com:8080/api/v1/rss2json?url=x", nil)
+		redirectToHTTPS(addr).ServeHTTP(rr, req)
+		if rr.Code != http.StatusPermanentRedirect {
+			t.Fatalf("expected 308, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Location"); got != want {
+			t.Fatalf("addr %s: expected %s, got %s", addr, want, got)
+		}
+	}
+}
+
+func TestUnixSocketListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rss2json.sock")
+	// 预先放置残留的套接字文件，启动时应被清理。
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	t.Setenv("SOCKET_MODE", "0660")
+	ln, cleanup, err := listen("unix:" + path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &http.Server{Handler: server.NewHandler(server.Options{})}
+	go srv.Serve(ln)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode().Perm() != 0o660 {
+		t.Fatalf("expected mode 0660, got %v", info.Mode().Perm())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/health")
+	if err != nil {
+		t.Fatalf("request over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	_ = srv.Close()
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket removed after cleanup, got %v", err)
+	}
+}
+
+func TestListenRefusesNonSocketFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := listen("unix:" + path); err == nil {
+		t.Fatal("expected error for existing regular file")
+	}
+}
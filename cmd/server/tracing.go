@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupTracing 在 OTEL_ENABLED 开启时注册 OTLP/HTTP 导出器，端点等配置读取标准的
+// OTEL_EXPORTER_OTLP_* 环境变量。未开启时保持全局 no-op 实现，返回的 shutdown 为空操作。
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	if !envEnabled("OTEL_ENABLED") {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME/OTEL_RESOURCE_ATTRIBUTES 优先于默认的服务名。
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "rss2json")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
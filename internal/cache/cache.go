@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry 表示一条缓存的上游 feed 内容。
+type Entry struct {
+	Body      []byte
+	FetchedAt time.Time
+	ExpiresAt time.Time
+	// MaxAge 为上游响应 Cache-Control 声明的 max-age，未声明时为 0。
+	MaxAge time.Duration
+}
+
+// Expired 判断条目在 now 时刻是否已过期。
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Store 定义缓存后端需要实现的操作，键为规范化后的 feed URL。
+type Store interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry)
+	// Delete 删除单个条目，返回是否存在。
+	Delete(key string) bool
+	// Flush 清空全部条目，返回删除的数量。
+	Flush() int
+}
+
+// Sizer 为可统计当前条目数的缓存后端实现，用于 /metrics 的缓存大小。
+type Sizer interface {
+	Len() int
+}
+
+// Len 返回缓存当前的条目数，后端不支持统计时返回 false。
+func Len(store Store) (int, bool) {
+	sizer, ok := store.(Sizer)
+	if !ok {
+		return 0, false
+	}
+	return sizer.Len(), true
+}
+
+// Lister 为可按前缀列出键的缓存后端实现，用于管理接口查看缓存内容。
+type Lister interface {
+	Keys(prefix string) []string
+}
+
+// ByteSizer 为可统计缓存内容占用字节数的后端实现。
+type ByteSizer interface {
+	Bytes() int64
+}
+
+// Keys 返回以 prefix 开头的键，按字典序排列；后端不支持列出键时返回 false。
+func Keys(store Store, prefix string) ([]string, bool) {
+	lister, ok := store.(Lister)
+	if !ok {
+		return nil, false
+	}
+	keys := lister.Keys(prefix)
+	sort.Strings(keys)
+	return keys, true
+}
+
+// Bytes 返回缓存内容占用的字节数，后端不支持统计时返回 false。
+func Bytes(store Store) (int64, bool) {
+	sizer, ok := store.(ByteSizer)
+	if !ok {
+		return 0, false
+	}
+	return sizer.Bytes(), true
+}
+
+// filterKeys 返回 keys 中以 prefix 开头的键。
+func filterKeys(keys []string, prefix string) []string {
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// encodedEntry 为持久化后端（磁盘、Redis）保存的条目格式，Key 用于识别哈希冲突。
+type encodedEntry struct {
+	Key       string        `json:"key"`
+	FetchedAt time.Time     `json:"fetched_at"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	Body      []byte        `json:"body"`
+	MaxAge    time.Duration `json:"max_age,omitempty"`
+}
+
+func encodeEntry(key string, entry Entry) ([]byte, error) {
+	return json.Marshal(encodedEntry{Key: key, FetchedAt: entry.FetchedAt, ExpiresAt: entry.ExpiresAt, Body: entry.Body, MaxAge: entry.MaxAge})
+}
+
+// decodeEntry 解析持久化的条目，内容损坏时返回 error，键不匹配时返回 false。
+func decodeEntry(key string, raw []byte) (Entry, bool, error) {
+	var rec encodedEntry
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return Entry{}, false, err
+	}
+	if rec.Key != key {
+		return Entry{}, false, nil
+	}
+	return Entry{Body: rec.Body, FetchedAt: rec.FetchedAt, ExpiresAt: rec.ExpiresAt, MaxAge: rec.MaxAge}, true, nil
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeysAndBytes(t *testing.T) {
+	disk, _ := newTestDisk(t, 0)
+	stores := map[string]Store{
+		"memory": NewMemory(4),
+		"disk":   disk,
+		"redis":  NewRedis(newFakeRedis()),
+		"tiered": NewTiered(NewMemory(4), NewRedis(newFakeRedis())),
+	}
+	for name, store := range stores {
+		store.Set("https://b.example.com/rss", Entry{Body: []byte("bbbb")})
+		store.Set("https://a.example.com/rss", Entry{Body: []byte("aa")})
+		store.Set("https://a.example.com/atom", Entry{Body: []byte("a")})
+
+		keys, ok := Keys(store, "https://a.")
+		if got := strings.Join(keys, ","); !ok || got != "https://a.example.com/atom,https://a.example.com/rss" {
+			t.Fatalf("%s: unexpected keys %q ok=%v", name, got, ok)
+		}
+		if all, _ := Keys(store, ""); len(all) != 3 {
+			t.Fatalf("%s: expected 3 keys, got %v", name, all)
+		}
+	}
+
+	if n, ok := Bytes(stores["memory"]); !ok || n != 7 {
+		t.Fatalf("expected memory cache to hold 7 bytes, got %d ok=%v", n, ok)
+	}
+	if n, ok := Bytes(disk); !ok || n <= 7 {
+		t.Fatalf("expected disk cache size to include encoded entries, got %d ok=%v", n, ok)
+	}
+	if _, ok := Bytes(stores["redis"]); ok {
+		t.Fatal("expected redis cache not to report bytes")
+	}
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxDiskBytes 为磁盘缓存默认的总大小上限。
+const DefaultMaxDiskBytes int64 = 256 << 20
+
+const diskEntrySuffix = ".entry"
+
+// Disk 为目录下的持久化缓存，每个条目一个文件，进程重启后仍可命中。
+// 写入先落临时文件再 rename，多个进程共享同一目录时读到的总是完整条目；
+// 总大小超过上限时按最近访问时间（文件 mtime）淘汰最旧的条目。
+type Disk struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+}
+
+// NewDisk 构造磁盘缓存并确保目录存在，maxBytes <= 0 时使用 DefaultMaxDiskBytes。
+func NewDisk(dir string, maxBytes int64) (*Disk, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDiskBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir, maxBytes: maxBytes, now: time.Now}, nil
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+diskEntrySuffix)
+}
+
+func (d *Disk) Get(key string) (Entry, bool) {
+	path := d.path(key)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	entry, ok, err := decodeEntry(key, raw)
+	if err != nil {
+		// 损坏的文件直接删除，下次抓取时重新写入。
+		_ = os.Remove(path)
+		return Entry{}, false
+	}
+	if !ok {
+		return Entry{}, false
+	}
+	now := d.now()
+	if entry.Expired(now) {
+		_ = os.Remove(path)
+		return Entry{}, false
+	}
+	_ = os.Chtimes(path, now, now)
+	return entry, true
+}
+
+func (d *Disk) Set(key string, entry Entry) {
+	raw, err := encodeEntry(key, entry)
+	if err != nil || int64(len(raw)) > d.maxBytes {
+		return
+	}
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(raw)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		now := d.now()
+		_ = os.Chtimes(tmp.Name(), now, now)
+		err = os.Rename(tmp.Name(), d.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	d.evict()
+}
+
+// Len 返回缓存目录中的条目文件数。
+func (d *Disk) Len() int {
+	files, _ := d.entries()
+	return len(files)
+}
+
+// Keys 读取各条目文件中保存的原始键，返回以 prefix 开头的键；无法解析的文件跳过。
+func (d *Disk) Keys(prefix string) []string {
+	files, _ := d.entries()
+	keys := make([]string, 0, len(files))
+	for _, f := range files {
+		raw, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		var rec encodedEntry
+		if json.Unmarshal(raw, &rec) != nil {
+			continue
+		}
+		keys = append(keys, rec.Key)
+	}
+	return filterKeys(keys, prefix)
+}
+
+// Bytes 返回缓存目录中条目文件的总大小。
+func (d *Disk) Bytes() int64 {
+	files, _ := d.entries()
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	return total
+}
+
+func (d *Disk) Delete(key string) bool {
+	return os.Remove(d.path(key)) == nil
+}
+
+func (d *Disk) Flush() int {
+	files, _ := d.entries()
+	n := 0
+	for _, f := range files {
+		if os.Remove(f.path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+type diskFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (d *Disk) entries() ([]diskFile, error) {
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]diskFile, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), diskEntrySuffix) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			// 其他进程可能刚好删除了该文件。
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		files = append(files, diskFile{path: filepath.Join(d.dir, de.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// evict 在总大小超过上限时删除最久未访问的条目。
+func (d *Disk) evict() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	files, err := d.entries()
+	if err != nil {
+		return
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if total <= d.maxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= d.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+			total -= f.size
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDisk(t *testing.T, maxBytes int64) (*Disk, *time.Time) {
+	t.Helper()
+	d, err := NewDisk(t.TempDir(), maxBytes)
+	if err != nil {
+		t.Fatalf("new disk cache: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDiskPersistsAcrossInstances(t *testing.T) {
+	d, _ := newTestDisk(t, 0)
+	d.Set("https://example.com/rss", Entry{Body: []byte("<rss/>")})
+
+	reopened, err := NewDisk(d.dir, 0)
+	if err != nil {
+		t.Fatalf("reopen disk cache: %v", err)
+	}
+	entry, ok := reopened.Get("https://example.com/rss")
+	if !ok || string(entry.Body) != "<rss/>" {
+		t.Fatalf("expected entry after reopen, got %q ok=%v", entry.Body, ok)
+	}
+	if _, ok := reopened.Get("https://example.com/other"); ok {
+		t.Fatal("expected miss for unknown key")
+	}
+}
+
+func TestDiskExpiry(t *testing.T) {
+	d, now := newTestDisk(t, 0)
+	d.Set("a", Entry{Body: []byte("a"), ExpiresAt: now.Add(time.Minute)})
+
+	if _, ok := d.Get("a"); !ok {
+		t.Fatal("expected fresh entry")
+	}
+	*now = now.Add(time.Minute)
+	if _, ok := d.Get("a"); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+	if _, err := os.Stat(d.path("a")); !os.IsNotExist(err) {
+		t.Fatalf("expected expired file removed, stat err=%v", err)
+	}
+}
+
+func TestDiskEvictsLeastRecentlyUsed(t *testing.T) {
+	d, now := newTestDisk(t, 0)
+	body := []byte(strings.Repeat("x", 100))
+	d.Set("a", Entry{Body: body})
+	info, err := os.Stat(d.path("a"))
+	if err != nil {
+		t.Fatalf("stat entry: %v", err)
+	}
+	d.maxBytes = info.Size()*2 + info.Size()/2
+
+	*now = now.Add(time.Second)
+	d.Set("b", Entry{Body: body})
+	*now = now.Add(time.Second)
+	d.Get("a")
+	*now = now.Add(time.Second)
+	d.Set("c", Entry{Body: body})
+
+	if _, ok := d.Get("b"); ok {
+		t.Fatal("expected least recently used entry evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := d.Get(key); !ok {
+			t.Fatalf("expected %s kept", key)
+		}
+	}
+}
+
+func TestDiskCorruptedFileRecovery(t *testing.T) {
+	d, _ := newTestDisk(t, 0)
+	d.Set("a", Entry{Body: []byte("a")})
+	if err := os.WriteFile(d.path("a"), []byte(`{"key":"a","bo`), 0o644); err != nil {
+		t.Fatalf("corrupt entry: %v", err)
+	}
+
+	if _, ok := d.Get("a"); ok {
+		t.Fatal("expected corrupted entry to miss")
+	}
+	if _, err := os.Stat(d.path("a")); !os.IsNotExist(err) {
+		t.Fatalf("expected corrupted file removed, stat err=%v", err)
+	}
+	d.Set("a", Entry{Body: []byte("fresh")})
+	if entry, ok := d.Get("a"); !ok || string(entry.Body) != "fresh" {
+		t.Fatalf("expected rewritten entry, got %q ok=%v", entry.Body, ok)
+	}
+}
+
+func TestDiskDeleteAndFlushLeaveNoTempFiles(t *testing.T) {
+	d, _ := newTestDisk(t, 0)
+	d.Set("a", Entry{})
+	d.Set("b", Entry{})
+
+	if !d.Delete("a") || d.Delete("a") {
+		t.Fatal("expected delete to report presence once")
+	}
+	if n := d.Flush(); n != 1 {
+		t.Fatalf("expected flush to remove 1 entry, got %d", n)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(d.dir, "*"))
+	if len(leftovers) != 0 {
+		t.Fatalf("expected empty cache dir, got %v", leftovers)
+	}
+}
+
+func TestTieredReadThrough(t *testing.T) {
+	front := NewMemory(4)
+	back, _ := newTestDisk(t, 0)
+	back.Set("a", Entry{Body: []byte("a")})
+	tiered := NewTiered(front, back)
+
+	if _, ok := tiered.Get("a"); !ok {
+		t.Fatal("expected hit from back layer")
+	}
+	if _, ok := front.Get("a"); !ok {
+		t.Fatal("expected front layer populated on read-through")
+	}
+
+	tiered.Set("b", Entry{Body: []byte("b")})
+	if _, ok := back.Get("b"); !ok {
+		t.Fatal("expected write-through to back layer")
+	}
+	if n := tiered.Flush(); n != 2 {
+		t.Fatalf("expected flush to report 2 entries, got %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries 为内存缓存默认的最大条目数。
+const DefaultMaxEntries = 1024
+
+type memoryItem struct {
+	key   string
+	entry Entry
+}
+
+// Memory 为进程内 LRU 缓存，条目数有上限以保证内存有界。
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+// NewMemory 构造内存缓存，maxEntries <= 0 时使用 DefaultMaxEntries。
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Memory{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (m *Memory) Get(key string) (Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	item := el.Value.(*memoryItem)
+	if item.entry.Expired(m.now()) {
+		m.removeElement(el)
+		return Entry{}, false
+	}
+	m.ll.MoveToFront(el)
+	return item.entry, true
+}
+
+// Len 返回当前条目数（含尚未被清理的过期条目）。
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// Keys 返回以 prefix 开头的键（含尚未被清理的过期条目）。
+func (m *Memory) Keys(prefix string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.items))
+	for key := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Bytes 返回所有条目内容的总字节数。
+func (m *Memory) Bytes() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total int64
+	for _, el := range m.items {
+		total += int64(len(el.Value.(*memoryItem).entry.Body))
+	}
+	return total
+}
+
+func (m *Memory) Set(key string, entry Entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryItem).entry = entry
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.ll.PushFront(&memoryItem{key: key, entry: entry})
+	for m.ll.Len() > m.maxEntries {
+		m.removeElement(m.ll.Back())
+	}
+}
+
+func (m *Memory) Delete(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	m.removeElement(el)
+	return true
+}
+
+func (m *Memory) Flush() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := m.ll.Len()
+	m.ll.Init()
+	m.items = make(map[string]*list.Element)
+	return n
+}
+
+func (m *Memory) removeElement(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryItem).key)
+}
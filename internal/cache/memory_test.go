@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLRUEviction(t *testing.T) {
+	m := NewMemory(2)
+	m.Set("a", Entry{Body: []byte("a")})
+	m.Set("b", Entry{Body: []byte("b")})
+	m.Get("a")
+	m.Set("c", Entry{Body: []byte("c")})
+
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("expected least recently used entry evicted")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("expected recently used entry kept")
+	}
+}
+
+func TestMemoryExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory(4)
+	m.now = func() time.Time { return now }
+	m.Set("a", Entry{Body: []byte("a"), ExpiresAt: now.Add(time.Minute)})
+
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("expected fresh entry")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := m.Get("a"); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+}
+
+func TestMemoryDeleteAndFlush(t *testing.T) {
+	m := NewMemory(4)
+	m.Set("a", Entry{})
+	m.Set("b", Entry{})
+	m.Set("c", Entry{})
+
+	if !m.Delete("a") || m.Delete("a") {
+		t.Fatal("expected single delete to report presence once")
+	}
+	if n, ok := Len(m); !ok || n != 2 {
+		t.Fatalf("expected 2 entries, got %d (%v)", n, ok)
+	}
+	if n := m.Flush(); n != 2 {
+		t.Fatalf("expected 2 flushed, got %d", n)
+	}
+	if n := m.Len(); n != 0 {
+		t.Fatalf("expected empty cache after flush, got %d", n)
+	}
+}
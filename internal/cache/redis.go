@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeyPrefix 为写入 Redis 的键前缀，Flush 只清理该前缀下的键。
+	redisKeyPrefix = "rss2json:cache:"
+	// redisOpTimeout 为单次 Redis 操作的超时，避免 Redis 变慢拖住用户请求。
+	redisOpTimeout = 500 * time.Millisecond
+	// redisWarnInterval 为 Redis 不可用时告警日志的最小间隔。
+	redisWarnInterval = time.Minute
+)
+
+// RedisClient 为 Redis 缓存用到的最小命令集合，便于在测试中替换实现。
+// Get 在键不存在时返回 (nil, false, nil)。
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) (int, error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+}
+
+// Redis 为多实例共享的缓存后端。Redis 不可用时所有操作按未命中处理并输出告警，
+// 请求退化为直接抓取上游，不会失败。
+type Redis struct {
+	client RedisClient
+	now    func() time.Time
+
+	warnMu   sync.Mutex
+	lastWarn time.Time
+}
+
+// NewRedis 使用给定客户端构造 Redis 缓存。
+func NewRedis(client RedisClient) *Redis {
+	return &Redis{client: client, now: time.Now}
+}
+
+// NewRedisFromURL 按 redis://[:password@]host:port/db 地址构造 Redis 缓存，不在启动时检查连通性。
+func NewRedisFromURL(rawURL string) (*Redis, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewRedis(goRedisClient{redis.NewClient(opts)}), nil
+}
+
+func (r *Redis) Get(key string) (Entry, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	raw, ok, err := r.client.Get(ctx, redisKeyPrefix+key)
+	if err != nil {
+		r.warn(err)
+		return Entry{}, false
+	}
+	if !ok {
+		return Entry{}, false
+	}
+	entry, ok, err := decodeEntry(key, raw)
+	if err != nil || !ok || entry.Expired(r.now()) {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+func (r *Redis) Set(key string, entry Entry) {
+	var ttl time.Duration
+	if !entry.ExpiresAt.IsZero() {
+		if ttl = entry.ExpiresAt.Sub(r.now()); ttl <= 0 {
+			return
+		}
+	}
+	raw, err := encodeEntry(key, entry)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := r.client.Set(ctx, redisKeyPrefix+key, raw, ttl); err != nil {
+		r.warn(err)
+	}
+}
+
+func (r *Redis) Delete(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	n, err := r.client.Del(ctx, redisKeyPrefix+key)
+	if err != nil {
+		r.warn(err)
+		return false
+	}
+	return n > 0
+}
+
+func (r *Redis) Flush() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	keys, err := r.client.Keys(ctx, redisKeyPrefix+"*")
+	if err != nil {
+		r.warn(err)
+		return 0
+	}
+	if len(keys) == 0 {
+		return 0
+	}
+	n, err := r.client.Del(ctx, keys...)
+	if err != nil {
+		r.warn(err)
+	}
+	return n
+}
+
+// Keys 使用 SCAN 列出以 prefix 开头的键，Redis 不可用时返回空列表。
+func (r *Redis) Keys(prefix string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	raw, err := r.client.Keys(ctx, redisKeyPrefix+redisGlobEscaper.Replace(prefix)+"*")
+	if err != nil {
+		r.warn(err)
+		return nil
+	}
+	keys := make([]string, 0, len(raw))
+	for _, key := range raw {
+		keys = append(keys, strings.TrimPrefix(key, redisKeyPrefix))
+	}
+	return filterKeys(keys, prefix)
+}
+
+// redisGlobEscaper 转义 SCAN MATCH 模式中的通配符，使前缀按字面匹配。
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// warn 记录 Redis 错误，同一时间窗口内只输出一次，避免 Redis 宕机时刷屏。
+func (r *Redis) warn(err error) {
+	r.warnMu.Lock()
+	defer r.warnMu.Unlock()
+	now := r.now()
+	if !r.lastWarn.IsZero() && now.Sub(r.lastWarn) < redisWarnInterval {
+		return
+	}
+	r.lastWarn = now
+	log.Printf("[warn] redis cache unavailable, fetching upstream directly: %v", err)
+}
+
+// goRedisClient 将 go-redis 客户端适配为 RedisClient。
+type goRedisClient struct {
+	client *redis.Client
+}
+
+func (c goRedisClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	raw, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, true, nil
+}
+
+func (c goRedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c goRedisClient) Del(ctx context.Context, keys ...string) (int, error) {
+	n, err := c.client.Del(ctx, keys...).Result()
+	return int(n), err
+}
+
+// Keys 使用 SCAN 遍历匹配的键，避免 KEYS 阻塞 Redis。
+func (c goRedisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedis 为内存中的 RedisClient 实现，down 为 true 时所有命令返回错误。
+type fakeRedis struct {
+	data map[string][]byte
+	ttls map[string]time.Duration
+	down bool
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+var errRedisDown = errors.New("dial tcp 127.0.0.1:6379: connection refused")
+
+func (f *fakeRedis) Get(_ context.Context, key string) ([]byte, bool, error) {
+	if f.down {
+		return nil, false, errRedisDown
+	}
+	raw, ok := f.data[key]
+	return raw, ok, nil
+}
+
+func (f *fakeRedis) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.down {
+		return errRedisDown
+	}
+	f.data[key] = value
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeRedis) Del(_ context.Context, keys ...string) (int, error) {
+	if f.down {
+		return 0, errRedisDown
+	}
+	n := 0
+	for _, key := range keys {
+		if _, ok := f.data[key]; ok {
+			delete(f.data, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeRedis) Keys(_ context.Context, pattern string) ([]string, error) {
+	if f.down {
+		return nil, errRedisDown
+	}
+	prefix := strings.TrimSuffix(pattern, "*")
+	var keys []string
+	for key := range f.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func TestRedisGetSetWithTTL(t *testing.T) {
+	client := newFakeRedis()
+	r := NewRedis(client)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.Set("https://example.com/rss", Entry{Body: []byte("<rss/>"), FetchedAt: now, ExpiresAt: now.Add(5 * time.Minute)})
+	if got := client.ttls[redisKeyPrefix+"https://example.com/rss"]; got != 5*time.Minute {
+		t.Fatalf("expected ttl 5m, got %v", got)
+	}
+	entry, ok := r.Get("https://example.com/rss")
+	if !ok || string(entry.Body) != "<rss/>" || !entry.FetchedAt.Equal(now) {
+		t.Fatalf("unexpected entry %+v ok=%v", entry, ok)
+	}
+	if _, ok := r.Get("https://example.com/other"); ok {
+		t.Fatal("expected miss for unknown key")
+	}
+}
+
+func TestRedisDeleteAndFlushOnlyOwnKeys(t *testing.T) {
+	client := newFakeRedis()
+	client.data["other:key"] = []byte("x")
+	r := NewRedis(client)
+	r.Set("a", Entry{Body: []byte("a")})
+	r.Set("b", Entry{Body: []byte("b")})
+
+	if !r.Delete("a") || r.Delete("a") {
+		t.Fatal("expected delete to report presence once")
+	}
+	if n := r.Flush(); n != 1 {
+		t.Fatalf("expected flush to remove 1 entry, got %d", n)
+	}
+	if _, ok := client.data["other:key"]; !ok {
+		t.Fatal("expected keys outside the prefix untouched")
+	}
+}
+
+func TestRedisDownDegradesToMiss(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	client := newFakeRedis()
+	client.down = true
+	r := NewRedis(client)
+
+	r.Set("a", Entry{Body: []byte("a")})
+	if _, ok := r.Get("a"); ok {
+		t.Fatal("expected miss while redis is down")
+	}
+	if r.Delete("a") || r.Flush() != 0 {
+		t.Fatal("expected no-op delete/flush while redis is down")
+	}
+	if got := strings.Count(buf.String(), "redis cache unavailable"); got != 1 {
+		t.Fatalf("expected a single throttled warning, got %d: %q", got, buf.String())
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+// Tiered 组合两层缓存：读取时先查 front，未命中再查 back 并回填 front；
+// 写入与删除同时作用于两层。常用于内存 LRU 在前、磁盘缓存在后。
+type Tiered struct {
+	front Store
+	back  Store
+}
+
+// NewTiered 构造两层缓存。
+func NewTiered(front, back Store) *Tiered {
+	return &Tiered{front: front, back: back}
+}
+
+func (t *Tiered) Get(key string) (Entry, bool) {
+	if entry, ok := t.front.Get(key); ok {
+		return entry, true
+	}
+	entry, ok := t.back.Get(key)
+	if ok {
+		t.front.Set(key, entry)
+	}
+	return entry, ok
+}
+
+func (t *Tiered) Set(key string, entry Entry) {
+	t.front.Set(key, entry)
+	t.back.Set(key, entry)
+}
+
+func (t *Tiered) Delete(key string) bool {
+	front := t.front.Delete(key)
+	back := t.back.Delete(key)
+	return front || back
+}
+
+// Flush 清空两层，返回较大一层删除的数量（同一条目通常同时存在于两层）。
+func (t *Tiered) Flush() int {
+	return max(t.front.Flush(), t.back.Flush())
+}
+
+// Len 返回较大一层的条目数，两层都不支持统计时返回 0。
+func (t *Tiered) Len() int {
+	front, _ := Len(t.front)
+	back, _ := Len(t.back)
+	return max(front, back)
+}
+
+// Keys 返回两层中以 prefix 开头的键的并集，两层都不支持列出时返回空列表。
+func (t *Tiered) Keys(prefix string) []string {
+	front, _ := Keys(t.front, prefix)
+	back, _ := Keys(t.back, prefix)
+	seen := make(map[string]bool, len(back))
+	keys := append([]string(nil), back...)
+	for _, key := range back {
+		seen[key] = true
+	}
+	for _, key := range front {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Bytes 返回较大一层占用的字节数，两层都不支持统计时返回 0。
+func (t *Tiered) Bytes() int64 {
+	front, _ := Bytes(t.front)
+	back, _ := Bytes(t.back)
+	return max(front, back)
+}
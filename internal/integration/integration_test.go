@@ -0,0 +1,290 @@
+// Package integration 在真实的出站传输路径上端到端测试 server.NewHandler：
+// 本地 httptest 服务器按路径提供 fixture，覆盖状态码、跳转、gzip、慢响应体与超大内容。
+package integration
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/internal/server"
+)
+
+const sampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Integration Feed</title>
+    <link>https://example.com/</link>
+    <description>served by a local fixture server</description>
+    <item>
+      <title>First</title>
+      <link>https://example.com/1</link>
+      <guid>https://example.com/1</guid>
+      <description>first item</description>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>https://example.com/2</link>
+      <guid>https://example.com/2</guid>
+      <description>second item</description>
+    </item>
+  </channel>
+</rss>`
+
+// newFeedServer 启动本地 feed 服务器，各路径对应一种上游行为：
+//
+//	/feed           正常返回 sampleRSS
+//	/status/{code}  返回给定状态码
+//	/redirect/{n}   连续跳转 n 次后到 /feed
+//	/gzip           以 Content-Encoding: gzip 返回 sampleRSS
+//	/slow?for=1s    立即返回响应头，响应体在给定时长内分段写出
+//	/stall?for=1s   等待给定时长后才返回响应头
+//	/large?bytes=N  返回不少于 N 字节的合法 feed
+func newFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		writeFeed(w, sampleRSS)
+	})
+	mux.HandleFunc("/status/{code}", func(w http.ResponseWriter, r *http.Request) {
+		var code int
+		if _, err := fmt.Sscan(r.PathValue("code"), &code); err != nil {
+			http.Error(w, "bad code", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(code)
+	})
+	mux.HandleFunc("/redirect/{n}", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		_, _ = fmt.Sscan(r.PathValue("n"), &n)
+		if n <= 1 {
+			http.Redirect(w, r, "/feed", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			http.Error(w, "gzip not accepted", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(sampleRSS))
+		_ = gz.Close()
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		total := queryDuration(r, "for")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.WriteHeader(http.StatusOK)
+		const chunks = 10
+		step := len(sampleRSS) / chunks
+		for i := 0; i < chunks; i++ {
+			end := (i + 1) * step
+			if i == chunks-1 {
+				end = len(sampleRSS)
+			}
+			_, _ = w.Write([]byte(sampleRSS[i*step : end]))
+			_ = http.NewResponseController(w).Flush()
+			select {
+			case <-time.After(total / chunks):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/stall", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(queryDuration(r, "for")):
+		case <-r.Context().Done():
+			return
+		}
+		writeFeed(w, sampleRSS)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		var size int
+		_, _ = fmt.Sscan(r.URL.Query().Get("bytes"), &size)
+		writeFeed(w, strings.Replace(sampleRSS, "first item", strings.Repeat("x", size), 1))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeFeed(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/rss+xml")
+	_, _ = w.Write([]byte(body))
+}
+
+func queryDuration(r *http.Request, key string) time.Duration {
+	d, _ := time.ParseDuration(r.URL.Query().Get(key))
+	return d
+}
+
+// convert 通过完整的 Handler 栈请求给定上游地址，返回状态码与解码后的 JSON。
+func convert(t *testing.T, handler http.Handler, upstream, extra string) (int, map[string]interface{}) {
+	t.Helper()
+	target := "/api/v1/rss2json?url=" + url.QueryEscape(upstream) + extra
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	var payload map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid JSON response (%d): %v: %s", rr.Code, err, rr.Body.String())
+	}
+	return rr.Code, payload
+}
+
+func itemTitles(t *testing.T, payload map[string]interface{}) []string {
+	t.Helper()
+	items, ok := payload["items"].([]interface{})
+	if !ok {
+		t.Fatalf("expected items in response, got %v", payload)
+	}
+	var titles []string
+	for _, it := range items {
+		titles = append(titles, it.(map[string]interface{})["title"].(string))
+	}
+	return titles
+}
+
+// errorCode 返回错误响应中的 error.code。
+func errorCode(payload map[string]interface{}) string {
+	errObj, _ := payload["error"].(map[string]interface{})
+	code, _ := errObj["code"].(string)
+	return code
+}
+
+func TestIntegrationConvert(t *testing.T) {
+	srv := newFeedServer(t)
+	handler := server.NewHandler(server.Options{})
+
+	for _, path := range []string{"/feed", "/redirect/3", "/gzip"} {
+		t.Run(path, func(t *testing.T) {
+			status, payload := convert(t, handler, srv.URL+path, "")
+			if status != http.StatusOK || payload["status"] != "ok" {
+				t.Fatalf("expected ok, got %d %v", status, payload)
+			}
+			if titles := itemTitles(t, payload); strings.Join(titles, ",") != "First,Second" {
+				t.Fatalf("unexpected items %v", titles)
+			}
+		})
+	}
+}
+
+func TestIntegrationErrorMapping(t *testing.T) {
+	srv := newFeedServer(t)
+	handler := server.NewHandler(server.Options{AllowRequestMaxBytes: true})
+
+	tests := []struct {
+		name   string
+		path   string
+		extra  string
+		status int
+		code   string
+	}{
+		{"upstream 404", "/status/404", "", http.StatusUnprocessableEntity, "upstream_status"},
+		{"upstream 500", "/status/500", "", http.StatusUnprocessableEntity, "upstream_status"},
+		{"too many redirects", "/redirect/11", "", http.StatusUnprocessableEntity, "fetch_failed"},
+		{"oversized body", "/large?bytes=8192", "&max_bytes=4096", http.StatusUnprocessableEntity, "too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := convert(t, handler, srv.URL+tt.path, tt.extra)
+			if status != tt.status || errorCode(payload) != tt.code {
+				t.Fatalf("expected %d %s, got %d %v", tt.status, tt.code, status, payload)
+			}
+		})
+	}
+
+	status, payload := convert(t, handler, srv.URL+"/large?bytes=1024", "&max_bytes=4096")
+	if status != http.StatusOK || payload["status"] != "ok" {
+		t.Fatalf("expected feed under the limit to convert, got %d %v", status, payload)
+	}
+}
+
+// TestIntegrationSlowUpstream 说明出站超时的边界：ResponseHeaderTimeout（5s）只约束等待响应头，
+// 响应头到达后分段写出的响应体仅受客户端整体超时（10s）约束。
+func TestIntegrationSlowUpstream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow upstream cases in -short mode")
+	}
+	srv := newFeedServer(t)
+	handler := server.NewHandler(server.Options{})
+
+	t.Run("body slower than header timeout", func(t *testing.T) {
+		status, payload := convert(t, handler, srv.URL+"/slow?for=7s", "")
+		if status != http.StatusOK || payload["status"] != "ok" {
+			t.Fatalf("expected trickled body within the client timeout to convert, got %d %v", status, payload)
+		}
+	})
+	t.Run("body slower than client timeout", func(t *testing.T) {
+		start := time.Now()
+		status, payload := convert(t, handler, srv.URL+"/slow?for=15s", "")
+		if status != http.StatusGatewayTimeout || errorCode(payload) != "fetch_timeout" {
+			t.Fatalf("expected fetch_timeout, got %d %v", status, payload)
+		}
+		if elapsed := time.Since(start); elapsed > 12*time.Second {
+			t.Fatalf("expected the client timeout to cut the body read, took %s", elapsed)
+		}
+	})
+	t.Run("headers slower than header timeout", func(t *testing.T) {
+		start := time.Now()
+		status, payload := convert(t, handler, srv.URL+"/stall?for=7s", "")
+		if status != http.StatusGatewayTimeout || errorCode(payload) != "fetch_timeout" {
+			t.Fatalf("expected fetch_timeout, got %d %v", status, payload)
+		}
+		if elapsed := time.Since(start); elapsed > 7*time.Second {
+			t.Fatalf("expected the header timeout to fire first, took %s", elapsed)
+		}
+	})
+}
+
+// TestIntegrationKeepAlivesDisabled 确认 RSS_DISABLE_KEEPALIVES=1 时每次抓取都新建连接，默认则复用。
+func TestIntegrationKeepAlivesDisabled(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeFeed(w, sampleRSS)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	// 先注册清理，使其在环境变量还原后按原配置重建出站客户端。
+	reload := rss.ReloadHTTPClientFromEnv
+	t.Cleanup(reload)
+	handler := server.NewHandler(server.Options{})
+	fetchTwice := func() int32 {
+		start := conns.Load()
+		for i := 0; i < 2; i++ {
+			if status, payload := convert(t, handler, fmt.Sprintf("%s/feed?n=%d", srv.URL, i), ""); status != http.StatusOK {
+				t.Fatalf("expected ok, got %d %v", status, payload)
+			}
+		}
+		return conns.Load() - start
+	}
+
+	reload()
+	if got := fetchTwice(); got != 1 {
+		t.Fatalf("expected the connection reused by default, got %d connections", got)
+	}
+
+	t.Setenv("RSS_DISABLE_KEEPALIVES", "1")
+	reload()
+	if got := fetchTwice(); got != 2 {
+		t.Fatalf("expected a new connection per fetch with keep-alives disabled, got %d", got)
+	}
+}
@@ -0,0 +1,97 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// generatedFeed 构造含 n 个条目的 RSS 2.0 feed，每个条目带 thumbnail、enclosure、dc:creator
+// 与一段 HTML 正文，作为基准测试的大 feed，避免在仓库中提交大文件。
+func generatedFeed(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Generated Feed</title>
+    <link>https://example.com/</link>
+    <description>Benchmark fixture</description>
+`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `    <item>
+      <title>Item %[1]d</title>
+      <link>https://example.com/posts/%[1]d?utm_source=rss</link>
+      <guid>https://example.com/posts/%[1]d</guid>
+      <pubDate>Mon, 01 Jan 2024 00:00:00 GMT</pubDate>
+      <dc:creator>Author %[1]d</dc:creator>
+      <description><![CDATA[<p>Summary of item %[1]d with <a href="https://example.com/%[1]d">a link</a>.</p>]]></description>
+      <content:encoded><![CDATA[<div><p>Paragraph one of item %[1]d.</p><p>Paragraph two <img src="https://example.com/%[1]d.jpg"/></p></div>]]></content:encoded>
+      <media:thumbnail url="https://example.com/thumbs/%[1]d.jpg" width="320"/>
+      <enclosure url="https://example.com/audio/%[1]d.mp3" type="audio/mpeg" length="%[2]d"/>
+    </item>
+`, i, 1000+i)
+	}
+	b.WriteString("  </channel>\n</rss>")
+	return b.String()
+}
+
+func benchmarkConvert(b *testing.B, body string) {
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ConvertWithOptions(context.Background(), "https://example.com/bench", Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertSmallFeed(b *testing.B) {
+	benchmarkConvert(b, sampleRSS)
+}
+
+func BenchmarkConvertLargeFeed(b *testing.B) {
+	benchmarkConvert(b, generatedFeed(2000))
+}
+
+// BenchmarkParseLargeFeed 只测 gofeed 解析，不含抓取与条目构造。
+func BenchmarkParseLargeFeed(b *testing.B) {
+	body := []byte(generatedFeed(2000))
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseFeed(context.Background(), body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExtractThumbnails(b *testing.B) {
+	body := []byte(generatedFeed(2000))
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if got := extractItemThumbnails(context.Background(), body); len(got) != 2000 {
+			b.Fatalf("expected 2000 thumbnails, got %d", len(got))
+		}
+	}
+}
+
+func TestGeneratedFeedParses(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: generatedFeed(3), status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https://example.com/generated")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Items) != 3 || resp.Items[2].Thumbnail != "https://example.com/thumbs/2.jpg" {
+		t.Fatalf("unexpected generated items: %d", len(resp.Items))
+	}
+}
@@ -0,0 +1,97 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fetchFeed 下载 feed 原始内容；启用缓存时优先返回未过期的缓存内容（Refresh 时跳过读取，只写入）。
+// data: URI 直接解码，不经过缓存。
+// maxAge 为上游 Cache-Control 声明的 max-age，随缓存条目一并保存。
+func fetchFeed(ctx context.Context, rawURL string, opts Options) (body []byte, maxAge time.Duration, err error) {
+	if isDataURL(rawURL) {
+		body, err = decodeDataURL(rawURL, opts)
+		return body, 0, err
+	}
+	ctx, span := tracer().Start(ctx, "fetch", trace.WithAttributes(attribute.String("server.address", hostOf(rawURL))))
+	defer func() {
+		span.SetAttributes(attribute.Int("rss.bytes", len(body)))
+		endSpan(span, err)
+	}()
+
+	key, cacheable := cacheKeyFor(rawURL, opts)
+	if cacheable && !opts.Refresh {
+		if entry, ok := opts.Cache.Get(key); ok {
+			cacheHitsTotal.Add(1)
+			span.SetAttributes(attribute.Bool("rss.cache_hit", true))
+			if limit := opts.maxBytes(); limit > 0 && int64(len(entry.Body)) > limit {
+				return nil, 0, newUpstreamErr(CodeTooLarge, fmt.Errorf("RSS 内容超过限制: %d bytes", limit))
+			}
+			return entry.Body, entry.MaxAge, nil
+		}
+		cacheMissesTotal.Add(1)
+	}
+
+	body, maxAge, err = fetchUpstream(ctx, rawURL, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if cacheable {
+		now := time.Now()
+		opts.Cache.Set(key, cache.Entry{Body: body, FetchedAt: now, ExpiresAt: now.Add(opts.CacheTTL), MaxAge: maxAge})
+	}
+	return body, maxAge, nil
+}
+
+// hostOf 返回 URL 的主机名，用于 span 属性，解析失败时返回空字符串。
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// CacheKey 返回 feed URL 对应的缓存键，与转换时一样先经 canonicalFeedURL 规范化
+// （主机小写、去掉默认端口与片段）；URL 不合法或内嵌凭据时返回空字符串。
+func CacheKey(rawURL string) string {
+	u, err := url.Parse(canonicalFeedURL(rawURL, false))
+	if err != nil || u.User != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// CacheKeyVariants 返回 feed URL 可能存储在缓存中的全部键：规范化后的键，以及请求带
+// strip_tracking 时去除跟踪参数后的键（与前者不同时）。用于按 URL 清除缓存。
+func CacheKeyVariants(rawURL string) []string {
+	key := CacheKey(rawURL)
+	if key == "" {
+		return nil
+	}
+	keys := []string{key}
+	if stripped := CacheKey(canonicalFeedURL(rawURL, true)); stripped != "" && stripped != key {
+		keys = append(keys, stripped)
+	}
+	return keys
+}
+
+// cacheKeyFor 判断本次请求能否使用缓存。带凭据或自定义请求头的请求可能拿到
+// 因人而异的内容，一律不缓存；关闭证书校验（insecure）或单独调整 max_bytes 抓到的内容
+// 不能提供给默认请求，同样不读写共享缓存。
+func cacheKeyFor(rawURL string, opts Options) (string, bool) {
+	if opts.Cache == nil || opts.CacheTTL <= 0 {
+		return "", false
+	}
+	if opts.Username != "" || opts.Password != "" || opts.UserAgent != "" || len(opts.Headers) > 0 || opts.Insecure || opts.MaxBytes > 0 {
+		return "", false
+	}
+	key := CacheKey(rawURL)
+	return key, key != ""
+}
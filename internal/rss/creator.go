@@ -0,0 +1,99 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// atomPerson 对应 Atom <author> 的子元素，gofeed 转换为 Person 时丢弃了 <uri>。
+type atomPerson struct {
+	URI string `xml:"uri"`
+}
+
+// creatorLinks 为 gofeed 丢弃的作者主页与打赏链接，供创作者支持类界面使用。
+type creatorLinks struct {
+	// feedAuthorURL 为频道级第一个带 <uri> 的 <author>。
+	feedAuthorURL string
+	// paymentURL 为频道级第一个 rel="payment" 的 <link>（含 RSS 中的 atom:link）。
+	paymentURL string
+	// itemAuthorURLs 与条目顺序一致，没有作者 <uri> 的条目为空字符串。
+	itemAuthorURLs []string
+}
+
+// extractCreatorLinks 扫描频道与各条目的 <author><uri> 以及频道级 <link rel="payment">。
+// 条目内的 rel="payment" 链接已随 links 输出，这里不重复收集。
+func extractCreatorLinks(body []byte) creatorLinks {
+	var out creatorLinks
+	if len(body) == 0 {
+		return out
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	var scope itemScope
+	current := ""
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return out
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if scope.start(t) {
+				current = ""
+				continue
+			}
+			switch strings.ToLower(t.Name.Local) {
+			case "author":
+				var person atomPerson
+				if err := decoder.DecodeElement(&person, &t); err != nil {
+					return out
+				}
+				scope.skipped()
+				uri := creatorURL(person.URI)
+				if scope.inItem() {
+					if current == "" {
+						current = uri
+					}
+				} else if out.feedAuthorURL == "" {
+					out.feedAuthorURL = uri
+				}
+			case "link":
+				if !scope.inItem() && out.paymentURL == "" && strings.EqualFold(attrValue(t.Attr, "rel"), "payment") {
+					out.paymentURL = creatorURL(attrValue(t.Attr, "href"))
+				}
+			}
+		case xml.EndElement:
+			if scope.end() {
+				out.itemAuthorURLs = append(out.itemAuthorURLs, current)
+			}
+		}
+	}
+	return out
+}
+
+// creatorURL 去除首尾空白，丢弃 javascript: 等脚本地址。
+func creatorURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if isScriptURL(raw) {
+		return ""
+	}
+	return raw
+}
+
+// attrValue 返回名为 name 的属性值（不区分大小写），不存在时返回空字符串。
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, attr := range attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return attr.Value
+		}
+	}
+	return ""
+}
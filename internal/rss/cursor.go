@@ -0,0 +1,95 @@
+package rss
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// itemTime 返回条目的发布时间，缺失时取更新时间，都没有时返回零值。
+func itemTime(item *gofeed.Item) time.Time {
+	switch {
+	case item.PublishedParsed != nil:
+		return *item.PublishedParsed
+	case item.UpdatedParsed != nil:
+		return *item.UpdatedParsed
+	}
+	return time.Time{}
+}
+
+// applyCursor 只保留游标之后的新条目，items 与 feed.Items 同步过滤。带日期的条目按时间比较；
+// 无日期的条目按 feed 顺序，排在游标条目之前的视为新条目，游标条目已不在 feed 中时全部保留。
+func applyCursor(feed *gofeed.Feed, items []*model.ItemMeta, cursor *model.Cursor) []*model.ItemMeta {
+	cursorIdx := -1
+	for i, item := range feed.Items {
+		if item != nil && item.GUID == cursor.ID {
+			cursorIdx = i
+			break
+		}
+	}
+	keptItems := feed.Items[:0]
+	kept := items[:0]
+	for i, item := range feed.Items {
+		if item == nil || i == cursorIdx {
+			continue
+		}
+		if t := itemTime(item); !t.IsZero() && !cursor.Time.IsZero() {
+			if !t.After(cursor.Time) {
+				continue
+			}
+		} else if cursorIdx >= 0 && i > cursorIdx {
+			continue
+		}
+		keptItems = append(keptItems, item)
+		kept = append(kept, items[i])
+	}
+	feed.Items = keptItems
+	return kept
+}
+
+// keepOldestItems 保留 items 中最早的 n 条，items 与 feed.Items 同步裁剪并保持 feed 顺序。
+// 两条都带日期时按时间比较，否则按 feed 顺序，越靠后越早，与 applyCursor 对无日期条目的处理一致。
+func keepOldestItems(feed *gofeed.Feed, items []*model.ItemMeta, n int) []*model.ItemMeta {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := itemTime(feed.Items[order[a]]), itemTime(feed.Items[order[b]])
+		if !ta.IsZero() && !tb.IsZero() && !ta.Equal(tb) {
+			return ta.Before(tb)
+		}
+		return order[a] > order[b]
+	})
+	keep := order[:n]
+	sort.Ints(keep)
+	keptItems := make([]*gofeed.Item, 0, n)
+	kept := make([]*model.ItemMeta, 0, n)
+	for _, i := range keep {
+		keptItems = append(keptItems, feed.Items[i])
+		kept = append(kept, items[i])
+	}
+	feed.Items = keptItems
+	return kept
+}
+
+// nextCursor 返回指向已返回条目中最新一条的游标：优先取发布时间最晚的，都没有日期时取 feed 中的第一条；
+// 没有返回条目时沿用 prev。
+func nextCursor(items []*model.ItemMeta, prev *model.Cursor) *model.Cursor {
+	var next *model.Cursor
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		t := itemTime(meta.Item)
+		if next == nil || t.After(next.Time) {
+			next = &model.Cursor{ID: meta.GUID, Time: t}
+		}
+	}
+	if next == nil {
+		return prev
+	}
+	return next
+}
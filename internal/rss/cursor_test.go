@@ -0,0 +1,134 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+// cursorFeed 生成按给定顺序排列条目的 RSS，每个条目为 "guid@pubDate"，pubDate 为空表示无日期。
+func cursorFeed(entries ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>Cursor</title>`)
+	for _, entry := range entries {
+		guid, date, _ := strings.Cut(entry, "@")
+		fmt.Fprintf(&b, `<item><title>%s</title><guid>%s</guid>`, guid, guid)
+		if date != "" {
+			fmt.Fprintf(&b, `<pubDate>%s</pubDate>`, date)
+		}
+		b.WriteString(`</item>`)
+	}
+	b.WriteString(`</channel></rss>`)
+	return b.String()
+}
+
+// convertWithCursor 转换给定 feed 内容，返回条目 GUID 与响应中的下一个游标。
+func convertWithCursor(t *testing.T, body, cursor string) ([]string, string) {
+	t.Helper()
+	return convertWithCursorCount(t, body, cursor, 0)
+}
+
+// convertWithCursorCount 同 convertWithCursor，count 大于 0 时限制返回条目数。
+func convertWithCursorCount(t *testing.T, body, cursor string, count int) ([]string, string) {
+	t.Helper()
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+	parsed, err := model.ParseCursor(cursor)
+	if err != nil {
+		t.Fatalf("parse cursor: %v", err)
+	}
+	resp, err := ConvertWithOptions(context.Background(), "https://example.com/cursor.xml", Options{Cursor: parsed, Count: count})
+	if err != nil {
+		t.Fatalf("convert error: %v", err)
+	}
+	var guids []string
+	for _, item := range resp.Items {
+		guids = append(guids, item.GUID)
+	}
+	return guids, resp.Meta.Cursor
+}
+
+func TestConvertCursorAdvances(t *testing.T) {
+	first := cursorFeed("b@Tue, 02 Jan 2024 10:00:00 GMT", "a@Mon, 01 Jan 2024 10:00:00 GMT")
+	guids, cursor := convertWithCursor(t, first, "")
+	if strings.Join(guids, ",") != "b,a" || cursor == "" {
+		t.Fatalf("expected all items and a cursor, got %v %q", guids, cursor)
+	}
+
+	// 第二次轮询时 feed 新增了 c 与 d（d 的日期较早但排在前面），只返回比 b 新的条目。
+	second := cursorFeed("d@Wed, 03 Jan 2024 09:00:00 GMT", "c@Thu, 04 Jan 2024 10:00:00 GMT", "b@Tue, 02 Jan 2024 10:00:00 GMT", "a@Mon, 01 Jan 2024 10:00:00 GMT")
+	guids, next := convertWithCursor(t, second, cursor)
+	if strings.Join(guids, ",") != "d,c" {
+		t.Fatalf("expected only new items, got %v", guids)
+	}
+	if next == cursor {
+		t.Fatal("expected the cursor to advance")
+	}
+	if parsed, _ := model.ParseCursor(next); parsed.ID != "c" {
+		t.Fatalf("expected the cursor to point at the newest item c, got %+v", parsed)
+	}
+
+	// 没有新条目时不返回条目，游标保持不变。
+	guids, same := convertWithCursor(t, second, next)
+	if len(guids) != 0 || same != next {
+		t.Fatalf("expected no items and the same cursor, got %v %q", guids, same)
+	}
+}
+
+func TestConvertCursorWithCountDeliversEveryItem(t *testing.T) {
+	_, cursor := convertWithCursor(t, cursorFeed("a@Mon, 01 Jan 2024 10:00:00 GMT"), "")
+
+	// 比游标新的条目有 4 条、count=2：两次轮询依次返回，不跳过任何条目。
+	feed := cursorFeed(
+		"e@Fri, 05 Jan 2024 10:00:00 GMT",
+		"d@Thu, 04 Jan 2024 10:00:00 GMT",
+		"c@Wed, 03 Jan 2024 10:00:00 GMT",
+		"b@Tue, 02 Jan 2024 10:00:00 GMT",
+		"a@Mon, 01 Jan 2024 10:00:00 GMT",
+	)
+	guids, cursor := convertWithCursorCount(t, feed, cursor, 2)
+	if strings.Join(guids, ",") != "c,b" {
+		t.Fatalf("expected the two oldest new items first, got %v", guids)
+	}
+	guids, cursor = convertWithCursorCount(t, feed, cursor, 2)
+	if strings.Join(guids, ",") != "e,d" {
+		t.Fatalf("expected the remaining new items next, got %v", guids)
+	}
+	if guids, _ = convertWithCursorCount(t, feed, cursor, 2); len(guids) != 0 {
+		t.Fatalf("expected no items left, got %v", guids)
+	}
+
+	// 无日期条目按 feed 顺序同样分批返回。
+	_, cursor = convertWithCursor(t, cursorFeed("x"), "")
+	undated := cursorFeed("z", "y", "w", "x")
+	guids, cursor = convertWithCursorCount(t, undated, cursor, 2)
+	if strings.Join(guids, ",") != "y,w" {
+		t.Fatalf("expected the undated items nearest the cursor first, got %v", guids)
+	}
+	if guids, _ = convertWithCursorCount(t, undated, cursor, 2); strings.Join(guids, ",") != "z" {
+		t.Fatalf("expected the remaining undated item next, got %v", guids)
+	}
+}
+
+func TestConvertCursorUndatedItems(t *testing.T) {
+	guids, cursor := convertWithCursor(t, cursorFeed("y", "x"), "")
+	if strings.Join(guids, ",") != "y,x" {
+		t.Fatalf("unexpected items %v", guids)
+	}
+	guids, _ = convertWithCursor(t, cursorFeed("z", "y", "x"), cursor)
+	if strings.Join(guids, ",") != "z" {
+		t.Fatalf("expected items before the cursor item in feed order, got %v", guids)
+	}
+}
+
+func TestParseCursorRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"not base64!", "bm90IGpzb24", model.Cursor{}.Encode()} {
+		if _, err := model.ParseCursor(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
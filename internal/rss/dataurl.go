@@ -0,0 +1,64 @@
+package rss
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// isDataURL 判断 url 是否为 data: URI。
+func isDataURL(raw string) bool {
+	return len(raw) >= 5 && strings.EqualFold(raw[:5], "data:")
+}
+
+// decodeDataURL 解码 data: URI 中内联的 feed 内容，不发起任何网络请求。
+// 解码后的大小与远程 feed 一样受 RSS_MAX_BYTES（或 max_bytes）限制；
+// 查询参数中未编码的 + 会被解析为空格，base64 内容中的空格按 + 还原。
+func decodeDataURL(raw string, opts Options) ([]byte, error) {
+	if opts.DisableDataURLs {
+		return nil, newInvalidInputErr(CodeInvalidURL, errors.New("data: URI 已被服务端禁用"))
+	}
+	header, payload, ok := strings.Cut(raw[len("data:"):], ",")
+	if !ok {
+		return nil, newInvalidInputErr(CodeInvalidURL, errors.New("data: URI 缺少逗号分隔的内容"))
+	}
+	limit := opts.maxBytes()
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		payload = strings.ReplaceAll(payload, " ", "+")
+		if limit > 0 && int64(base64.StdEncoding.DecodedLen(len(payload))) > limit+2 {
+			return nil, newUpstreamErr(CodeTooLarge, fmt.Errorf("RSS 内容超过限制: %d bytes", limit))
+		}
+		body, err := decodeBase64(payload)
+		if err != nil {
+			return nil, newInvalidInputErr(CodeInvalidURL, fmt.Errorf("data: URI 的 base64 内容不合法: %w", err))
+		}
+		return checkDataSize(body, limit)
+	}
+	body, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, newInvalidInputErr(CodeInvalidURL, fmt.Errorf("data: URI 内容不合法: %w", err))
+	}
+	return checkDataSize([]byte(body), limit)
+}
+
+// decodeBase64 依次尝试标准与 URL 安全字母表，兼容省略填充的写法。
+func decodeBase64(payload string) ([]byte, error) {
+	payload = strings.TrimRight(payload, "=")
+	body, err := base64.RawStdEncoding.DecodeString(payload)
+	if err == nil {
+		return body, nil
+	}
+	if body, urlErr := base64.RawURLEncoding.DecodeString(payload); urlErr == nil {
+		return body, nil
+	}
+	return nil, err
+}
+
+func checkDataSize(body []byte, limit int64) ([]byte, error) {
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, newUpstreamErr(CodeTooLarge, fmt.Errorf("RSS 内容超过限制: %d bytes", limit))
+	}
+	return body, nil
+}
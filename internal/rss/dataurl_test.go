@@ -0,0 +1,62 @@
+package rss
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConvertDataURL(t *testing.T) {
+	doer := &countingDoer{status: http.StatusInternalServerError}
+	restore := WithHTTPClient(doer)
+	defer restore()
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(sampleRSS))
+	for name, raw := range map[string]string{
+		"base64":         "data:application/rss+xml;base64," + encoded,
+		"plus as space":  "data:application/rss+xml;base64," + strings.ReplaceAll(encoded, "+", " "),
+		"no padding":     "DATA:application/rss+xml;base64," + strings.TrimRight(encoded, "="),
+		"percent-encode": "data:application/rss+xml," + strings.ReplaceAll(sampleRSS, "\n", "%0A"),
+	} {
+		resp, err := ConvertWithOptions(context.Background(), raw, Options{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if resp.Feed == nil || resp.Feed.Title == "" || len(resp.Items) == 0 {
+			t.Fatalf("%s: unexpected response %+v", name, resp)
+		}
+		if resp.Feed.RequestedURL != "" {
+			t.Fatalf("%s: expected data URI not echoed back, got %q", name, resp.Feed.RequestedURL)
+		}
+	}
+	if got := doer.calls.Load(); got != 0 {
+		t.Fatalf("expected no network calls, got %d", got)
+	}
+}
+
+func TestConvertDataURLRejected(t *testing.T) {
+	restore := WithHTTPClient(&countingDoer{status: http.StatusInternalServerError})
+	defer restore()
+	t.Setenv(maxFeedBytesEnv, "2048")
+
+	oversized := "data:application/rss+xml;base64," + base64.StdEncoding.EncodeToString([]byte(sampleRSS+strings.Repeat(" ", 4096)))
+	cases := []struct {
+		name string
+		raw  string
+		opts Options
+		code ErrorCode
+	}{
+		{"oversized", oversized, Options{}, CodeTooLarge},
+		{"disabled", "data:application/rss+xml;base64," + base64.StdEncoding.EncodeToString([]byte(sampleRSS)), Options{DisableDataURLs: true}, CodeInvalidURL},
+		{"bad base64", "data:application/rss+xml;base64,!!!", Options{}, CodeInvalidURL},
+		{"missing comma", "data:application/rss+xml;base64", Options{}, CodeInvalidURL},
+	}
+	for _, tc := range cases {
+		_, err := ConvertWithOptions(context.Background(), tc.raw, tc.opts)
+		if ErrorCodeOf(err) != tc.code {
+			t.Fatalf("%s: expected %s, got %v", tc.name, tc.code, err)
+		}
+	}
+}
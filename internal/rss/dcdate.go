@@ -0,0 +1,74 @@
+package rss
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// dcDateLayouts 为 gofeed 无法解析时尝试的 dc:date 写法，包括 W3CDTF 的省略形式与
+// 中文站点常见的斜杠、年月日格式。
+var dcDateLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006-01-02",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15:04",
+	"2006年1月2日",
+	"2006-01",
+	"2006",
+}
+
+// applyDCDates 为没有可解析发布时间、但带有 dc:date 的条目补充 published 时间戳。
+// dc:date 不含时区时按 loc 解释，loc 为 nil 时按 UTC。
+func applyDCDates(items []*gofeed.Item, loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, item := range items {
+		if item == nil || item.PublishedParsed != nil || item.DublinCoreExt == nil {
+			continue
+		}
+		raw := firstDCValue(item.DublinCoreExt.Date)
+		if raw == "" {
+			continue
+		}
+		parsed, ok := parseDCDate(raw, loc)
+		if !ok {
+			continue
+		}
+		item.PublishedParsed = &parsed
+		if strings.TrimSpace(item.Published) == "" {
+			item.Published = raw
+		}
+	}
+}
+
+// parseDCDate 依次尝试 dcDateLayouts，返回第一个成功的结果。
+func parseDCDate(raw string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dcDateLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// firstDCValue 返回 dc 元素中第一个去除首尾空白后非空的值。
+func firstDCValue(values []string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
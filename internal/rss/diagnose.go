@@ -0,0 +1,142 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/html/charset"
+)
+
+// fetchDiag 由 fetchUpstream 填充，供 Validate 输出诊断信息。
+type fetchDiag struct {
+	contentType string
+}
+
+// xmlEncodingPattern 匹配 XML 声明中的 encoding 属性。
+var xmlEncodingPattern = regexp.MustCompile(`^<\?xml[^>]*\bencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// errorLinePattern 从解析器错误信息中提取行号。
+var errorLinePattern = regexp.MustCompile(`\bline (\d+)`)
+
+// diagnose 检查上游内容的类型、编码与 XML 良构性，不做完整解析。
+func diagnose(body []byte, contentType string) *model.Diagnostics {
+	d := &model.Diagnostics{ContentType: contentType, Bytes: len(body)}
+	d.Encoding, d.EncodingSource = detectEncoding(body, contentType)
+	d.FeedTypeGuess = guessFeedType(body, contentType)
+	if d.FeedTypeGuess == "json" {
+		d.WellFormed = json.Valid(body)
+		return d
+	}
+	d.XMLError = scanXML(body)
+	d.WellFormed = d.XMLError == nil
+	return d
+}
+
+// detectEncoding 按 BOM、Content-Type charset、XML 声明的优先级判断编码，均未声明时为 utf-8。
+func detectEncoding(body []byte, contentType string) (string, string) {
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return "utf-8", "bom"
+	case bytes.HasPrefix(body, []byte("\xfe\xff")):
+		return "utf-16be", "bom"
+	case bytes.HasPrefix(body, []byte("\xff\xfe")):
+		return "utf-16le", "bom"
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(params["charset"]), "content-type"
+	}
+	head := body
+	if len(head) > 256 {
+		head = head[:256]
+	}
+	if m := xmlEncodingPattern.FindSubmatch(bytes.TrimLeft(head, " \t\r\n")); m != nil {
+		return strings.ToLower(string(m[1])), "xml-declaration"
+	}
+	return "utf-8", "default"
+}
+
+// guessFeedType 按内容开头推测 feed 类型，HTML 页面单独标出。
+func guessFeedType(body []byte, contentType string) string {
+	if isHTMLPage(contentType, body) {
+		return "html"
+	}
+	switch gofeed.DetectFeedType(bytes.NewReader(body)) {
+	case gofeed.FeedTypeRSS:
+		return "rss"
+	case gofeed.FeedTypeAtom:
+		return "atom"
+	case gofeed.FeedTypeJSON:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
+
+// scanXML 以严格模式逐个读取 XML token，返回第一个错误及其位置；良构时返回 nil。
+func scanXML(body []byte) *model.DiagnosticError {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.CharsetReader = charset.NewReaderLabel
+	for {
+		_, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			line, column := dec.InputPos()
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line = syntaxErr.Line
+			}
+			return &model.DiagnosticError{Message: err.Error(), Line: line, Column: column}
+		}
+	}
+}
+
+// parseDiagnostic 将解析器错误转为诊断错误，尽量带上行号。
+func parseDiagnostic(err error) *model.DiagnosticError {
+	d := &model.DiagnosticError{Message: err.Error()}
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		d.Line = syntaxErr.Line
+	} else if m := errorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+	}
+	return d
+}
+
+// missingElements 统计频道与条目缺少的常用必需元素。
+func missingElements(feed *gofeed.Feed, d *model.Diagnostics) {
+	if strings.TrimSpace(feed.Title) == "" {
+		d.FeedMissing = append(d.FeedMissing, "title")
+	}
+	if strings.TrimSpace(feed.Link) == "" && len(feed.Links) == 0 {
+		d.FeedMissing = append(d.FeedMissing, "link")
+	}
+	missing := make(map[string]int)
+	for _, item := range feed.Items {
+		if strings.TrimSpace(item.Title) == "" {
+			missing["title"]++
+		}
+		if strings.TrimSpace(item.Link) == "" && len(item.Links) == 0 {
+			missing["link"]++
+		}
+		if strings.TrimSpace(item.GUID) == "" {
+			missing["guid"]++
+		}
+		if item.PublishedParsed == nil && item.UpdatedParsed == nil {
+			missing["published"]++
+		}
+	}
+	if len(missing) > 0 {
+		d.ItemsMissing = missing
+	}
+}
@@ -0,0 +1,387 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dnsServerEnv      = "RSS_DNS_SERVER"
+	dnsTimeoutEnv     = "RSS_DNS_TIMEOUT"
+	dnsCacheTTLEnv    = "RSS_DNS_CACHE_TTL"
+	dnsNegativeTTLEnv = "RSS_DNS_NEGATIVE_TTL"
+	ipVersionEnv      = "RSS_IP_VERSION"
+
+	// defaultDNSCacheTTL/defaultDNSNegativeTTL 为解析成功与失败结果的默认缓存时长。
+	defaultDNSCacheTTL    = 30 * time.Second
+	defaultDNSNegativeTTL = 5 * time.Second
+	// dnsCacheMaxEntries 为解析缓存的条目上限，写满后先清理过期条目，仍满则不再写入。
+	dnsCacheMaxEntries = 10000
+)
+
+// errNonPublicAddress 表示要求只连接公网地址的请求解析到了内网地址。
+var errNonPublicAddress = errors.New("refusing to dial non-public address")
+
+// dnsServerFromEnv 读取 RSS_DNS_SERVER（如 10.0.0.2 或 10.0.0.2:5353），未写端口时使用 53。
+func dnsServerFromEnv() string {
+	raw := strings.TrimSpace(os.Getenv(dnsServerEnv))
+	if raw == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(raw); err == nil {
+		return raw
+	}
+	return net.JoinHostPort(strings.Trim(raw, "[]"), "53")
+}
+
+// dnsTimeoutFromEnv 读取 RSS_DNS_TIMEOUT（如 500ms），0 或未设置表示不单独限制解析耗时。
+func dnsTimeoutFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(dnsTimeoutEnv))
+	if raw == "" {
+		return 0
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil || val < 0 {
+		log.Printf("[warn] ignoring invalid %s=%q", dnsTimeoutEnv, raw)
+		return 0
+	}
+	return val
+}
+
+// dnsDurationFromEnv 读取解析缓存时长，未设置时使用 def，0 表示不缓存。
+func dnsDurationFromEnv(name string, def time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return def
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil || val < 0 {
+		log.Printf("[warn] ignoring invalid %s=%q", name, raw)
+		return def
+	}
+	return val
+}
+
+// ipVersionFromEnv 读取 RSS_IP_VERSION，返回 "4"、"6" 或空串（双栈）。
+func ipVersionFromEnv() string {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv(ipVersionEnv)))
+	switch strings.TrimPrefix(raw, "ipv") {
+	case "":
+		return ""
+	case "4":
+		return "4"
+	case "6":
+		return "6"
+	default:
+		log.Printf("[warn] ignoring invalid %s=%q", ipVersionEnv, raw)
+		return ""
+	}
+}
+
+// constrainNetwork 按 RSS_IP_VERSION 将 tcp 限定为 tcp4 或 tcp6，用于 IPv6 不可用时会卡住的双栈主机。
+func constrainNetwork(network, version string) string {
+	if version == "" || network != "tcp" {
+		return network
+	}
+	return network + version
+}
+
+// newDialer 返回出站连接使用的 Dialer；设置 RSS_DNS_SERVER 时使用纯 Go 解析器，
+// 所有查询都发往该服务器。
+func newDialer() *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	if server := dnsServerFromEnv(); server != "" {
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return dialer
+}
+
+// newDialContext 在 Dialer 之上按 RSS_IP_VERSION 限定地址族，按 RSS_DNS_TIMEOUT 单独限制域名解析耗时，
+// 并按 RSS_DNS_CACHE_TTL/RSS_DNS_NEGATIVE_TTL 缓存解析结果，解析完成后依次尝试各个地址。
+func newDialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	resolver := dialer.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	hosts := &hostResolver{
+		lookup:      resolver.LookupIP,
+		timeout:     dnsTimeoutFromEnv(),
+		ttl:         dnsDurationFromEnv(dnsCacheTTLEnv, defaultDNSCacheTTL),
+		negativeTTL: dnsDurationFromEnv(dnsNegativeTTLEnv, defaultDNSNegativeTTL),
+		cache:       resolvedHosts,
+	}
+	return dialResolved(dialer, hosts, ipVersionFromEnv())
+}
+
+// dialResolved 返回先经 hosts 解析再拨号的 DialContext。未启用解析超时与缓存、且 ctx 不要求
+// 目标为公网地址时直接交给 Dialer；否则自行按 Happy Eyeballs（RFC 6555）在两个地址族间回退，
+// 与 Dialer 自带的双栈行为一致。
+func dialResolved(dialer *net.Dialer, hosts *hostResolver, version string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		network = constrainNetwork(network, version)
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		// 经代理访问时拨号目标为代理本身，不做公网检查。
+		publicOnly := strings.EqualFold(publicDialHost(ctx), host)
+		if !publicOnly && !hosts.enabled() {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		var ips []net.IP
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+		} else if ips, err = hosts.resolve(ctx, ipNetwork(network), host); err != nil {
+			return nil, err
+		}
+		serial := func(ctx context.Context, ips []net.IP) (net.Conn, error) {
+			var firstErr error
+			for _, ip := range ips {
+				// 对缓存与新解析的地址都在拨号前检查，拨号使用的正是检查过的地址，
+				// 避免域名在检查后改指内网（DNS rebinding）。
+				var err error
+				if publicOnly && !publicIP(ip) {
+					err = fmt.Errorf("%w: %s resolves to %s", errNonPublicAddress, host, ip)
+				} else {
+					var conn net.Conn
+					if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+						return conn, nil
+					}
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			return nil, firstErr
+		}
+		primaries, fallbacks := splitAddressFamilies(ips)
+		if len(fallbacks) == 0 || dialer.FallbackDelay < 0 {
+			return serial(ctx, primaries)
+		}
+		return dialParallel(ctx, fallbackDelay(dialer), serial, primaries, fallbacks)
+	}
+}
+
+// defaultFallbackDelay 与标准库一致：首选地址族 300ms 内未连上时并行尝试另一地址族。
+const defaultFallbackDelay = 300 * time.Millisecond
+
+func fallbackDelay(dialer *net.Dialer) time.Duration {
+	if dialer.FallbackDelay > 0 {
+		return dialer.FallbackDelay
+	}
+	return defaultFallbackDelay
+}
+
+// splitAddressFamilies 按第一个地址的地址族将地址分为首选与回退两组，各自保持解析顺序。
+func splitAddressFamilies(ips []net.IP) (primaries, fallbacks []net.IP) {
+	if len(ips) == 0 {
+		return nil, nil
+	}
+	primaryV4 := ips[0].To4() != nil
+	for _, ip := range ips {
+		if (ip.To4() != nil) == primaryV4 {
+			primaries = append(primaries, ip)
+		} else {
+			fallbacks = append(fallbacks, ip)
+		}
+	}
+	return primaries, fallbacks
+}
+
+// dialParallel 先拨首选地址族，delay 后（或首选组提前全部失败时）并行拨回退地址族，
+// 返回最先建立的连接并关闭落后的连接；都失败时返回首选组的错误。
+func dialParallel(ctx context.Context, delay time.Duration, serial func(context.Context, []net.IP) (net.Conn, error), primaries, fallbacks []net.IP) (net.Conn, error) {
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+		done    bool
+	}
+	results := make(chan dialResult)
+	returned := make(chan struct{})
+	defer close(returned)
+
+	race := func(ctx context.Context, primary bool) {
+		ips := primaries
+		if !primary {
+			ips = fallbacks
+		}
+		conn, err := serial(ctx, ips)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary, done: true}:
+		case <-returned:
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	primaryCtx, primaryCancel := context.WithCancel(ctx)
+	defer primaryCancel()
+	go race(primaryCtx, true)
+
+	fallbackTimer := time.NewTimer(delay)
+	defer fallbackTimer.Stop()
+
+	var primary, fallback dialResult
+	for {
+		select {
+		case <-fallbackTimer.C:
+			fallbackCtx, fallbackCancel := context.WithCancel(ctx)
+			defer fallbackCancel()
+			go race(fallbackCtx, false)
+		case res := <-results:
+			if res.err == nil {
+				return res.conn, nil
+			}
+			if res.primary {
+				primary = res
+			} else {
+				fallback = res
+			}
+			if primary.done && fallback.done {
+				return nil, primary.err
+			}
+			if res.primary && fallbackTimer.Stop() {
+				// 首选组已全部失败，不必再等待回退延迟。
+				fallbackTimer.Reset(0)
+			}
+		}
+	}
+}
+
+// ipNetwork 将 tcp4/tcp6 等拨号网络映射为 LookupIP 使用的 ip4/ip6。
+func ipNetwork(network string) string {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return "ip4"
+	case strings.HasSuffix(network, "6"):
+		return "ip6"
+	default:
+		return "ip"
+	}
+}
+
+// hostResolver 按超时解析域名，并通过 cache 复用成功与失败的结果。
+type hostResolver struct {
+	lookup      func(ctx context.Context, network, host string) ([]net.IP, error)
+	timeout     time.Duration
+	ttl         time.Duration
+	negativeTTL time.Duration
+	cache       *dnsCache
+}
+
+// enabled 判断是否需要自行解析：启用了解析超时或任一缓存。
+func (r *hostResolver) enabled() bool {
+	return r.timeout > 0 || r.ttl > 0 || r.negativeTTL > 0
+}
+
+// resolve 返回 host 的地址，优先使用未过期的缓存结果。解析超时或调用方取消不写入失败缓存，
+// 以免一次慢查询让后续请求都直接失败。
+func (r *hostResolver) resolve(ctx context.Context, network, host string) ([]net.IP, error) {
+	key := network + "|" + strings.ToLower(host)
+	if ips, err, ok := r.cache.get(key); ok {
+		return ips, err
+	}
+	lookupCtx, cancel := ctx, context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		lookupCtx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	ips, err := r.lookup(lookupCtx, network, host)
+	cancel()
+	switch {
+	case err == nil && len(ips) > 0 && r.ttl > 0:
+		r.cache.set(key, ips, nil, r.ttl)
+	case err != nil && r.negativeTTL > 0 && !isTimeout(err) && ctx.Err() == nil:
+		r.cache.set(key, nil, err, r.negativeTTL)
+	}
+	return ips, err
+}
+
+type dnsEntry struct {
+	ips     []net.IP
+	err     error
+	expires time.Time
+}
+
+// dnsCache 按地址族与主机名记录解析结果，进程内所有出站客户端共享。
+type dnsCache struct {
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+	now     func() time.Time
+}
+
+var resolvedHosts = newDNSCache()
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{entries: make(map[string]dnsEntry), now: time.Now}
+}
+
+func (c *dnsCache) get(key string) ([]net.IP, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, nil, false
+	}
+	return entry.ips, entry.err, true
+}
+
+func (c *dnsCache) set(key string, ips []net.IP, err error, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= dnsCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= dnsCacheMaxEntries {
+			return
+		}
+	}
+	c.entries[key] = dnsEntry{ips: ips, err: err, expires: now.Add(ttl)}
+}
+
+type publicDialKey struct{}
+
+// withPublicDial 标记 ctx 下直连 host 的出站连接只允许公网地址，解析到内网地址时拒绝拨号。
+func withPublicDial(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, publicDialKey{}, host)
+}
+
+func publicDialHost(ctx context.Context) string {
+	host, _ := ctx.Value(publicDialKey{}).(string)
+	return host
+}
+
+// publicIP 判断地址不是回环、私有、链路本地、未指定或组播地址。
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast())
+}
+
+// isDNSFailure 判断下载失败是否出在域名解析（含解析超时），而非连接或读取。
+func isDNSFailure(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
@@ -0,0 +1,254 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewDialerUsesCustomResolver(t *testing.T) {
+	if d := newDialer(); d.Resolver != nil {
+		t.Fatal("expected system resolver without RSS_DNS_SERVER")
+	}
+
+	dns, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer dns.Close()
+	t.Setenv(dnsServerEnv, dns.LocalAddr().String())
+
+	d := newDialer()
+	if d.Resolver == nil || !d.Resolver.PreferGo {
+		t.Fatal("expected custom Go resolver")
+	}
+	conn, err := d.Resolver.Dial(context.Background(), "udp", "192.0.2.1:53")
+	if err != nil {
+		t.Fatalf("dial resolver: %v", err)
+	}
+	defer conn.Close()
+	if got := conn.RemoteAddr().String(); got != dns.LocalAddr().String() {
+		t.Fatalf("expected queries sent to %s, got %s", dns.LocalAddr(), got)
+	}
+}
+
+func TestDNSServerDefaultPort(t *testing.T) {
+	t.Setenv(dnsServerEnv, "10.0.0.2")
+	if got := dnsServerFromEnv(); got != "10.0.0.2:53" {
+		t.Fatalf("expected default port 53, got %s", got)
+	}
+}
+
+func TestDNSTimeoutBoundsResolution(t *testing.T) {
+	// 不回应任何查询的 DNS 服务器，解析只能以超时结束。
+	dns, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer dns.Close()
+	t.Setenv(dnsServerEnv, dns.LocalAddr().String())
+	t.Setenv(dnsTimeoutEnv, "100ms")
+
+	start := time.Now()
+	_, err = newDialContext(newDialer())(context.Background(), "tcp", "feed.example.test:443")
+	if err == nil {
+		t.Fatal("expected resolution to fail")
+	}
+	if !isTimeout(err) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected resolution bounded by RSS_DNS_TIMEOUT, took %v", elapsed)
+	}
+}
+
+func TestIPVersionConstrainsDialNetwork(t *testing.T) {
+	cases := map[string]string{"": "tcp", "4": "tcp4", "ipv6": "tcp6", "5": "tcp"}
+	for env, want := range cases {
+		t.Setenv(ipVersionEnv, env)
+		if got := constrainNetwork("tcp", ipVersionFromEnv()); got != want {
+			t.Fatalf("%s=%q: expected %s, got %s", ipVersionEnv, env, want, got)
+		}
+	}
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	t.Setenv(ipVersionEnv, "4")
+	conn, err := newDialContext(newDialer())(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("expected IPv4 dial to succeed: %v", err)
+	}
+	conn.Close()
+
+	t.Setenv(ipVersionEnv, "6")
+	if conn, err := newDialContext(newDialer())(context.Background(), "tcp", ln.Addr().String()); err == nil {
+		conn.Close()
+		t.Fatal("expected IPv4 address to be rejected when forced to IPv6")
+	}
+}
+
+// stubLookup 按主机返回固定地址，未登记的主机返回 NXDOMAIN，并记录查询次数。
+type stubLookup struct {
+	mu    sync.Mutex
+	addrs map[string]string
+	calls int
+}
+
+func (s *stubLookup) lookup(ctx context.Context, network, host string) ([]net.IP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	addr, ok := s.addrs[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IP{net.ParseIP(addr)}, nil
+}
+
+func (s *stubLookup) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestDNSCacheReusesResults(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	stub := &stubLookup{addrs: map[string]string{"feed.example.test": "127.0.0.1"}}
+	cache := newDNSCache()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return clock }
+	dial := dialResolved(&net.Dialer{}, &hostResolver{lookup: stub.lookup, ttl: time.Minute, negativeTTL: 5 * time.Second, cache: cache}, "")
+
+	for i := 0; i < 2; i++ {
+		conn, err := dial(context.Background(), "tcp", net.JoinHostPort("feed.example.test", port))
+		if err != nil {
+			t.Fatalf("dial %d: %v", i, err)
+		}
+		conn.Close()
+	}
+	if stub.count() != 1 {
+		t.Fatalf("expected second dial served from cache, got %d lookups", stub.count())
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := dial(context.Background(), "tcp", "missing.example.test:443"); !isDNSFailure(err) {
+			t.Fatalf("expected dns error, got %v", err)
+		}
+	}
+	if stub.count() != 2 {
+		t.Fatalf("expected negative result cached, got %d lookups", stub.count())
+	}
+
+	clock = clock.Add(6 * time.Second)
+	_, _ = dial(context.Background(), "tcp", "missing.example.test:443")
+	if stub.count() != 3 {
+		t.Fatalf("expected negative entry to expire, got %d lookups", stub.count())
+	}
+}
+
+func TestPublicDialRejectsCachedPrivateAddress(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	addr := net.JoinHostPort("rebind.example.test", port)
+
+	stub := &stubLookup{addrs: map[string]string{"rebind.example.test": "127.0.0.1"}}
+	dial := dialResolved(&net.Dialer{}, &hostResolver{lookup: stub.lookup, ttl: time.Minute, cache: newDNSCache()}, "")
+
+	// 普通抓取允许内网地址，同时写入缓存。
+	conn, err := dial(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.Close()
+
+	// 要求公网地址的请求即使命中缓存也要拒绝。
+	ctx := withPublicDial(context.Background(), "rebind.example.test")
+	if _, err := dial(ctx, "tcp", addr); !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("expected cached private address rejected, got %v", err)
+	}
+	if stub.count() != 1 {
+		t.Fatalf("expected cached lookup, got %d lookups", stub.count())
+	}
+	if _, err := dial(withPublicDial(context.Background(), "127.0.0.1"), "tcp", ln.Addr().String()); !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("expected private literal rejected, got %v", err)
+	}
+
+	// 标记只针对目标主机，经代理拨号时不检查代理地址。
+	conn, err = dial(withPublicDial(context.Background(), "t.co"), "tcp", addr)
+	if err != nil {
+		t.Fatalf("expected dial to other host allowed: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDNSFailureErrorCode(t *testing.T) {
+	restore := WithHTTPClient(&http.Client{Transport: &http.Transport{
+		DialContext: dialResolved(&net.Dialer{}, &hostResolver{lookup: (&stubLookup{}).lookup, cache: newDNSCache(), ttl: time.Minute}, ""),
+	}})
+	defer restore()
+
+	_, err := Convert(context.Background(), "http://nxdomain.example.test/rss")
+	if code := ErrorCodeOf(err); code != CodeDNSError {
+		t.Fatalf("expected %s, got %s (%v)", CodeDNSError, code, err)
+	}
+}
+
+func TestDialResolvedFallsBackAcrossAddressFamilies(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	// 首选的 IPv6 地址拨号一直挂起，模拟 IPv6 不通的双栈主机。
+	stalled := net.JoinHostPort("2001:db8::1", port)
+	dialer := &net.Dialer{
+		FallbackDelay: 20 * time.Millisecond,
+		ControlContext: func(ctx context.Context, network, address string, _ syscall.RawConn) error {
+			if address == stalled {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	}
+	lookup := func(ctx context.Context, network, host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("127.0.0.1")}, nil
+	}
+	dial := dialResolved(dialer, &hostResolver{lookup: lookup, ttl: time.Minute, cache: newDNSCache()}, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	conn, err := dial(ctx, "tcp", net.JoinHostPort("dualstack.example.test", port))
+	if err != nil {
+		t.Fatalf("expected IPv4 fallback to connect, got %v", err)
+	}
+	defer conn.Close()
+	if conn.RemoteAddr().String() != ln.Addr().String() {
+		t.Fatalf("expected connection to %s, got %s", ln.Addr(), conn.RemoteAddr())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected fallback after the fallback delay, took %s", elapsed)
+	}
+}
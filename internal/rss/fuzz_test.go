@@ -0,0 +1,100 @@
+package rss
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fuzzMaxInput 为模糊测试输入的长度上限，超过时跳过，避免单个用例耗时过长。
+const fuzzMaxInput = 256 << 10
+
+// fuzzSeeds 返回现有 RSS/Atom fixture 以及在其基础上变形得到的语料：截断、标签不闭合、
+// 条目嵌套与缩略图标签错位等野外常见的坏 feed。
+func fuzzSeeds() []string {
+	fixtures := []string{sampleRSS, sampleThumbnailRSS, sampleRSS091, sampleRDF, sampleAtom, sampleAtomLinks}
+	seeds := append([]string(nil), fixtures...)
+	for _, fixture := range fixtures {
+		seeds = append(seeds,
+			fixture[:len(fixture)/2],
+			strings.Replace(fixture, "</item>", "", 1),
+			strings.Replace(fixture, "</entry>", "", 1),
+			strings.ReplaceAll(fixture, "<item>", "<item><item>"),
+		)
+	}
+	seeds = append(seeds,
+		"",
+		"<rss>",
+		`<rss version="2.0"><channel><media:thumbnail url="https://example.com/t.jpg"/></channel></rss>`,
+		`<rss version="2.0"><channel><item><media:group><media:thumbnail url="a" width="x"/><media:thumbnail url="b" width="99999999999999999999"/></media:group></item></channel></rss>`,
+		`<feed xmlns="http://www.w3.org/2005/Atom"><entry><entry><link href="https://example.com/1"/></entry></entry></feed>`,
+		`<rss version="2.0"><item><title>root item</title></item></rss>`,
+		`<?xml version="1.0" encoding="bogus"?><rss><channel><item/></channel></rss>`,
+	)
+	return seeds
+}
+
+// FuzzConvertReader 将任意内容作为上游响应体走完整的转换流程，要求不 panic、
+// 条目数与缩略图对齐，且序列化结果的大小与输入成比例。
+func FuzzConvertReader(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add([]byte(seed))
+	}
+	// 每个输入都应重新解析，不能命中上一个输入留下的失败缓存。
+	f.Setenv(negativeCacheTTLEnv, "0")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > fuzzMaxInput {
+			t.Skip()
+		}
+		restore := WithHTTPClient(fakeDoer{body: string(data), status: http.StatusOK})
+		defer restore()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := ConvertWithOptions(ctx, "https://fuzz.example.com/rss", Options{Sanitize: true, StripTracking: true, MinifyHTML: true, SummaryLen: 50})
+		if err != nil {
+			if ErrorCodeOf(err) == "" {
+				t.Fatalf("error without code: %v", err)
+			}
+			return
+		}
+		out, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatalf("marshal error: %v", err)
+		}
+		if limit := 64*len(data) + 64<<10; len(out) > limit {
+			t.Fatalf("output of %d bytes for %d bytes of input exceeds %d", len(out), len(data), limit)
+		}
+	})
+}
+
+// FuzzExtractItemThumbnails 检查手写的条目扫描：任意输入不 panic，且能解析为 feed 时
+// 缩略图与链接的数量都不超过解析出的条目数。
+func FuzzExtractItemThumbnails(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > fuzzMaxInput {
+			t.Skip()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		thumbnails := extractItemThumbnails(ctx, data)
+		links := extractItemLinks(data)
+		feed, err := parseFeed(ctx, data)
+		if err != nil {
+			return
+		}
+		if len(thumbnails) > len(feed.Items) {
+			t.Fatalf("%d thumbnails for %d items", len(thumbnails), len(feed.Items))
+		}
+		if len(links) > len(feed.Items) {
+			t.Fatalf("%d link sets for %d items", len(links), len(feed.Items))
+		}
+	})
+}
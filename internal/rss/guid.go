@@ -0,0 +1,29 @@
+package rss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// syntheticGUIDPrefix 标识由 link 与标题生成的 GUID，避免与上游 GUID 混淆。
+const syntheticGUIDPrefix = "urn:rss2json:"
+
+// synthesizeGUIDs 为缺少 GUID 的条目（如 RSS 0.91 与 RDF）按 link 与标题的哈希生成稳定的 GUID，
+// 供去重与 id 字段使用；link 与标题都为空时保持为空。
+func synthesizeGUIDs(items []*gofeed.Item) {
+	for _, item := range items {
+		if item == nil || strings.TrimSpace(item.GUID) != "" {
+			continue
+		}
+		link := strings.TrimSpace(item.Link)
+		title := strings.TrimSpace(item.Title)
+		if link == "" && title == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(link + "\n" + title))
+		item.GUID = syntheticGUIDPrefix + hex.EncodeToString(sum[:16])
+	}
+}
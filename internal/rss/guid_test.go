@@ -0,0 +1,37 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestSynthesizeGUIDs(t *testing.T) {
+	items := []*gofeed.Item{
+		{Title: "A", Link: "https://example.com/a"},
+		{Title: "A", Link: "https://example.com/a", GUID: "upstream-guid"},
+		{Title: "B", Link: "https://example.com/a"},
+		{},
+		nil,
+	}
+	synthesizeGUIDs(items)
+	if !strings.HasPrefix(items[0].GUID, syntheticGUIDPrefix) {
+		t.Fatalf("expected synthetic guid, got %q", items[0].GUID)
+	}
+	if items[1].GUID != "upstream-guid" {
+		t.Fatalf("expected upstream guid kept, got %q", items[1].GUID)
+	}
+	if items[2].GUID == items[0].GUID {
+		t.Fatalf("expected title to affect guid, both %q", items[0].GUID)
+	}
+	if items[3].GUID != "" {
+		t.Fatalf("expected empty item to stay without guid, got %q", items[3].GUID)
+	}
+
+	again := []*gofeed.Item{{Title: "A", Link: "https://example.com/a"}}
+	synthesizeGUIDs(again)
+	if again[0].GUID != items[0].GUID {
+		t.Fatalf("expected stable guid, got %q then %q", items[0].GUID, again[0].GUID)
+	}
+}
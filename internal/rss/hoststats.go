@@ -0,0 +1,118 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+// hostStatsMaxHosts 为按主机统计的主机数上限，超过时淘汰最久未访问的主机。
+const hostStatsMaxHosts = 4096
+
+// hostStat 为单个上游主机的转换结果计数。
+type hostStat struct {
+	requests      int64
+	successes     int64
+	errors        map[ErrorCode]int64
+	lastSeen      time.Time
+	lastError     time.Time
+	lastErrorCode ErrorCode
+}
+
+// hostStats 按上游主机记录转换次数、成功数与按错误码分类的失败数，进程重启后清零。
+type hostStats struct {
+	mu       sync.Mutex
+	hosts    map[string]*hostStat
+	maxHosts int
+	now      func() time.Time
+}
+
+func newHostStats(maxHosts int) *hostStats {
+	return &hostStats{
+		hosts:    make(map[string]*hostStat),
+		maxHosts: maxHosts,
+		now:      time.Now,
+	}
+}
+
+// upstreamStats 为所有转换共享的按主机统计。
+var upstreamStats = newHostStats(hostStatsMaxHosts)
+
+// record 记录一次针对 host 的转换结果。客户端取消的请求不代表上游状况，不计入。
+func (s *hostStats) record(host string, err error) {
+	if host == "" || errors.Is(err, context.Canceled) {
+		return
+	}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stat, ok := s.hosts[host]
+	if !ok {
+		if len(s.hosts) >= s.maxHosts {
+			s.evictOldest()
+		}
+		stat = &hostStat{errors: make(map[ErrorCode]int64)}
+		s.hosts[host] = stat
+	}
+	stat.requests++
+	stat.lastSeen = now
+	if err == nil {
+		stat.successes++
+		return
+	}
+	code := ErrorCodeOf(err)
+	stat.errors[code]++
+	stat.lastError = now
+	stat.lastErrorCode = code
+}
+
+// evictOldest 淘汰最久未访问的主机，调用方需持有锁。
+func (s *hostStats) evictOldest() {
+	oldest := ""
+	var oldestSeen time.Time
+	for host, stat := range s.hosts {
+		if oldest == "" || stat.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = host, stat.lastSeen
+		}
+	}
+	delete(s.hosts, oldest)
+}
+
+// snapshot 返回按主机名排序的统计副本。
+func (s *hostStats) snapshot() []model.HostStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]model.HostStat, 0, len(s.hosts))
+	for host, stat := range s.hosts {
+		item := model.HostStat{
+			Host:      host,
+			Requests:  stat.requests,
+			Successes: stat.successes,
+			Errors:    stat.requests - stat.successes,
+		}
+		if len(stat.errors) > 0 {
+			item.ErrorsByCode = make(map[string]int64, len(stat.errors))
+			for code, n := range stat.errors {
+				item.ErrorsByCode[string(code)] = n
+			}
+			lastError := stat.lastError.UTC()
+			item.LastErrorAt = &lastError
+			item.LastErrorCode = string(stat.lastErrorCode)
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+// HostStats 返回进程启动以来按上游主机统计的转换结果，按主机名排序。
+func HostStats() []model.HostStat {
+	return upstreamStats.snapshot()
+}
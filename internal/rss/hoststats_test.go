@@ -0,0 +1,40 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHostStatsRecord(t *testing.T) {
+	s := newHostStats(2)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.record("a.example.com", nil)
+	s.record("a.example.com", newUpstreamErr(CodeFetchFailed, errors.New("refused")))
+	s.record("a.example.com", context.Canceled)
+	now = now.Add(time.Minute)
+	s.record("b.example.com", &FeedError{Kind: ErrorKindUpstream, Code: CodeUpstreamStatus, StatusCode: 503})
+
+	got := s.snapshot()
+	if len(got) != 2 || got[0].Host != "a.example.com" || got[1].Host != "b.example.com" {
+		t.Fatalf("unexpected hosts %+v", got)
+	}
+	a := got[0]
+	if a.Requests != 2 || a.Successes != 1 || a.Errors != 1 || a.ErrorsByCode["fetch_failed"] != 1 || a.LastErrorCode != "fetch_failed" {
+		t.Fatalf("unexpected stats for a: %+v", a)
+	}
+	if b := got[1]; b.LastErrorAt == nil || !b.LastErrorAt.Equal(now) || b.ErrorsByCode["upstream_status"] != 1 {
+		t.Fatalf("unexpected stats for b: %+v", b)
+	}
+
+	// 超过上限时淘汰最久未访问的主机。
+	now = now.Add(time.Minute)
+	s.record("c.example.com", nil)
+	got = s.snapshot()
+	if len(got) != 2 || got[0].Host != "b.example.com" || got[1].Host != "c.example.com" {
+		t.Fatalf("expected a.example.com evicted, got %+v", got)
+	}
+}
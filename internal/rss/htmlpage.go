@@ -0,0 +1,40 @@
+package rss
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+)
+
+// errHTMLPage 为上游返回 HTML 页面（如拒绝访问页、Cloudflare 验证页）而非 feed 时的错误。
+var errHTMLPage = errors.New("URL returned an HTML page, not a feed")
+
+// htmlPagePrefixes 为 HTML 文档常见的开头（已转为小写）。
+var htmlPagePrefixes = [][]byte{[]byte("<!doctype html"), []byte("<html")}
+
+// feedPrefixes 为 feed 内容常见的开头，Content-Type 误标为 text/html 时据此放行。
+var feedPrefixes = [][]byte{[]byte("<?xml"), []byte("<rss"), []byte("<feed"), []byte("<rdf"), []byte("{")}
+
+// isHTMLPage 判断上游响应是否为 HTML 页面：内容以 <!DOCTYPE html 或 <html 开头，
+// 或 Content-Type 为 text/html 且内容不像 feed。
+func isHTMLPage(contentType string, body []byte) bool {
+	head := bytes.ToLower(bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n"))
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	for _, prefix := range htmlPagePrefixes {
+		if bytes.HasPrefix(head, prefix) {
+			return true
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" {
+		return false
+	}
+	for _, prefix := range feedPrefixes {
+		if bytes.HasPrefix(head, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTMLPageRejected(t *testing.T) {
+	page := "\n<!DOCTYPE html><html><head><title>Access denied</title></head><body>Checking your browser</body></html>"
+	cases := []struct {
+		name string
+		doer fakeDoer
+	}{
+		{"doctype", fakeDoer{body: page, status: http.StatusOK}},
+		{"content type", fakeDoer{body: "<div>Just a moment...</div>", status: http.StatusOK, header: http.Header{"Content-Type": {"text/html; charset=utf-8"}}}},
+	}
+	for _, tc := range cases {
+		restore := WithHTTPClient(tc.doer)
+		_, err := Convert(context.Background(), "https://challenge.example.com/rss")
+		restore()
+		if ErrorCodeOf(err) != CodeHTMLPage || !strings.Contains(err.Error(), "HTML page, not a feed") {
+			t.Fatalf("%s: expected html_page error, got %v", tc.name, err)
+		}
+		if IsParseError(err) {
+			t.Fatalf("%s: expected upstream error, not a parse error", tc.name)
+		}
+	}
+}
+
+func TestFeedServedAsHTMLStillParses(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK, header: http.Header{"Content-Type": {"text/html"}}})
+	defer restore()
+
+	if _, err := Convert(context.Background(), "https://mislabeled.example.com/rss"); err != nil {
+		t.Fatalf("expected mislabeled feed to parse, got %v", err)
+	}
+}
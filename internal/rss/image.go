@@ -0,0 +1,76 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/html/charset"
+)
+
+// rssImage 对应 RSS 频道 <image> 的子元素。
+type rssImage struct {
+	URL    string `xml:"url"`
+	Title  string `xml:"title"`
+	Link   string `xml:"link"`
+	Width  string `xml:"width"`
+	Height string `xml:"height"`
+}
+
+// extractImage 读取频道级 <image>（RSS 2.0 位于 channel 内，RSS 1.0 与 channel 同级），
+// 忽略条目内的元素与没有 <url> 子元素的 itunes:image 等扩展；不存在时返回 nil。
+func extractImage(body []byte) *model.Image {
+	if len(body) == 0 {
+		return nil
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	depthInItem := 0
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == "item" || name == "entry" {
+				depthInItem++
+				continue
+			}
+			if depthInItem > 0 || name != "image" {
+				continue
+			}
+			var raw rssImage
+			if err := decoder.DecodeElement(&raw, &t); err != nil {
+				continue
+			}
+			if url := strings.TrimSpace(raw.URL); url != "" {
+				return &model.Image{
+					URL:    url,
+					Title:  strings.TrimSpace(raw.Title),
+					Link:   strings.TrimSpace(raw.Link),
+					Width:  positiveInt(raw.Width),
+					Height: positiveInt(raw.Height),
+				}
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if (name == "item" || name == "entry") && depthInItem > 0 {
+				depthInItem--
+			}
+		}
+	}
+}
+
+// positiveInt 解析正整数，无效或非正数时返回 0。
+func positiveInt(raw string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
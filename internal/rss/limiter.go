@@ -0,0 +1,156 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	upstreamRPSEnv     = "UPSTREAM_RPS_PER_HOST"
+	upstreamMaxWaitEnv = "UPSTREAM_MAX_WAIT"
+	defaultUpstreamRPS = 10.0
+	defaultMaxWait     = time.Second
+	limiterMaxHosts    = 4096
+)
+
+// tokenBucket 为单个主机的令牌桶，tokens 允许为负数表示已预约的排队请求。
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// hostLimiter 按目标主机名限制出站请求速率，桶数量有上限以保证内存有界。
+type hostLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	maxHosts int
+	now      func() time.Time
+}
+
+func newHostLimiter(maxHosts int) *hostLimiter {
+	return &hostLimiter{
+		buckets:  make(map[string]*tokenBucket),
+		maxHosts: maxHosts,
+		now:      time.Now,
+	}
+}
+
+// defaultLimiter 为所有出站 feed 请求共享的主机限速器。
+var defaultLimiter = newHostLimiter(limiterMaxHosts)
+
+// reserve 预约一个令牌并返回需要等待的时长；等待超过 maxWait 时撤销预约并返回 false。
+func (l *hostLimiter) reserve(host string, rps float64, maxWait time.Duration) (time.Duration, bool) {
+	burst := math.Max(1, rps)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[host]
+	if !ok {
+		l.evict(now, rps, burst)
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[host] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rps)
+	bucket.last = now
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0, true
+	}
+	wait := time.Duration(-bucket.tokens / rps * float64(time.Second))
+	if wait > maxWait {
+		bucket.tokens++
+		return wait, false
+	}
+	return wait, true
+}
+
+// cancel 归还一个已预约但未使用的令牌。
+func (l *hostLimiter) cancel(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[host]; ok {
+		bucket.tokens++
+	}
+}
+
+// evict 在桶数量达到上限时清理已回满的空闲桶，仍超限则淘汰最久未使用的桶。
+func (l *hostLimiter) evict(now time.Time, rps, burst float64) {
+	if len(l.buckets) < l.maxHosts {
+		return
+	}
+	for host, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rps >= burst {
+			delete(l.buckets, host)
+		}
+	}
+	for len(l.buckets) >= l.maxHosts {
+		oldest := ""
+		var oldestAt time.Time
+		for host, bucket := range l.buckets {
+			if oldest == "" || bucket.last.Before(oldestAt) {
+				oldest, oldestAt = host, bucket.last
+			}
+		}
+		delete(l.buckets, oldest)
+	}
+}
+
+// wait 等待主机令牌，不会等待超过 maxWait 或 ctx 的截止时间。
+func (l *hostLimiter) wait(ctx context.Context, host string, rps float64, maxWait time.Duration) error {
+	if rps <= 0 || host == "" {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < maxWait {
+			maxWait = remaining
+		}
+	}
+	delay, ok := l.reserve(host, rps, maxWait)
+	if !ok {
+		return newRateLimitedErr(fmt.Errorf("对 %s 的请求过于频繁，请稍后重试", host), delay)
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(host)
+		return ctx.Err()
+	}
+}
+
+func upstreamRPS() float64 {
+	raw := strings.TrimSpace(os.Getenv(upstreamRPSEnv))
+	if raw == "" {
+		return defaultUpstreamRPS
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	if err != nil || val < 0 {
+		return defaultUpstreamRPS
+	}
+	return val
+}
+
+func upstreamMaxWait() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(upstreamMaxWaitEnv))
+	if raw == "" {
+		return defaultMaxWait
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil || val < 0 {
+		return defaultMaxWait
+	}
+	return val
+}
@@ -0,0 +1,104 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterBurstSingleHost(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newHostLimiter(16)
+	l.now = func() time.Time { return now }
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if _, ok := l.reserve("feeds.example.com", 2, 0); ok {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Fatalf("expected burst of 2, got %d", allowed)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if _, ok := l.reserve("feeds.example.com", 2, 0); !ok {
+		t.Fatal("expected token refilled after 500ms")
+	}
+}
+
+func TestHostLimiterQueuesWithinMaxWait(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newHostLimiter(16)
+	l.now = func() time.Time { return now }
+
+	l.reserve("a.example.com", 1, 0)
+	wait, ok := l.reserve("a.example.com", 1, 2*time.Second)
+	if !ok || wait != time.Second {
+		t.Fatalf("expected queued for 1s, got %v ok=%v", wait, ok)
+	}
+	wait, ok = l.reserve("a.example.com", 1, 1500*time.Millisecond)
+	if ok {
+		t.Fatalf("expected rejection when wait %v exceeds max", wait)
+	}
+}
+
+func TestHostLimiterSpreadAcrossHosts(t *testing.T) {
+	l := newHostLimiter(16)
+	for i := 0; i < 10; i++ {
+		if _, ok := l.reserve(fmt.Sprintf("host%d.example.com", i), 1, 0); !ok {
+			t.Fatalf("host %d should not be limited", i)
+		}
+	}
+}
+
+func TestHostLimiterBoundedHosts(t *testing.T) {
+	l := newHostLimiter(4)
+	for i := 0; i < 100; i++ {
+		l.reserve(fmt.Sprintf("host%d.example.com", i), 1, 0)
+	}
+	if len(l.buckets) > 4 {
+		t.Fatalf("expected at most 4 buckets, got %d", len(l.buckets))
+	}
+}
+
+func TestHostLimiterRespectsDeadline(t *testing.T) {
+	l := newHostLimiter(16)
+	l.reserve("slow.example.com", 1, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := l.wait(ctx, "slow.example.com", 1, time.Minute)
+	if !IsRateLimited(err) {
+		t.Fatalf("expected rate limited error, got %v", err)
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Fatal("should fail fast instead of waiting past the deadline")
+	}
+	if RetryAfter(err) <= 0 {
+		t.Fatal("expected retry-after hint")
+	}
+}
+
+func TestConvertRateLimitedBurst(t *testing.T) {
+	t.Setenv(upstreamRPSEnv, "1")
+	t.Setenv(upstreamMaxWaitEnv, "0s")
+	prev := defaultLimiter
+	defaultLimiter = newHostLimiter(limiterMaxHosts)
+	defer func() { defaultLimiter = prev }()
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	if _, err := Convert(context.Background(), "https://busy.example.com/rss"); err != nil {
+		t.Fatalf("first request should pass: %v", err)
+	}
+	if _, err := Convert(context.Background(), "https://busy.example.com/rss"); !IsRateLimited(err) {
+		t.Fatalf("expected rate limited error, got %v", err)
+	}
+	if _, err := Convert(context.Background(), "https://other.example.com/rss"); err != nil {
+		t.Fatalf("other host should pass: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package rss
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// markdownEscaper 转义文本中会被解释为 Markdown 标记的字符。文本节点中的实体已被解码，
+// <、>、& 必须转义，否则 &lt;script&gt; 会在渲染 Markdown 时成为真实的 HTML。
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "&", `\&`)
+
+// markdownURLEscaper 转义链接地址中会截断 Markdown 链接语法或构成 HTML 的字符。
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownBlocks 为转换时需独立成段的块级元素。
+var markdownBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Main: true, atom.Aside: true, atom.Nav: true, atom.Figure: true, atom.Figcaption: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Blockquote: true, atom.Pre: true, atom.Hr: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true, atom.Tr: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true,
+}
+
+// applyContentFormat 按 content_format 转换条目的 content 与 description，需在生成摘要之后调用，
+// 使 summary 仍为纯文本。
+func applyContentFormat(items []*model.ItemMeta, format string) {
+	if format != model.ContentFormatMarkdown {
+		return
+	}
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		meta.Description = htmlToMarkdown(meta.Description)
+		meta.Content = htmlToMarkdown(meta.Content)
+	}
+}
+
+// htmlToMarkdown 将 HTML 片段转换为 Markdown，支持标题、段落、链接、图片、列表、引用、
+// 粗体/斜体/删除线与代码；脚本类元素整体丢弃，其余未知元素只保留文本。
+// 不含标记或解析失败时原样返回。
+func htmlToMarkdown(raw string) string {
+	if strings.TrimSpace(raw) == "" || !strings.Contains(raw, "<") {
+		return raw
+	}
+	parent := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), parent)
+	if err != nil {
+		return raw
+	}
+	for _, node := range nodes {
+		parent.AppendChild(node)
+	}
+	return strings.Join(markdownChildBlocks(parent), "\n\n")
+}
+
+// markdownChildBlocks 将子节点转换为若干段落，相邻的行内内容合并为一段。
+func markdownChildBlocks(parent *html.Node) []string {
+	var out []string
+	var run strings.Builder
+	flush := func() {
+		if text := cleanMarkdownInline(run.String()); text != "" {
+			out = append(out, text)
+		}
+		run.Reset()
+	}
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && unsafeElements[child.DataAtom] {
+			continue
+		}
+		if child.Type == html.ElementNode && markdownBlocks[child.DataAtom] {
+			flush()
+			out = append(out, markdownBlock(child)...)
+			continue
+		}
+		run.WriteString(markdownInline(child))
+	}
+	flush()
+	return out
+}
+
+// markdownBlock 转换单个块级元素。
+func markdownBlock(node *html.Node) []string {
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(cleanMarkdownInline(markdownInlineChildren(node)), "  \n", " ")
+		if text == "" {
+			return nil
+		}
+		level := int(node.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+	case atom.Ul, atom.Ol:
+		if list := markdownList(node); list != "" {
+			return []string{list}
+		}
+		return nil
+	case atom.Blockquote:
+		inner := strings.Join(markdownChildBlocks(node), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+	case atom.Pre:
+		code := strings.TrimRight(nodeText(node), "\n")
+		if strings.TrimSpace(code) == "" {
+			return nil
+		}
+		fence := "```"
+		if strings.Contains(code, fence) {
+			fence = "~~~"
+		}
+		return []string{fence + "\n" + code + "\n" + fence}
+	case atom.Hr:
+		return []string{"---"}
+	default:
+		return markdownChildBlocks(node)
+	}
+}
+
+// markdownList 转换 ul/ol，嵌套列表与多段内容按标记宽度缩进。
+func markdownList(node *html.Node) string {
+	ordered := node.DataAtom == atom.Ol
+	n := 1
+	if start, err := strconv.Atoi(getAttr(node, "start")); ordered && err == nil {
+		n = start
+	}
+	var items []string
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || unsafeElements[child.DataAtom] {
+			continue
+		}
+		content := strings.Join(markdownChildBlocks(child), "\n")
+		if content == "" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(n) + ". "
+			n++
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// markdownInline 转换行内节点，br 以换行表示，由 cleanMarkdownInline 统一处理。
+func markdownInline(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode:
+		return markdownEscaper.Replace(collapseHTMLSpace(node.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if unsafeElements[node.DataAtom] {
+		return ""
+	}
+	switch node.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.A:
+		text := strings.TrimSpace(markdownInlineChildren(node))
+		href := strings.TrimSpace(getAttr(node, "href"))
+		if href == "" || isScriptURL(href) {
+			return text
+		}
+		if text == "" {
+			text = markdownEscaper.Replace(href)
+		}
+		return "[" + text + "](" + markdownURLEscaper.Replace(href) + ")"
+	case atom.Img:
+		src := strings.TrimSpace(getAttr(node, "src"))
+		if src == "" || isScriptURL(src) {
+			return ""
+		}
+		return "![" + markdownEscaper.Replace(collapseHTMLSpace(getAttr(node, "alt"))) + "](" + markdownURLEscaper.Replace(src) + ")"
+	case atom.Strong, atom.B:
+		return wrapMarkdown(markdownInlineChildren(node), "**")
+	case atom.Em, atom.I:
+		return wrapMarkdown(markdownInlineChildren(node), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapMarkdown(markdownInlineChildren(node), "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		code := collapseHTMLSpace(nodeText(node))
+		if strings.TrimSpace(code) == "" {
+			return code
+		}
+		if strings.Contains(code, "`") {
+			return "`` " + code + " ``"
+		}
+		return "`" + code + "`"
+	case atom.Td, atom.Th, atom.Dt, atom.Dd:
+		return " " + markdownInlineChildren(node) + " "
+	}
+	if markdownBlocks[node.DataAtom] {
+		return "\n" + markdownInlineChildren(node) + "\n"
+	}
+	return markdownInlineChildren(node)
+}
+
+func markdownInlineChildren(node *html.Node) string {
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(markdownInline(child))
+	}
+	return b.String()
+}
+
+// wrapMarkdown 用强调标记包裹文本，首尾空白留在标记之外，否则 Markdown 不识别。
+func wrapMarkdown(text, mark string) string {
+	inner := strings.TrimSpace(text)
+	if inner == "" {
+		return text
+	}
+	lead, trail := "", ""
+	if strings.HasPrefix(text, " ") {
+		lead = " "
+	}
+	if strings.HasSuffix(text, " ") {
+		trail = " "
+	}
+	return lead + mark + inner + mark + trail
+}
+
+// cleanMarkdownInline 合并行内内容的空白，br 产生的换行输出为 Markdown 硬换行，空行丢弃；
+// 行首会被解释为标题、列表等块级标记的文本加以转义。
+func cleanMarkdownInline(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, escapeMarkdownLineStart(line))
+		}
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// escapeMarkdownLineStart 转义行首的 #、-、+、= 以及 1. / 1) 形式的有序列表标记。
+// > 与 * 已由 markdownEscaper 在任意位置转义。
+func escapeMarkdownLineStart(line string) string {
+	switch line[0] {
+	case '#', '-', '+', '=':
+		return `\` + line
+	}
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return line
+}
+
+// nodeText 返回节点内的原始文本，保留空白，用于 pre 与 code。
+func nodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Br {
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(nodeText(child))
+	}
+	return b.String()
+}
+
+func getAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
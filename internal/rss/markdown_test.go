@@ -0,0 +1,68 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	cases := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"link", `<p>Read <a href="https://example.com/post?a=1">the post</a> now</p>`, "Read [the post](https://example.com/post?a=1) now"},
+		{"heading", `<h2>Release <em>notes</em></h2><p>Body</p>`, "## Release *notes*\n\nBody"},
+		{"emphasis", `<p><strong>bold</strong> and <i>italic</i> and <b> spaced </b>text</p>`, "**bold** and *italic* and **spaced** text"},
+		{"image", `<img src="https://example.com/a b.png" alt="A [chart]">`, `![A \[chart\]](https://example.com/a%20b.png)`},
+		{"unordered list", `<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>`, "- one\n- two\n  - nested"},
+		{"ordered list", `<ol start="3"><li>three</li><li>four</li></ol>`, "3. three\n4. four"},
+		{"blockquote", `<blockquote><p>quoted</p><p>twice</p></blockquote>`, "> quoted\n>\n> twice"},
+		{"pre", "<pre><code>if a {\n  b()\n}</code></pre>", "```\nif a {\n  b()\n}\n```"},
+		{"inline code and br", `line <code>x_y</code><br>next`, "line `x_y`  \nnext"},
+		{"escape and script", `<p>a*b_c</p><script>alert(1)</script><a href="javascript:alert(1)">x</a>`, `a\*b\_c` + "\n\nx"},
+		{"plain text", "no markup *here*", "no markup *here*"},
+		{"escaped html", `<p>Use &lt;script&gt;alert(1)&lt;/script&gt; &lt;img src=x onerror=alert(1)&gt; &amp;amp;</p>`, `Use \<script\>alert(1)\</script\> \<img src=x onerror=alert(1)\> \&amp;`},
+		{"line-leading markers", `<p># not a heading</p><p>- not a list<br>+ nor this<br>2024. not ordered</p>`, `\# not a heading` + "\n\n" + `\- not a list` + "  \n" + `\+ nor this` + "  \n" + `2024\. not ordered`},
+		{"heading text", `<h1>#1 pick</h1>`, `# \#1 pick`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := htmlToMarkdown(tc.html); got != tc.want {
+				t.Fatalf("htmlToMarkdown(%q) = %q, want %q", tc.html, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertContentFormatMarkdown(t *testing.T) {
+	const feed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Markdown</title>
+    <item>
+      <title>Post</title>
+      <link>https://example.com/1</link>
+      <description><![CDATA[<p>See <a href="https://example.com/1">this</a></p>]]></description>
+      <content:encoded><![CDATA[<h1>Title</h1><p>Full text</p>]]></content:encoded>
+    </item>
+  </channel>
+</rss>`
+	restore := WithHTTPClient(fakeDoer{body: feed, status: http.StatusOK})
+	defer restore()
+
+	resp, err := ConvertWithOptions(context.Background(), "https://example.com/md.xml", Options{ContentFormat: model.ContentFormatMarkdown})
+	if err != nil {
+		t.Fatalf("convert error: %v", err)
+	}
+	item := resp.Items[0]
+	if item.Description != "See [this](https://example.com/1)" || item.Content != "# Title\n\nFull text" {
+		t.Fatalf("unexpected markdown body %q / %q", item.Description, item.Content)
+	}
+	if item.Summary != "See this" {
+		t.Fatalf("expected plain text summary, got %q", item.Summary)
+	}
+}
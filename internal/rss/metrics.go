@@ -0,0 +1,27 @@
+package rss
+
+import "expvar"
+
+// 通过 /metrics 与 /debug/vars 暴露的上游内容缓存命中计数。
+var (
+	cacheHitsTotal   = expvar.NewInt("rss_cache_hits_total")
+	cacheMissesTotal = expvar.NewInt("rss_cache_misses_total")
+)
+
+// CacheStats 返回进程启动以来上游内容缓存的命中与未命中次数，不可缓存的请求不计入。
+func CacheStats() (hits, misses int64) {
+	return cacheHitsTotal.Value(), cacheMissesTotal.Value()
+}
+
+// 通过 expvar 暴露的运行时计数，开启 DEBUG_ENDPOINTS 后可在 /debug/vars 查看。
+var (
+	conversionsInFlight = expvar.NewInt("rss_conversions_in_flight")
+	conversionsTotal    = expvar.NewInt("rss_conversions_total")
+	upstreamBytesTotal  = expvar.NewInt("rss_upstream_bytes_total")
+)
+
+// 通过 /metrics 与 /debug/vars 暴露的出站连接计数，用于评估连接池大小是否合适。
+var (
+	upstreamConnsNewTotal    = expvar.NewInt("rss_upstream_conns_new_total")
+	upstreamConnsReusedTotal = expvar.NewInt("rss_upstream_conns_reused_total")
+)
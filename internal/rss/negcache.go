@@ -0,0 +1,156 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	negativeCacheTTLEnv     = "NEGATIVE_CACHE_TTL"
+	defaultNegativeCacheTTL = 30 * time.Second
+	// negativeCacheMaxEntries 限制失败记录数量，避免大量不同的坏链接占满内存。
+	negativeCacheMaxEntries = 4096
+	// permanentFailureFactor 为解析失败与上游 4xx 相对超时等暂时性故障的缓存倍数，
+	// 这类错误短时间内重试几乎不会成功。
+	permanentFailureFactor = 4
+)
+
+// errParseFeed 标记 feed 内容无法解析的错误。
+var errParseFeed = errors.New("解析 RSS 失败")
+
+type negativeEntry struct {
+	err     error
+	expires time.Time
+}
+
+// negativeCache 按 feed URL 记录最近的上游失败，窗口内的重复请求直接返回同样的错误，
+// 不再发起出站请求。
+type negativeCache struct {
+	mu      sync.Mutex
+	entries map[string]negativeEntry
+	now     func() time.Time
+}
+
+var failures = newNegativeCache()
+
+func newNegativeCache() *negativeCache {
+	return &negativeCache{entries: make(map[string]negativeEntry), now: time.Now}
+}
+
+// get 返回未过期的失败记录，包装为带 RetryAfter 的错误。
+func (c *negativeCache) get(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	remaining := entry.expires.Sub(c.now())
+	if remaining <= 0 {
+		delete(c.entries, key)
+		return nil
+	}
+	cached := FeedError{Kind: ErrorKindUpstream}
+	var feedErr *FeedError
+	if errors.As(entry.err, &feedErr) {
+		cached = *feedErr
+	}
+	cached.Err = fmt.Errorf("%w (cached failure, retry in %s)", entry.err, remaining.Round(time.Second))
+	cached.RetryAfter = remaining
+	cached.Cached = true
+	return &cached
+}
+
+func (c *negativeCache) set(key string, err error, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= negativeCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= negativeCacheMaxEntries {
+			return
+		}
+	}
+	c.entries[key] = negativeEntry{err: err, expires: now.Add(ttl)}
+}
+
+// forget 删除单个失败记录，用于后台刷新成功后让用户请求不再返回缓存的失败。
+func (c *negativeCache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *negativeCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]negativeEntry)
+}
+
+// negativeCacheTTL 读取 NEGATIVE_CACHE_TTL（如 30s），默认 30 秒，0 表示关闭。
+func negativeCacheTTL() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(negativeCacheTTLEnv))
+	if raw == "" {
+		return defaultNegativeCacheTTL
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil || val < 0 {
+		return defaultNegativeCacheTTL
+	}
+	return val
+}
+
+// negativeKeyFor 判断本次请求的失败能否记录。带凭据、自定义请求头或单独调整了
+// 抓取行为（insecure、max_bytes）的请求结果因请求而异，不参与失败缓存；data: URI 不经过网络，同样不参与。
+func negativeKeyFor(rawURL string, opts Options) (string, bool) {
+	if negativeCacheTTL() <= 0 {
+		return "", false
+	}
+	if opts.Username != "" || opts.Password != "" || opts.UserAgent != "" || len(opts.Headers) > 0 || opts.Insecure || opts.MaxBytes > 0 || isDataURL(rawURL) {
+		return "", false
+	}
+	key := CacheKey(rawURL)
+	return key, key != ""
+}
+
+// negativeTTLFor 返回错误应缓存的时长，0 表示不缓存。输入错误、本地限速、
+// robots 禁止与客户端取消都不属于上游故障。
+func negativeTTLFor(err error) time.Duration {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return 0
+	}
+	var feedErr *FeedError
+	if errors.As(err, &feedErr) {
+		switch feedErr.Kind {
+		case ErrorKindInvalidInput, ErrorKindRateLimited, ErrorKindBlocked:
+			return 0
+		}
+		if feedErr.Cached {
+			return 0
+		}
+	}
+	// 上游限流时按其 Retry-After 缓存，窗口内不再打扰上游。
+	if feedErr != nil && feedErr.RetryAfter > 0 {
+		return feedErr.RetryAfter
+	}
+	ttl := negativeCacheTTL()
+	if errors.Is(err, errParseFeed) || (feedErr != nil && feedErr.StatusCode >= 400 && feedErr.StatusCode < 500) {
+		return ttl * permanentFailureFactor
+	}
+	return ttl
+}
+
+// IsCachedFailure 判断错误是否来自失败缓存而非本次出站请求。
+func IsCachedFailure(err error) bool {
+	var feedErr *FeedError
+	return errors.As(err, &feedErr) && feedErr.Cached
+}
@@ -0,0 +1,181 @@
+package rss
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingDoer struct {
+	calls  atomic.Int32
+	body   string
+	status int
+	header http.Header
+}
+
+func (c *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	return &http.Response{StatusCode: c.status, Header: c.header, Body: io.NopCloser(strings.NewReader(c.body))}, nil
+}
+
+func TestNegativeCacheSingleUpstreamAttempt(t *testing.T) {
+	doer := &countingDoer{status: http.StatusInternalServerError}
+	restore := WithHTTPClient(doer)
+	defer restore()
+
+	_, first := Convert(context.Background(), "https://dead.example.com/rss")
+	if first == nil || IsCachedFailure(first) {
+		t.Fatalf("expected fresh upstream error, got %v", first)
+	}
+	_, second := Convert(context.Background(), "https://dead.example.com/rss")
+	if !IsCachedFailure(second) {
+		t.Fatalf("expected cached failure, got %v", second)
+	}
+	if got := doer.calls.Load(); got != 1 {
+		t.Fatalf("expected a single upstream attempt, got %d", got)
+	}
+	if retry := RetryAfter(second); retry <= 0 || retry > defaultNegativeCacheTTL {
+		t.Fatalf("expected remaining negative cache time, got %v", retry)
+	}
+	if !strings.Contains(second.Error(), "cached failure") {
+		t.Fatalf("expected remaining time in message, got %q", second.Error())
+	}
+}
+
+func TestNegativeCacheExpires(t *testing.T) {
+	doer := &countingDoer{status: http.StatusServiceUnavailable}
+	restore := WithHTTPClient(doer)
+	defer restore()
+	now := time.Now()
+	failures.now = func() time.Time { return now }
+	defer func() { failures.now = time.Now }()
+
+	Convert(context.Background(), "https://flaky.example.com/rss")
+	now = now.Add(defaultNegativeCacheTTL)
+	Convert(context.Background(), "https://flaky.example.com/rss")
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected retry after the window, got %d attempts", got)
+	}
+}
+
+func TestRefreshBypassesAndClearsNegativeCache(t *testing.T) {
+	doer := &countingDoer{status: http.StatusServiceUnavailable}
+	restore := WithHTTPClient(doer)
+	defer restore()
+
+	Convert(context.Background(), "https://recovering.example.com/rss")
+	doer.status, doer.body = http.StatusOK, sampleRSS
+	if _, err := ConvertWithOptions(context.Background(), "https://recovering.example.com/rss", Options{Refresh: true}); err != nil {
+		t.Fatalf("expected refresh to reach upstream, got %v", err)
+	}
+	if _, err := Convert(context.Background(), "https://recovering.example.com/rss"); err != nil {
+		t.Fatalf("expected cached failure cleared after refresh, got %v", err)
+	}
+	if got := doer.calls.Load(); got != 3 {
+		t.Fatalf("expected 3 upstream attempts, got %d", got)
+	}
+}
+
+func TestNegativeCacheLongerForParseErrorsAnd4xx(t *testing.T) {
+	cases := []struct {
+		name   string
+		doer   *countingDoer
+		factor time.Duration
+	}{
+		{"parse error", &countingDoer{status: http.StatusOK, body: "not xml"}, permanentFailureFactor},
+		{"not found", &countingDoer{status: http.StatusNotFound}, permanentFailureFactor},
+		{"server error", &countingDoer{status: http.StatusBadGateway}, 1},
+	}
+	for _, tc := range cases {
+		restore := WithHTTPClient(tc.doer)
+		Convert(context.Background(), "https://broken.example.com/rss")
+		_, err := Convert(context.Background(), "https://broken.example.com/rss")
+		restore()
+		want := defaultNegativeCacheTTL * tc.factor
+		if retry := RetryAfter(err); retry <= want-time.Second || retry > want {
+			t.Fatalf("%s: expected about %v, got %v", tc.name, want, retry)
+		}
+	}
+}
+
+func TestNegativeCacheSkipsInvalidInputAndDisabled(t *testing.T) {
+	if ttl := negativeTTLFor(newInvalidInputErr(CodeInvalidURL, context.Canceled)); ttl != 0 {
+		t.Fatalf("invalid input must never be cached, got %v", ttl)
+	}
+
+	t.Setenv(negativeCacheTTLEnv, "0")
+	doer := &countingDoer{status: http.StatusInternalServerError}
+	restore := WithHTTPClient(doer)
+	defer restore()
+	Convert(context.Background(), "https://dead.example.com/rss")
+	Convert(context.Background(), "https://dead.example.com/rss")
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected negative cache disabled, got %d attempts", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		" 0 ":                           0,
+		"-5":                            0,
+		"soon":                          0,
+		"Thu, 01 Jan 2026 12:01:30 GMT": 90 * time.Second,
+		"Thu, 01 Jan 2026 11:59:00 GMT": 0,
+		"86400":                         maxUpstreamRetryAfter,
+	}
+	for raw, want := range cases {
+		if got := parseRetryAfter(raw, now); got != want {
+			t.Fatalf("parseRetryAfter(%q) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestUpstreamRateLimitUsesRetryAfter(t *testing.T) {
+	doer := &countingDoer{status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"300"}}}
+	restore := WithHTTPClient(doer)
+	defer restore()
+	now := time.Now()
+	failures.now = func() time.Time { return now }
+	defer func() { failures.now = time.Now }()
+
+	_, err := Convert(context.Background(), "https://busy.example.com/rss")
+	if ErrorCodeOf(err) != CodeUpstreamRateLimited || RetryAfter(err) != 5*time.Minute || UpstreamStatus(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected upstream_rate_limited with 5m Retry-After, got %s %s: %v", ErrorCodeOf(err), RetryAfter(err), err)
+	}
+
+	// 失败缓存按上游 Retry-After 而非 NEGATIVE_CACHE_TTL 计时。
+	now = now.Add(2 * time.Minute)
+	_, err = Convert(context.Background(), "https://busy.example.com/rss")
+	if !IsCachedFailure(err) || ErrorCodeOf(err) != CodeUpstreamRateLimited || RetryAfter(err) != 3*time.Minute {
+		t.Fatalf("expected cached rate limit with 3m remaining, got %s %s: %v", ErrorCodeOf(err), RetryAfter(err), err)
+	}
+	now = now.Add(3 * time.Minute)
+	Convert(context.Background(), "https://busy.example.com/rss")
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected upstream retried after Retry-After, got %d attempts", got)
+	}
+}
+
+func TestUpstreamUnavailableWithoutRetryAfter(t *testing.T) {
+	doer := &countingDoer{status: http.StatusServiceUnavailable}
+	restore := WithHTTPClient(doer)
+	defer restore()
+
+	_, err := Convert(context.Background(), "https://down.example.com/rss")
+	if ErrorCodeOf(err) != CodeUpstreamStatus || RetryAfter(err) != 0 {
+		t.Fatalf("expected plain upstream_status without Retry-After, got %s %s", ErrorCodeOf(err), RetryAfter(err))
+	}
+
+	doer.header = http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}
+	_, err = Convert(context.Background(), "https://maintenance.example.com/rss")
+	if ErrorCodeOf(err) != CodeUpstreamRateLimited || RetryAfter(err) <= 50*time.Second || RetryAfter(err) > time.Minute {
+		t.Fatalf("expected HTTP-date Retry-After honoured on 503, got %s %s", ErrorCodeOf(err), RetryAfter(err))
+	}
+}
@@ -3,46 +3,108 @@ package rss
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/mmcdole/gofeed"
-	"github.com/zdev0x/rss2json/internal/model"
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/net/http2"
 )
 
 // httpClientTimeout 定义 RSS 拉取超时时间。
 const (
-	httpClientTimeout   = 10 * time.Second
-	dialTimeout         = 5 * time.Second
-	tlsHandshakeTimeout = 5 * time.Second
-	responseHeaderTime  = 5 * time.Second
-	idleConnTimeout     = 30 * time.Second
-	maxIdleConns        = 100
-	maxIdleConnsPerHost = 10
-	defaultMaxFeedBytes = int64(10 << 20) // 10 MiB
+	httpClientTimeout          = 10 * time.Second
+	dialTimeout                = 5 * time.Second
+	tlsHandshakeTimeout        = 5 * time.Second
+	responseHeaderTime         = 5 * time.Second
+	idleConnTimeout            = 30 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultMaxFeedBytes        = int64(10 << 20) // 10 MiB
 )
 
 const maxFeedBytesEnv = "RSS_MAX_BYTES"
 
+// http2Env 为 off 时出站请求仅使用 HTTP/1.1，用于 h2 实现有问题（如频繁 RST_STREAM）的源站；
+// forceHTTP1Env 为早期的同义配置，设为 1 时效果相同。
+const (
+	http2Env      = "RSS_HTTP2"
+	forceHTTP1Env = "RSS_FORCE_HTTP1"
+)
+
+// allowDowngradeEnv 为 1 时允许上游把 https 请求跳转到 http。
+const allowDowngradeEnv = "RSS_ALLOW_DOWNGRADE"
+
+// maxUpstreamRetryAfter 为采纳上游 Retry-After 的上限。
+const maxUpstreamRetryAfter = time.Hour
+
+// defaultAccept 为抓取 feed 时默认发送的 Accept，促使按内容协商的站点（如部分 WordPress 端点）返回 XML，
+// 可被 RSS_HEADERS 或单次请求的请求头覆盖。
+const defaultAccept = "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8"
+
 type ErrorKind int
 
 const (
 	ErrorKindInvalidInput ErrorKind = iota + 1
 	ErrorKindUpstream
+	ErrorKindBlocked
+	ErrorKindRateLimited
+	// ErrorKindParse 表示内容已成功下载，但不是可解析的 RSS/Atom/JSON Feed。
+	ErrorKindParse
+)
+
+// ErrorCode 为返回给客户端的稳定错误码，客户端应据此区分失败原因而非匹配错误文本。
+type ErrorCode string
+
+const (
+	CodeMissingURL       ErrorCode = "missing_url"
+	CodeInvalidURL       ErrorCode = "invalid_url"
+	CodeFetchTimeout     ErrorCode = "fetch_timeout"
+	CodeFetchFailed      ErrorCode = "fetch_failed"
+	CodeUpstreamStatus   ErrorCode = "upstream_status"
+	CodeParseFailed      ErrorCode = "parse_failed"
+	CodeTooLarge         ErrorCode = "too_large"
+	CodeRobotsDisallowed ErrorCode = "robots_disallowed"
+	CodeRateLimited      ErrorCode = "rate_limited"
+	CodeProxyFailed      ErrorCode = "proxy_failed"
+	CodeMalformedURL     ErrorCode = "malformed_url"
+	CodeHTMLPage         ErrorCode = "html_page"
+	CodeDNSError         ErrorCode = "dns_error"
+	// CodeUpstreamRateLimited 为上游以 429（或带 Retry-After 的 503）限流，RetryAfter 为上游要求的等待时长。
+	CodeUpstreamRateLimited ErrorCode = "upstream_rate_limited"
 )
 
 type FeedError struct {
 	Kind ErrorKind
+	// Code 为错误码，为空时按 Kind 推断。
+	Code ErrorCode
 	Err  error
+	// RetryAfter 为建议客户端重试前等待的时长，0 表示未知。
+	RetryAfter time.Duration
+	// StatusCode 为上游返回的非 2xx 状态码，其他错误为 0。
+	StatusCode int
+	// Cached 表示错误来自失败缓存，本次没有请求上游。
+	Cached bool
+	// Snippet 为解析失败或返回 HTML 页面时响应体开头的片段（已移除控制字符），便于排查。
+	Snippet string
 }
 
 func (e *FeedError) Error() string {
@@ -53,12 +115,119 @@ func (e *FeedError) Unwrap() error {
 	return e.Err
 }
 
-func newInvalidInputErr(err error) error {
-	return &FeedError{Kind: ErrorKindInvalidInput, Err: err}
+func newInvalidInputErr(code ErrorCode, err error) error {
+	return &FeedError{Kind: ErrorKindInvalidInput, Code: code, Err: err}
+}
+
+func newUpstreamErr(code ErrorCode, err error) error {
+	return &FeedError{Kind: ErrorKindUpstream, Code: code, Err: err}
+}
+
+func newParseErr(err error, body []byte) error {
+	return &FeedError{Kind: ErrorKindParse, Code: CodeParseFailed, Err: fmt.Errorf("%w: %w", errParseFeed, err), Snippet: bodySnippet(body)}
+}
+
+func newBlockedErr(err error) error {
+	return &FeedError{Kind: ErrorKindBlocked, Code: CodeRobotsDisallowed, Err: err}
+}
+
+func newRateLimitedErr(err error, retryAfter time.Duration) error {
+	return &FeedError{Kind: ErrorKindRateLimited, Code: CodeRateLimited, Err: err, RetryAfter: retryAfter}
 }
 
-func newUpstreamErr(err error) error {
-	return &FeedError{Kind: ErrorKindUpstream, Err: err}
+// ErrorCodeOf 返回错误对应的错误码。下载失败中的超时单独归为 fetch_timeout，
+// 非 FeedError 的错误按下载失败处理。
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var feedErr *FeedError
+	if !errors.As(err, &feedErr) {
+		if isTimeout(err) {
+			return CodeFetchTimeout
+		}
+		return CodeFetchFailed
+	}
+	if (feedErr.Code == "" || feedErr.Code == CodeFetchFailed) && isTimeout(err) {
+		return CodeFetchTimeout
+	}
+	if feedErr.Code != "" {
+		return feedErr.Code
+	}
+	switch feedErr.Kind {
+	case ErrorKindInvalidInput:
+		return CodeInvalidURL
+	case ErrorKindBlocked:
+		return CodeRobotsDisallowed
+	case ErrorKindRateLimited:
+		return CodeRateLimited
+	case ErrorKindParse:
+		return CodeParseFailed
+	}
+	return CodeFetchFailed
+}
+
+// upstreamStatusErr 构造上游非 2xx 状态码的错误。429 以及带 Retry-After 的 503 视为上游限流，
+// 记录上游要求的等待时长，供响应头转发与失败缓存使用。
+func upstreamStatusErr(resp *http.Response, now time.Time) error {
+	feedErr := &FeedError{Kind: ErrorKindUpstream, Code: CodeUpstreamStatus, Err: fmt.Errorf("RSS 返回非 2xx 状态码: %d", resp.StatusCode), StatusCode: resp.StatusCode}
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusServiceUnavailable && retryAfter > 0) {
+		feedErr.Code = CodeUpstreamRateLimited
+		feedErr.RetryAfter = retryAfter
+	}
+	return feedErr
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP-date），缺失、不合法或已过期时返回 0，
+// 超过 maxUpstreamRetryAfter 时截断，避免异常值让 feed 长时间不可用。
+func parseRetryAfter(raw string, now time.Time) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	var wait time.Duration
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		wait = time.Duration(min(secs, int64(maxUpstreamRetryAfter/time.Second))) * time.Second
+	} else if at, err := http.ParseTime(raw); err == nil {
+		wait = at.Sub(now)
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return min(wait, maxUpstreamRetryAfter)
+}
+
+// IsParseError 判断错误是否为内容已下载但无法解析。
+func IsParseError(err error) bool {
+	var feedErr *FeedError
+	return errors.As(err, &feedErr) && feedErr.Kind == ErrorKindParse
+}
+
+// ErrorSnippet 返回解析失败时响应体开头的片段，其他错误返回空串。
+func ErrorSnippet(err error) string {
+	var feedErr *FeedError
+	if errors.As(err, &feedErr) {
+		return feedErr.Snippet
+	}
+	return ""
+}
+
+// UpstreamStatus 返回上游响应的非 2xx 状态码，其他错误返回 0。
+func UpstreamStatus(err error) int {
+	var feedErr *FeedError
+	if errors.As(err, &feedErr) {
+		return feedErr.StatusCode
+	}
+	return 0
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
 func IsInvalidInput(err error) bool {
@@ -66,93 +235,510 @@ func IsInvalidInput(err error) bool {
 	return errors.As(err, &feedErr) && feedErr.Kind == ErrorKindInvalidInput
 }
 
+// IsRateLimited 判断错误是否因出站限速被拒绝。
+func IsRateLimited(err error) bool {
+	var feedErr *FeedError
+	return errors.As(err, &feedErr) && feedErr.Kind == ErrorKindRateLimited
+}
+
+// RetryAfter 返回错误携带的建议重试等待时长。
+func RetryAfter(err error) time.Duration {
+	var feedErr *FeedError
+	if errors.As(err, &feedErr) {
+		return feedErr.RetryAfter
+	}
+	return 0
+}
+
+// IsBlocked 判断错误是否因目标站点 robots.txt 禁止抓取。
+func IsBlocked(err error) bool {
+	var feedErr *FeedError
+	return errors.As(err, &feedErr) && feedErr.Kind == ErrorKindBlocked
+}
+
 type httpDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// defaultHTTPClient 默认使用环境变量配置的 HTTP 客户端，支持 HTTP/HTTPS/SOCKS5 代理。
-var defaultHTTPClient httpDoer = newHTTPClientFromEnv()
+// currentHTTPClient 为出站请求使用的 HTTP 客户端，首次使用时按环境变量构造，支持 HTTP/HTTPS/SOCKS5 代理；
+// ReloadHTTPClientFromEnv 与 SetProxy 重建时原子替换，进行中的请求继续使用旧客户端。
+// 构造与重建由 httpClientMu 串行化，读取无需加锁。
+var (
+	currentHTTPClient atomic.Pointer[httpDoer]
+	httpClientMu      sync.Mutex
+)
+
+// defaultHTTPClient 返回当前的出站 HTTP 客户端，尚未构造时按环境变量构造。
+func defaultHTTPClient() httpDoer {
+	if d := currentHTTPClient.Load(); d != nil {
+		return *d
+	}
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+	if d := currentHTTPClient.Load(); d != nil {
+		return *d
+	}
+	d := newHTTPClientFromEnv()
+	currentHTTPClient.Store(&d)
+	return d
+}
+
+// ReloadHTTPClientFromEnv 按当前环境变量（RSS_PROXY、TLS、连接池等）重建出站客户端并原子替换，
+// 旧客户端的空闲连接随即关闭；insecure=1 使用的客户端在下次使用时重建。
+func ReloadHTTPClientFromEnv() {
+	httpClientMu.Lock()
+	next := newHTTPClientFromEnv()
+	prev := currentHTTPClient.Swap(&next)
+	httpClientMu.Unlock()
+	if prev != nil {
+		closeIdle(*prev)
+	}
 
-// WithHTTPClient 在测试场景中替换默认 HTTP 客户端，返回恢复函数。
+	insecureHTTPClientMu.Lock()
+	closeIdle(insecureHTTPClient)
+	insecureHTTPClient = nil
+	insecureHTTPClientMu.Unlock()
+}
+
+// WithHTTPClient 在测试场景中替换默认 HTTP 客户端，返回恢复函数；此前尚未构造默认客户端时，
+// 恢复后在下次使用时按环境变量构造。
 func WithHTTPClient(d httpDoer) func() {
-	prev := defaultHTTPClient
-	defaultHTTPClient = d
+	prev := currentHTTPClient.Swap(&d)
+	failures.flush()
 	return func() {
-		defaultHTTPClient = prev
+		currentHTTPClient.Store(prev)
+		failures.flush()
+	}
+}
+
+// Options 定义单次转换的可选参数。
+type Options struct {
+	// Username/Password 用于 HTTP Basic 鉴权，优先于 URL 中内嵌的 userinfo。
+	Username string
+	Password string
+	// UserAgent/Headers 为单次请求覆盖的请求头，叠加在 RSS_HEADERS 之上。
+	UserAgent string
+	Headers   http.Header
+	// Count 限制返回的条目数，0 表示不限制。
+	Count int
+	// Sanitize 清理条目 HTML 中的脚本、事件属性与 javascript: 链接。
+	Sanitize bool
+	// StripTracking 移除条目链接中的 utm_* 等跟踪参数。
+	StripTracking bool
+	// MinifyHTML 移除条目 HTML 中的注释并压缩空白，pre/code 内容不变。
+	MinifyHTML bool
+	// ResolveShortlinks 将条目链接中的短链（t.co、bit.ly 等）替换为跳转后的最终地址。
+	ResolveShortlinks bool
+	// OmitContent 输出时省略条目的 content 与 description，仅保留元数据。
+	OmitContent bool
+	// OmitFeed 输出时省略 feed 信息块，适合只关心新条目的轮询客户端。
+	OmitFeed bool
+	// SummaryLen 为条目 summary 的最大字符数，0 表示默认 200。
+	SummaryLen int
+	// DisableDataURLs 拒绝以 data: URI 内联传入的 feed。
+	DisableDataURLs bool
+	// RawDC 输出条目原始的 dc:creator 与 dc:date（dcCreator、dcDate）。
+	RawDC bool
+	// Prefer 为 content 或 description 时，条目额外输出按偏好选取的 body。
+	Prefer string
+	// FieldMap 非空时按映射重命名条目输出字段，用于兼容其他转换服务。
+	FieldMap model.FieldMap
+	// Cursor 非 nil 时只返回该游标之后的新条目，响应 meta.cursor 给出下一次请求使用的游标。
+	Cursor *model.Cursor
+	// ContentFormat 为 markdown 时将条目 content 与 description 由 HTML 转换为 Markdown，空值保持 HTML。
+	ContentFormat string
+
+	// diag 非 nil 时记录上游响应的 Content-Type，且 HTML 页面不作为错误返回，交给 Validate 诊断。
+	diag *fetchDiag
+	// MaxBytes 覆盖 RSS_MAX_BYTES 的单次请求内容上限，0 表示使用全局配置。
+	MaxBytes int64
+	// Insecure 跳过本次请求的上游证书校验。
+	Insecure bool
+	// Cache/CacheTTL 启用上游内容缓存，CacheTTL <= 0 时不缓存。
+	Cache    cache.Store
+	CacheTTL time.Duration
+	// Refresh 为 true 时不读取内容缓存与负缓存而直接抓取上游，结果照常写入缓存，用于后台预热。
+	Refresh bool
+	// Location 为输出 RFC 3339 时间使用的时区，nil 时为 UTC。
+	Location *time.Location
+}
+
+// maxBytes 返回本次请求的内容上限，未覆盖时使用 RSS_MAX_BYTES。
+func (o Options) maxBytes() int64 {
+	if o.MaxBytes > 0 {
+		return o.MaxBytes
+	}
+	return maxFeedBytes()
+}
+
+// EffectiveMaxBytes 返回本次转换实际使用的内容大小上限（MaxBytes 或 RSS_MAX_BYTES）。
+func (o Options) EffectiveMaxBytes() int64 {
+	return o.maxBytes()
+}
+
+// FetchTimeout 返回出站抓取的整体超时，当前客户端不是 *http.Client 时返回 0。
+func FetchTimeout() time.Duration {
+	if c, ok := defaultHTTPClient().(*http.Client); ok {
+		return c.Timeout
+	}
+	return 0
+}
+
+// fetchAndParse 从给定 URL 拉取 Feed 并解析为 gofeed 结构，同时返回原始内容供后续扫描。
+// 完整解析失败时尝试截取到最后一个完整条目再解析，成功则 partial 为 true，返回的 body 为截取后的内容。
+// 上游失败会按 NEGATIVE_CACHE_TTL 记录，窗口内的重复请求直接返回同样的错误。
+// 解析前去除 BOM、开头空白与注释横幅，返回的 body 同样是去除后的内容。
+// maxAge 为上游响应 Cache-Control 声明的 max-age。stats 非 nil 时记录下载的字节数、抓取与解析耗时。
+func fetchAndParse(ctx context.Context, rawURL string, opts Options, stats *fetchStats) (feed *gofeed.Feed, body []byte, partial bool, maxAge time.Duration, err error) {
+	negKey, negCacheable := negativeKeyFor(rawURL, opts)
+	if negCacheable {
+		if cachedErr := failures.get(negKey); cachedErr != nil && !opts.Refresh {
+			return nil, nil, false, 0, cachedErr
+		}
+		defer func() {
+			if ttl := negativeTTLFor(err); ttl > 0 {
+				failures.set(negKey, err, ttl)
+			} else if err == nil && opts.Refresh {
+				failures.forget(negKey)
+			}
+		}()
+	}
+
+	start := time.Now()
+	body, maxAge, err = fetchFeed(ctx, rawURL, opts)
+	if err != nil {
+		return nil, nil, false, 0, err
+	}
+	if stats != nil {
+		stats.bytes = len(body)
+		stats.duration = time.Since(start)
+	}
+	if stats != nil {
+		parseStart := time.Now()
+		defer func() { stats.parse = time.Since(parseStart) }()
+	}
+	body = trimFeedPrologue(body)
+	if err := guardXML(ctx, body); err != nil {
+		return nil, nil, false, 0, err
+	}
+	feed, err = parseFeed(ctx, body)
+	if err == nil {
+		return feed, body, false, maxAge, nil
+	}
+	if ctx.Err() != nil {
+		return nil, nil, false, 0, err
+	}
+	logUpstreamParseFailure(ctx, body, err)
+	if recovered := truncateToLastItem(body); recovered != nil {
+		if partialFeed, perr := parseFeed(ctx, recovered); perr == nil && len(partialFeed.Items) > 0 {
+			return partialFeed, recovered, true, maxAge, nil
+		}
+	}
+	return nil, nil, false, 0, err
+}
+
+// fetchStats 记录一次抓取下载的原始字节数（缓存命中时为缓存内容大小）、抓取耗时与解析耗时
+// （含截断恢复）。
+type fetchStats struct {
+	bytes    int
+	duration time.Duration
+	parse    time.Duration
+}
+
+// ctxReader 在 ctx 结束后让后续读取直接失败，使解析在客户端断开后尽快停止。
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
 	}
+	return c.r.Read(p)
 }
 
-// fetchAndParse 从给定 URL 拉取 Feed 并解析为 gofeed 结构。
-func fetchAndParse(ctx context.Context, url string) (*gofeed.Feed, []string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// parseFeed 将已下载的内容解析为 gofeed 结构。ctx 结束时返回 ctx.Err() 而非解析错误。
+func parseFeed(ctx context.Context, body []byte) (feed *gofeed.Feed, err error) {
+	_, span := tracer().Start(ctx, "parse", trace.WithAttributes(attribute.Int("rss.bytes", len(body))))
+	defer func() { endSpan(span, err) }()
+
+	parser := gofeed.NewParser()
+	feed, err = parser.Parse(&ctxReader{ctx: ctx, r: bytes.NewReader(body)})
 	if err != nil {
-		return nil, nil, newInvalidInputErr(fmt.Errorf("创建请求失败: %w", err))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, newParseErr(err, body)
+	}
+	span.SetAttributes(attribute.String("rss.feed_type", feed.FeedType), attribute.Int("rss.items", len(feed.Items)))
+	return feed, nil
+}
+
+// fetchUpstream 下载 feed 原始内容，负责鉴权、请求头、robots 与大小限制；
+// 同时返回上游 Cache-Control 声明的 max-age，未声明时为 0。
+func fetchUpstream(ctx context.Context, rawURL string, opts Options) ([]byte, time.Duration, error) {
+	target, username, password, err := splitCredentials(rawURL)
+	if err != nil {
+		return nil, 0, newInvalidInputErr(CodeInvalidURL, err)
+	}
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, 0, newInvalidInputErr(CodeInvalidURL, errors.New("URL 不合法: 仅支持 http/https 绝对地址"))
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36")
+	if opts.Username != "" || opts.Password != "" {
+		username, password = opts.Username, opts.Password
+	}
+
+	req, err := http.NewRequestWithContext(withConnTrace(ctx), http.MethodGet, target, nil)
+	if err != nil {
+		return nil, 0, newInvalidInputErr(CodeInvalidURL, fmt.Errorf("创建请求失败: %w", err))
+	}
+	req.Header.Set("User-Agent", configuredUserAgent())
+	req.Header.Set("Accept", defaultAccept)
 	applyCustomHeaders(req)
+	applyRequestHeaders(req, opts)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	if respectRobots() && !robotsAllowed(ctx, req) {
+		return nil, 0, newBlockedErr(errors.New("blocked by robots.txt"))
+	}
+	if err := defaultLimiter.wait(ctx, req.URL.Hostname(), upstreamRPS(), upstreamMaxWait()); err != nil {
+		if IsRateLimited(err) {
+			return nil, 0, err
+		}
+		return nil, 0, newUpstreamErr(CodeFetchFailed, err)
+	}
 
-	resp, err := defaultHTTPClient.Do(req)
+	client := defaultHTTPClient()
+	if opts.Insecure {
+		client = insecureClient()
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if resp != nil {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
 	if err != nil {
-		return nil, nil, newUpstreamErr(fmt.Errorf("下载 RSS 失败: %w", err))
+		code := CodeFetchFailed
+		switch {
+		case isProxyFailure(err):
+			code = CodeProxyFailed
+		case isDNSFailure(err) && ctx.Err() == nil:
+			code = CodeDNSError
+		}
+		err = newUpstreamErr(code, fmt.Errorf("下载 RSS 失败: %w", err))
+		logUpstreamFetch(ctx, req, nil, 0, time.Since(start), err)
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
+	if opts.diag != nil {
+		opts.diag.contentType = resp.Header.Get("Content-Type")
+	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, nil, newUpstreamErr(fmt.Errorf("RSS 返回非 2xx 状态码: %d", resp.StatusCode))
+		err = upstreamStatusErr(resp, time.Now())
+		logUpstreamFetch(ctx, req, resp, 0, time.Since(start), err)
+		return nil, 0, err
 	}
 
-	reader := io.Reader(resp.Body)
-	var limited *io.LimitedReader
-	maxBytes := maxFeedBytes()
+	body, err := readFeedBody(resp.Body, opts.maxBytes())
+	upstreamBytesTotal.Add(int64(len(body)))
+	if err == nil && opts.diag == nil && isHTMLPage(resp.Header.Get("Content-Type"), body) {
+		err = &FeedError{Kind: ErrorKindUpstream, Code: CodeHTMLPage, Err: errHTMLPage, Snippet: bodySnippet(body)}
+		body = nil
+	}
+	logUpstreamFetch(ctx, req, resp, len(body), time.Since(start), err)
+	return body, upstreamMaxAge(resp.Header), err
+}
+
+// readFeedBody 完整读取响应体后再交给解析器，避免解析器未读到 EOF 时漏判超限。
+// maxBytes > 0 时多读 1 字节：恰好 maxBytes 的内容通过，maxBytes+1 判定为超限。
+// 分块传输（无 Content-Length）的响应同样适用。
+func readFeedBody(r io.Reader, maxBytes int64) ([]byte, error) {
 	if maxBytes > 0 {
-		limited = &io.LimitedReader{R: resp.Body, N: maxBytes + 1}
-		reader = limited
+		r = io.LimitReader(r, maxBytes+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, newUpstreamErr(CodeFetchFailed, fmt.Errorf("读取 RSS 失败: %w", err))
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return nil, newUpstreamErr(CodeTooLarge, fmt.Errorf("RSS 内容超过限制: %d bytes", maxBytes))
 	}
+	return body, nil
+}
 
-	var buf bytes.Buffer
-	tee := io.TeeReader(reader, &buf)
+// errorSnippetRunes 为解析失败时返回给客户端的响应体片段长度（字符数）。
+const errorSnippetRunes = 120
 
-	parser := gofeed.NewParser()
-	feed, err := parser.Parse(tee)
+// bodySnippet 截取响应体开头 errorSnippetRunes 个字符，换行等空白替换为空格，其余控制字符移除。
+func bodySnippet(body []byte) string {
+	text := strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+	var b strings.Builder
+	n := 0
+	for _, r := range text {
+		if n >= errorSnippetRunes {
+			break
+		}
+		switch {
+		case unicode.IsSpace(r):
+			r = ' '
+		case unicode.IsControl(r) || r == '\uFEFF':
+			continue
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return b.String()
+}
+
+// splitCredentials 从 URL 中剥离 userinfo，返回不含凭据的地址与用户名密码。
+// 解析失败时错误信息不包含原始 URL，避免凭据泄露到日志或响应中。
+func splitCredentials(rawURL string) (string, string, string, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		if limited != nil && limited.N == 0 {
-			return nil, nil, newUpstreamErr(fmt.Errorf("RSS 内容超过限制: %d bytes", maxBytes))
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
 		}
-		return nil, nil, newUpstreamErr(fmt.Errorf("解析 RSS 失败: %w", err))
+		return "", "", "", fmt.Errorf("URL 不合法: %w", err)
 	}
-	if limited != nil && limited.N == 0 {
-		return nil, nil, newUpstreamErr(fmt.Errorf("RSS 内容超过限制: %d bytes", maxBytes))
+	if u.User == nil {
+		return rawURL, "", "", nil
 	}
-	thumbnails := extractItemThumbnails(buf.Bytes())
-	return feed, thumbnails, nil
+	username := u.User.Username()
+	password, _ := u.User.Password()
+	u.User = nil
+	return u.String(), username, password, nil
 }
 
 // Convert 将给定 URL 的 RSS 转为统一 JSON 模型。
 func Convert(ctx context.Context, url string) (model.Response, error) {
+	return ConvertWithOptions(ctx, url, Options{})
+}
+
+// ConvertWithOptions 按单次请求的选项将 RSS 转为统一 JSON 模型。
+func ConvertWithOptions(ctx context.Context, url string, opts Options) (model.Response, error) {
+	url, urlWarning, err := normalizeFeedURL(url)
+	if err != nil {
+		return model.Response{}, err
+	}
 	if url == "" {
-		return model.Response{}, newInvalidInputErr(errors.New("缺少 rss url"))
+		return model.Response{}, newInvalidInputErr(CodeMissingURL, errors.New("缺少 rss url"))
 	}
+	url = canonicalFeedURL(url, opts.StripTracking)
+	conversionsTotal.Add(1)
+	conversionsInFlight.Add(1)
+	defer conversionsInFlight.Add(-1)
 
-	feed, thumbnails, err := fetchAndParse(ctx, url)
+	var stats fetchStats
+	feed, body, partial, maxAge, err := fetchAndParse(ctx, url, opts, &stats)
+	if !isDataURL(url) {
+		upstreamStats.record(hostOf(url), err)
+	}
 	if err != nil {
 		return model.Response{}, err
 	}
 	stripExtensions(feed)
+	applyDCDates(feed.Items, opts.Location)
+	thumbnails := extractItemThumbnails(ctx, body)
+	links := extractItemLinks(body)
+	creators := extractCreatorLinks(body)
 
 	items := make([]*model.ItemMeta, 0, len(feed.Items))
 	for i, item := range feed.Items {
+		// 客户端已断开时不再构造剩余条目。
+		if err := ctx.Err(); err != nil {
+			return model.Response{}, err
+		}
 		thumbnail := ""
 		if i < len(thumbnails) {
 			thumbnail = thumbnails[i]
 		}
-		items = append(items, model.NewItemMeta(item, thumbnail))
+		meta := model.NewItemMeta(item, thumbnail)
+		meta.Location = opts.Location
+		meta.OmitContent = opts.OmitContent
+		meta.RawDC = opts.RawDC
+		meta.Prefer = opts.Prefer
+		meta.FieldMap = opts.FieldMap
+		if i < len(creators.itemAuthorURLs) {
+			meta.AuthorURL = creators.itemAuthorURLs[i]
+		}
+		if i < len(links) && len(links[i]) > 0 {
+			meta.Links = links[i]
+			if item.Link == "" {
+				item.Link = primaryLink(links[i])
+			}
+		}
+		items = append(items, meta)
+	}
+	synthesizeGUIDs(feed.Items)
+	warnings := collectWarnings(feed, body)
+	if partial {
+		warnings = append([]string{fmt.Sprintf("feed truncated: returned %d items parsed before the cut", len(items))}, warnings...)
+	}
+	if urlWarning != "" {
+		warnings = append([]string{urlWarning}, warnings...)
+	}
+	totalItems := len(items)
+	if opts.Cursor != nil {
+		items = applyCursor(feed, items, opts.Cursor)
+	}
+	if opts.Count > 0 && len(items) > opts.Count {
+		if opts.Cursor != nil {
+			// 带游标时返回最早的 count 条新条目，下一游标只推进到其中最新的一条，其余新条目下次返回。
+			items = keepOldestItems(feed, items, opts.Count)
+		} else {
+			items = items[:opts.Count]
+			feed.Items = feed.Items[:opts.Count]
+		}
+	}
+	var cursor string
+	if next := nextCursor(items, opts.Cursor); next != nil {
+		cursor = next.Encode()
+	}
+	if opts.ResolveShortlinks {
+		resolveShortlinks(ctx, items)
+	}
+	applyTransforms(feed, items, opts)
+	applySummaries(items, opts.SummaryLen)
+	applyContentFormat(items, opts.ContentFormat)
+	feedMeta := model.NewFeedMeta(feed)
+	feedMeta.ImageInfo = extractImage(body)
+	feedMeta.Location = opts.Location
+	feedMeta.Stats = model.NewFeedStats(items)
+	feedMeta.AuthorURL = creators.feedAuthorURL
+	feedMeta.PaymentURL = creators.paymentURL
+	if !isDataURL(url) {
+		// 规范化地址仍带 userinfo 供抓取时认证，输出前去掉，凭据不出现在响应中。
+		if requested, _, _, err := splitCredentials(url); err == nil {
+			feedMeta.RequestedURL = requested
+		}
+	}
+	ttl := extractUpdateInterval(body)
+	if opts.OmitFeed {
+		feedMeta = nil
 	}
 
 	return model.Response{
-		Status:  "ok",
-		Version: model.APIVersion,
-		Feed:    model.NewFeedMeta(feed),
-		Items:   items,
+		Status:   "ok",
+		Version:  model.APIVersion,
+		Feed:     feedMeta,
+		Items:    items,
+		Partial:  partial,
+		Warnings: warnings,
+		Meta: &model.Meta{
+			TotalItems:    totalItems,
+			ReturnedItems: len(items),
+			FeedBytes:     stats.bytes,
+			FetchMs:       float64(stats.duration) / float64(time.Millisecond),
+			ParseMs:       float64(stats.parse) / float64(time.Millisecond),
+			Cursor:        cursor,
+		},
+		TTL: ttl,
+
+		PollAfterSeconds: int(pollAfter(ttl, maxAge).Seconds()),
 	}, nil
 }
 
@@ -170,59 +756,237 @@ func stripExtensions(feed *gofeed.Feed) {
 	}
 }
 
-func extractItemThumbnails(body []byte) []string {
+// extractItemThumbnails 收集每个 item/entry 内的 thumbnail，包括嵌套在 media:group 中的；
+// 优先取 media:thumbnail，没有时才取无前缀的 thumbnail，其他命名空间的同名元素忽略。
+// 同一优先级有多个候选时取 width 最大的，都未声明 width 时取第一个。
+// ctx 结束时停止扫描并返回 nil。
+func extractItemThumbnails(ctx context.Context, body []byte) []string {
 	if len(body) == 0 {
 		return nil
 	}
-	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder := xml.NewDecoder(&ctxReader{ctx: ctx, r: bytes.NewReader(body)})
+	decoder.CharsetReader = charset.NewReaderLabel
 	thumbnails := make([]string, 0)
-	inItem := false
+	var scope itemScope
 	current := ""
+	currentWidth, currentRank := 0, 0
 	for {
 		tok, err := decoder.Token()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
+			if ctx.Err() != nil {
+				return nil
+			}
 			return thumbnails
 		}
 		switch t := tok.(type) {
 		case xml.StartElement:
-			name := strings.ToLower(t.Name.Local)
-			if name == "item" || name == "entry" {
-				inItem = true
+			if scope.start(t) {
 				current = ""
+				currentWidth, currentRank = 0, 0
 				continue
 			}
-			if !inItem || name != "thumbnail" {
+			if !scope.inItem() || !strings.EqualFold(t.Name.Local, "thumbnail") {
 				continue
 			}
-			if current != "" {
+			// Skip 与 DecodeElement 会消费结束标签，需同步弹出元素栈。
+			scope.skipped()
+			rank := thumbnailRank(&scope, t.Name.Space)
+			if rank == 0 || rank < currentRank {
 				_ = decoder.Skip()
 				continue
 			}
+			if rank > currentRank {
+				current, currentWidth = "", 0
+			}
 			if url := attrURL(t.Attr); url != "" {
-				current = url
+				if width := attrInt(t.Attr, "width"); current == "" || width > currentWidth {
+					current, currentWidth, currentRank = url, width, rank
+				}
+				_ = decoder.Skip()
+				continue
+			}
+			if current != "" {
 				_ = decoder.Skip()
 				continue
 			}
 			var value string
 			if err := decoder.DecodeElement(&value, &t); err == nil {
-				current = strings.TrimSpace(value)
+				if current = strings.TrimSpace(value); current != "" {
+					currentRank = rank
+				}
 			}
 		case xml.EndElement:
-			name := strings.ToLower(t.Name.Local)
-			if name == "item" || name == "entry" {
-				if inItem {
-					thumbnails = append(thumbnails, strings.TrimSpace(current))
+			if scope.end() {
+				if ctx.Err() != nil {
+					return nil
 				}
-				inItem = false
+				thumbnails = append(thumbnails, strings.TrimSpace(current))
 			}
 		}
 	}
 	return thumbnails
 }
 
+// mediaRSSNamespace 为 Media RSS 的命名空间，部分 feed 声明时省略结尾的斜杠。
+const mediaRSSNamespace = "http://search.yahoo.com/mrss/"
+
+// thumbnailRank 返回 thumbnail 元素的优先级：media:thumbnail 为 2，无前缀（feed 自身命名空间）
+// 的 thumbnail 为 1，其他命名空间中同名的元素与缩略图无关，为 0。
+// 未声明 xmlns:media 时 encoding/xml 以前缀 media 作为命名空间，同样视为 Media RSS。
+func thumbnailRank(scope *itemScope, space string) int {
+	switch {
+	case space == "media" || strings.TrimSuffix(space, "/")+"/" == mediaRSSNamespace:
+		return 2
+	case scope.feedSpace(space):
+		return 1
+	}
+	return 0
+}
+
+// extractItemLinks 扫描 item/entry 下带 href 的 <link>，保留 rel 与 type 属性。
+// RSS 的 <link>文本</link> 没有 href，不会被收集。
+func extractItemLinks(body []byte) [][]model.Link {
+	if len(body) == 0 {
+		return nil
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	links := make([][]model.Link, 0)
+	var scope itemScope
+	var current []model.Link
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return links
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if scope.start(t) {
+				current = nil
+				continue
+			}
+			if !scope.inItem() || !strings.EqualFold(t.Name.Local, "link") {
+				continue
+			}
+			link := model.Link{}
+			for _, attr := range t.Attr {
+				switch strings.ToLower(attr.Name.Local) {
+				case "href":
+					link.Href = strings.TrimSpace(attr.Value)
+				case "rel":
+					link.Rel = strings.TrimSpace(attr.Value)
+				case "type":
+					link.Type = strings.TrimSpace(attr.Value)
+				}
+			}
+			if link.Href != "" {
+				current = append(current, link)
+			}
+		case xml.EndElement:
+			if scope.end() {
+				links = append(links, current)
+			}
+		}
+	}
+	return links
+}
+
+// itemScope 在逐 token 扫描时跟踪元素栈，只把根元素 <rss>/<rdf> 或其 <channel> 下的 <item>
+// 以及根元素 <feed> 下的 <entry> 视为条目，与 gofeed 的条目顺序一致。RSS 1.0/RDF 的 <item>
+// 与 <channel> 同级，条目内嵌套的同名扩展元素也不会打断当前条目。
+// 与 gofeed 一样，带扩展命名空间前缀的 <x:item> 不算条目。
+type itemScope struct {
+	stack     []string
+	itemDepth int
+	// rootSpace 为根元素的命名空间，条目通常与根元素同属 feed 的默认命名空间。
+	rootSpace string
+}
+
+// feedNamespaces 为条目元素可以使用的命名空间：RSS 0.9、RSS 1.0 与 Atom。
+var feedNamespaces = map[string]bool{
+	"http://my.netscape.com/rdf/simple/0.9/": true,
+	"http://purl.org/rss/1.0/":               true,
+	"http://www.w3.org/2005/Atom":            true,
+}
+
+// feedSpace 判断元素是否属于 feed 自身的命名空间，而非扩展。
+func (s *itemScope) feedSpace(space string) bool {
+	return space == "" || space == s.rootSpace || feedNamespaces[space]
+}
+
+// start 处理开始标签，进入一个条目时返回 true。
+func (s *itemScope) start(t xml.StartElement) bool {
+	name := strings.ToLower(t.Name.Local)
+	if len(s.stack) == 0 {
+		s.rootSpace = t.Name.Space
+	}
+	s.stack = append(s.stack, name)
+	if s.itemDepth > 0 || !s.feedSpace(t.Name.Space) || !s.isItem(name) {
+		return false
+	}
+	s.itemDepth = len(s.stack)
+	return true
+}
+
+// isItem 判断刚入栈的 name 是否为条目：根元素 <rss>/<rdf> 下或其 <channel> 下的 <item>，
+// 以及根元素 <feed> 下的 <entry>。
+func (s *itemScope) isItem(name string) bool {
+	root := s.stack[0]
+	switch depth := len(s.stack) - 1; {
+	case name == "item" && depth == 1:
+		return root == "rss" || root == "rdf"
+	case name == "item" && depth == 2:
+		return s.stack[1] == "channel" && (root == "rss" || root == "rdf")
+	case name == "entry" && depth == 1:
+		return root == "feed"
+	}
+	return false
+}
+
+// end 处理结束标签，离开当前条目时返回 true。
+func (s *itemScope) end() bool {
+	if len(s.stack) == 0 {
+		return false
+	}
+	closing := s.itemDepth > 0 && len(s.stack) == s.itemDepth
+	s.stack = s.stack[:len(s.stack)-1]
+	if closing {
+		s.itemDepth = 0
+	}
+	return closing
+}
+
+// skipped 在元素连同结束标签被整体跳过后将其弹出。
+func (s *itemScope) skipped() {
+	if len(s.stack) > 0 && len(s.stack) > s.itemDepth {
+		s.stack = s.stack[:len(s.stack)-1]
+	}
+}
+
+// inItem 报告当前是否位于条目内。
+func (s *itemScope) inItem() bool {
+	return s.itemDepth > 0
+}
+
+// primaryLink 优先返回 rel="alternate"（或未声明 rel）的链接，否则返回第一个。
+func primaryLink(links []model.Link) string {
+	for _, link := range links {
+		if link.Rel == "" || strings.EqualFold(link.Rel, "alternate") {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
 func attrURL(attrs []xml.Attr) string {
 	for _, attr := range attrs {
 		if strings.EqualFold(attr.Name.Local, "url") {
@@ -232,6 +996,16 @@ func attrURL(attrs []xml.Attr) string {
 	return ""
 }
 
+// attrInt 读取整数属性，缺失或无效时返回 0。
+func attrInt(attrs []xml.Attr, name string) int {
+	for _, attr := range attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return positiveInt(attr.Value)
+		}
+	}
+	return 0
+}
+
 func maxFeedBytes() int64 {
 	raw := strings.TrimSpace(os.Getenv(maxFeedBytesEnv))
 	if raw == "" {
@@ -244,31 +1018,59 @@ func maxFeedBytes() int64 {
 	return val
 }
 
-// newHTTPClientFromEnv 构造支持代理的 http.Client。
+// newHTTPClientFromEnv 构造支持代理与 TLS 配置的 http.Client。
 func newHTTPClientFromEnv() httpDoer {
-	proxyEnv := strings.TrimSpace(os.Getenv("RSS_PROXY"))
+	tlsConfig := tlsConfigFromEnv()
+	if tlsConfig.InsecureSkipVerify {
+		log.Printf("[warn] %s is enabled: upstream TLS certificates will NOT be verified", tlsSkipVerifyEnv)
+	}
+	return newHTTPClient(tlsConfig)
+}
+
+// insecureHTTPClient 为 insecure=1 的单次请求使用的客户端，首次使用时构造，SetProxy 后重新构造。
+var (
+	insecureHTTPClient   httpDoer
+	insecureHTTPClientMu sync.Mutex
+)
+
+func insecureClient() httpDoer {
+	insecureHTTPClientMu.Lock()
+	defer insecureHTTPClientMu.Unlock()
+	if insecureHTTPClient == nil {
+		tlsConfig := tlsConfigFromEnv()
+		tlsConfig.InsecureSkipVerify = true
+		insecureHTTPClient = newHTTPClient(tlsConfig)
+	}
+	return insecureHTTPClient
+}
+
+// newHTTPClient 按 RSS_PROXY 与给定 TLS 配置构造 http.Client。
+func newHTTPClient(tlsConfig *tls.Config) httpDoer {
+	proxyRaw := strings.TrimSpace(os.Getenv(proxyEnv))
 
 	tr := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   dialTimeout,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           newDialContext(newDialer()),
+		TLSClientConfig:       tlsConfig,
 		TLSHandshakeTimeout:   tlsHandshakeTimeout,
 		ResponseHeaderTimeout: responseHeaderTime,
-		IdleConnTimeout:       idleConnTimeout,
-		MaxIdleConns:          maxIdleConns,
-		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeoutFromEnv(),
+		MaxIdleConns:          maxIdleConns(),
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost(),
+		MaxConnsPerHost:       maxConnsPerHost(),
+		DisableKeepAlives:     disableKeepAlives(),
 		ExpectContinueTimeout: time.Second,
 	}
 
-	if proxyEnv == "" {
-		return &http.Client{Timeout: httpClientTimeout, Transport: tr}
+	if proxyRaw == "" {
+		configureHTTPVersion(tr)
+		return &http.Client{Timeout: httpClientTimeout, Transport: tr, CheckRedirect: checkRedirect}
 	}
 
-	u, err := url.Parse(proxyEnv)
+	u, err := url.Parse(proxyRaw)
 	if err != nil {
-		return &http.Client{Timeout: httpClientTimeout, Transport: tr}
+		configureHTTPVersion(tr)
+		return &http.Client{Timeout: httpClientTimeout, Transport: tr, CheckRedirect: checkRedirect}
 	}
 
 	switch strings.ToLower(u.Scheme) {
@@ -287,7 +1089,57 @@ func newHTTPClientFromEnv() httpDoer {
 		// 未知 scheme 时退回默认设置，避免启动失败。
 	}
 
-	return &http.Client{Timeout: httpClientTimeout, Transport: tr}
+	configureHTTPVersion(tr)
+	return &http.Client{Timeout: httpClientTimeout, Transport: tr, CheckRedirect: checkRedirect}
+}
+
+// maxRedirects 与标准库默认一致。
+const maxRedirects = 10
+
+// errRedirectDowngrade 表示上游把 https 请求跳转到了 http。
+var errRedirectDowngrade = errors.New("拒绝从 https 跳转到 http")
+
+// checkRedirect 默认禁止 https→http 的降级跳转，避免凭据与请求头以明文发出；
+// RSS_ALLOW_DOWNGRADE=1 时放行。跳转次数上限与标准库相同。
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if len(via) > 0 && via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme == "http" && !allowDowngrade() {
+		return fmt.Errorf("%w: %s", errRedirectDowngrade, req.URL.Redacted())
+	}
+	return nil
+}
+
+func allowDowngrade() bool {
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(allowDowngradeEnv)))
+	return val == "1" || val == "true" || val == "on"
+}
+
+// configureHTTPVersion 对所有出站路径（直连、HTTP 代理与 SOCKS5 自定义拨号）一律显式启用 HTTP/2，
+// 因为自定义 TLS 配置与拨号会让标准库不再自动协商 h2；RSS_HTTP2=off（或旧配置 RSS_FORCE_HTTP1=1）
+// 时清空 TLSNextProto，仅使用 HTTP/1.1。
+func configureHTTPVersion(tr *http.Transport) {
+	if forceHTTP1() {
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		return
+	}
+	// 自定义 DialContext（如 SOCKS5）时标准库默认不再尝试 h2，需显式开启。
+	tr.ForceAttemptHTTP2 = true
+	if err := http2.ConfigureTransport(tr); err != nil {
+		log.Printf("[warn] enable HTTP/2 for upstream transport failed: %v", err)
+	}
+}
+
+// forceHTTP1 判断是否禁用 HTTP/2：RSS_HTTP2=off 或兼容旧配置的 RSS_FORCE_HTTP1=1。
+func forceHTTP1() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(http2Env))) {
+	case "0", "false", "off":
+		return true
+	}
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(forceHTTP1Env)))
+	return val == "1" || val == "true" || val == "on"
 }
 
 // applyCustomHeaders 从环境变量解析自定义头并设置到请求上。
@@ -299,6 +1151,22 @@ func applyCustomHeaders(req *http.Request) {
 	}
 }
 
+// applyRequestHeaders 应用单次请求指定的请求头，user_agent 优先级最高。
+func applyRequestHeaders(req *http.Request, opts Options) {
+	for k, values := range opts.Headers {
+		for i, v := range values {
+			if i == 0 {
+				req.Header.Set(k, v)
+				continue
+			}
+			req.Header.Add(k, v)
+		}
+	}
+	if ua := strings.TrimSpace(opts.UserAgent); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+}
+
 func customHeadersFromEnv() map[string]string {
 	raw := strings.TrimSpace(os.Getenv("RSS_HEADERS"))
 	if raw == "" {
@@ -325,15 +1193,23 @@ func customHeadersFromEnv() map[string]string {
 	return res
 }
 
+// errSocksDial 标记无法连接 SOCKS5 代理本身的错误。
+var errSocksDial = errors.New("连接 SOCKS5 代理失败")
+
+// isProxyFailure 判断下载失败是否出在出站代理本身（无法连接 RSS_PROXY），而非目标站点。
+func isProxyFailure(err error) bool {
+	if errors.Is(err, errSocksDial) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "proxyconnect"
+}
+
 // dialSocks5 建立 SOCKS5 连接，仅支持无认证模式。
 func dialSocks5(ctx context.Context, proxyAddr string, targetAddr string) (net.Conn, error) {
-	dialer := &net.Dialer{
-		Timeout:   dialTimeout,
-		KeepAlive: 30 * time.Second,
-	}
-	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	conn, err := newDialContext(newDialer())(ctx, "tcp", proxyAddr)
 	if err != nil {
-		return nil, fmt.Errorf("连接 SOCKS5 代理失败: %w", err)
+		return nil, fmt.Errorf("%w: %w", errSocksDial, err)
 	}
 
 	// 方法协商：版本 5，1 种方法，无认证(0x00)。
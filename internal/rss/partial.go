@@ -0,0 +1,52 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+)
+
+// itemEndPattern 匹配 RSS/RDF 的 </item> 与 Atom 的 </entry>（可带命名空间前缀）。
+var itemEndPattern = regexp.MustCompile(`(?i)</(?:[\w.-]+:)?(?:item|entry)\s*>`)
+
+// truncateToLastItem 用于内容被截断（如连接中断）时的尽力恢复：截取到最后一个完整条目的结束标签，
+// 再按仍未闭合的元素补齐结束标签。找不到完整条目时返回 nil。
+func truncateToLastItem(body []byte) []byte {
+	matches := itemEndPattern.FindAllIndex(body, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	prefix := body[:matches[len(matches)-1][1]]
+
+	decoder := xml.NewDecoder(bytes.NewReader(prefix))
+	decoder.Strict = false
+	var open []xml.Name
+	for {
+		tok, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+		case xml.EndElement:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == t.Name {
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	recovered := bytes.NewBuffer(make([]byte, 0, len(prefix)+64))
+	recovered.Write(prefix)
+	for i := len(open) - 1; i >= 0; i-- {
+		recovered.WriteString("</")
+		if open[i].Space != "" {
+			recovered.WriteString(open[i].Space + ":")
+		}
+		recovered.WriteString(open[i].Local + ">")
+	}
+	return recovered.Bytes()
+}
@@ -0,0 +1,83 @@
+package rss
+
+import (
+	"context"
+	"log"
+	"net/http/httptrace"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 出站连接池大小，未设置或不合法时使用 defaultMaxIdleConns/defaultMaxIdleConnsPerHost。
+// 高扇出的聚合场景可调大，减少对同一主机反复建连。
+const (
+	maxIdleConnsEnv        = "RSS_MAX_IDLE_CONNS"
+	maxIdleConnsPerHostEnv = "RSS_MAX_IDLE_CONNS_PER_HOST"
+	// maxConnsPerHostEnv 限制单个主机的连接总数（含使用中的），默认不限制。
+	maxConnsPerHostEnv = "RSS_MAX_CONNS_PER_HOST"
+	// idleConnTimeoutEnv 为空闲连接保留时长，默认 idleConnTimeout。
+	idleConnTimeoutEnv = "RSS_IDLE_CONN_TIMEOUT"
+	// disableKeepAlivesEnv 为 1 时每个请求都新建连接，用于复用连接会出错的上游或中间代理。
+	disableKeepAlivesEnv = "RSS_DISABLE_KEEPALIVES"
+)
+
+func maxIdleConns() int {
+	return envPositiveInt(maxIdleConnsEnv, defaultMaxIdleConns)
+}
+
+func maxIdleConnsPerHost() int {
+	return envPositiveInt(maxIdleConnsPerHostEnv, defaultMaxIdleConnsPerHost)
+}
+
+func maxConnsPerHost() int {
+	return envPositiveInt(maxConnsPerHostEnv, 0)
+}
+
+func idleConnTimeoutFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(idleConnTimeoutEnv))
+	if raw == "" {
+		return idleConnTimeout
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil || val <= 0 {
+		log.Printf("[warn] ignoring invalid %s=%q", idleConnTimeoutEnv, raw)
+		return idleConnTimeout
+	}
+	return val
+}
+
+func disableKeepAlives() bool {
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(disableKeepAlivesEnv)))
+	return val == "1" || val == "true" || val == "on"
+}
+
+// envPositiveInt 读取正整数环境变量，未设置或不合法时返回 def。
+func envPositiveInt(key string, def int) int {
+	val, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || val <= 0 {
+		return def
+	}
+	return val
+}
+
+// connTrace 统计出站请求拿到的连接是新建的还是从空闲池复用的。
+var connTrace = &httptrace.ClientTrace{
+	GotConn: func(info httptrace.GotConnInfo) {
+		if info.Reused {
+			upstreamConnsReusedTotal.Add(1)
+		} else {
+			upstreamConnsNewTotal.Add(1)
+		}
+	},
+}
+
+func withConnTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, connTrace)
+}
+
+// ConnStats 返回进程启动以来出站请求新建与复用连接的次数，复用比例过低时可调大连接池。
+func ConnStats() (created, reused int64) {
+	return upstreamConnsNewTotal.Value(), upstreamConnsReusedTotal.Value()
+}
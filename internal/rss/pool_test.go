@@ -0,0 +1,84 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func transportOf(t *testing.T, client httpDoer) *http.Transport {
+	t.Helper()
+	c, ok := client.(*http.Client)
+	if !ok {
+		t.Fatalf("expected *http.Client, got %T", client)
+	}
+	tr, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", c.Transport)
+	}
+	return tr
+}
+
+func TestTransportPoolSizeFromEnv(t *testing.T) {
+	tr := transportOf(t, newHTTPClientFromEnv())
+	if tr.MaxIdleConns != defaultMaxIdleConns || tr.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost {
+		t.Fatalf("unexpected defaults %d/%d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+
+	t.Setenv(maxIdleConnsEnv, "500")
+	t.Setenv(maxIdleConnsPerHostEnv, "64")
+	tr = transportOf(t, newHTTPClientFromEnv())
+	if tr.MaxIdleConns != 500 || tr.MaxIdleConnsPerHost != 64 {
+		t.Fatalf("expected configured pool 500/64, got %d/%d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+
+	t.Setenv(maxIdleConnsEnv, "-1")
+	t.Setenv(maxIdleConnsPerHostEnv, "lots")
+	tr = transportOf(t, newHTTPClientFromEnv())
+	if tr.MaxIdleConns != defaultMaxIdleConns || tr.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost {
+		t.Fatalf("expected invalid values to fall back, got %d/%d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+}
+
+func TestConnStatsCountReuse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(sampleRSS))
+	}))
+	defer srv.Close()
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	created, reused := ConnStats()
+	for _, path := range []string{"/a", "/b"} {
+		if _, err := Convert(context.Background(), srv.URL+path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	newCreated, newReused := ConnStats()
+	if newCreated-created != 1 || newReused-reused != 1 {
+		t.Fatalf("expected one new and one reused connection, got %d new and %d reused", newCreated-created, newReused-reused)
+	}
+}
+
+func TestTransportTuningFromEnv(t *testing.T) {
+	tr := transportOf(t, newHTTPClientFromEnv())
+	if tr.MaxConnsPerHost != 0 || tr.DisableKeepAlives || tr.IdleConnTimeout != idleConnTimeout {
+		t.Fatalf("unexpected defaults: max conns %d, keep-alives disabled %v, idle %s", tr.MaxConnsPerHost, tr.DisableKeepAlives, tr.IdleConnTimeout)
+	}
+
+	t.Setenv(maxConnsPerHostEnv, "8")
+	t.Setenv(disableKeepAlivesEnv, "1")
+	t.Setenv(idleConnTimeoutEnv, "90s")
+	tr = transportOf(t, newHTTPClientFromEnv())
+	if tr.MaxConnsPerHost != 8 || !tr.DisableKeepAlives || tr.IdleConnTimeout != 90*time.Second {
+		t.Fatalf("expected configured transport, got max conns %d, keep-alives disabled %v, idle %s", tr.MaxConnsPerHost, tr.DisableKeepAlives, tr.IdleConnTimeout)
+	}
+
+	t.Setenv(idleConnTimeoutEnv, "soon")
+	if tr = transportOf(t, newHTTPClientFromEnv()); tr.IdleConnTimeout != idleConnTimeout {
+		t.Fatalf("expected invalid idle timeout to fall back, got %s", tr.IdleConnTimeout)
+	}
+}
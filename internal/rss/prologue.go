@@ -0,0 +1,29 @@
+package rss
+
+import (
+	"bytes"
+)
+
+var (
+	utf8BOM      = []byte("\xef\xbb\xbf")
+	commentStart = []byte("<!--")
+	commentEnd   = []byte("-->")
+)
+
+// trimFeedPrologue 去除 feed 开头的 UTF-8 BOM、空白以及 XML 声明之前的注释横幅，使 gofeed
+// 与缩略图提取使用的 xml.Decoder 都能从合法的开头解析。其余开头内容原样保留：
+// 首个非空白字节为 { 或 [ 时视为 JSON Feed，不做任何裁剪，避免其内容中的 HTML 被误当作开头。
+func trimFeedPrologue(body []byte) []byte {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, utf8BOM), " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '[' {
+		return body
+	}
+	for bytes.HasPrefix(trimmed, commentStart) {
+		end := bytes.Index(trimmed[len(commentStart):], commentEnd)
+		if end < 0 {
+			break
+		}
+		trimmed = bytes.TrimLeft(trimmed[len(commentStart)+end+len(commentEnd):], " \t\r\n")
+	}
+	return trimmed
+}
@@ -0,0 +1,71 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestTrimFeedPrologue(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"untouched", "<?xml version=\"1.0\"?><rss/>", "<?xml version=\"1.0\"?><rss/>"},
+		{"bom", "\xef\xbb\xbf<?xml version=\"1.0\"?><rss/>", "<?xml version=\"1.0\"?><rss/>"},
+		{"whitespace", "\n\n  \t<feed/>", "<feed/>"},
+		{"stray text kept", "ok\r\n<rss/>", "ok\r\n<rss/>"},
+		{"json feed", " \n{\"content_html\":\"<p>x</p>\"}", " \n{\"content_html\":\"<p>x</p>\"}"},
+		{"comment banner", "<!-- generated by cms -->\n<?xml version=\"1.0\"?><rss/>", "<?xml version=\"1.0\"?><rss/>"},
+		{"several comments", "\xef\xbb\xbf <!-- a --><!-- b -->\n<rss/>", "<rss/>"},
+		{"unterminated comment", "<!-- oops <rss/>", "<!-- oops <rss/>"},
+		{"no markup", "not a feed", "not a feed"},
+		{"blank", " \n", " \n"},
+	}
+	for _, tc := range cases {
+		if got := string(trimFeedPrologue([]byte(tc.in))); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConvertToleratesFeedPrologue(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		title     string
+		thumbnail string
+	}{
+		{"bom-prefixed rss", "\xef\xbb\xbf" + sampleThumbnailRSS, "Item A", "https://example.com/thumb.jpg"},
+		{"whitespace-prefixed atom", "\n\n   \t" + sampleAtom, "Atom Item", ""},
+		{"comment banner", "<!-- Generated by FeedCMS 2.1 -->\n" + sampleThumbnailRSS, "Item A", "https://example.com/thumb.jpg"},
+	}
+	for _, tc := range cases {
+		restore := WithHTTPClient(fakeDoer{body: tc.body, status: http.StatusOK})
+		resp, err := ConvertWithOptions(context.Background(), "https://example.com/feed", Options{})
+		restore()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(resp.Items) == 0 || resp.Items[0].Title != tc.title {
+			t.Fatalf("%s: unexpected items %+v", tc.name, resp.Items)
+		}
+		if resp.Items[0].Thumbnail != tc.thumbnail {
+			t.Fatalf("%s: expected thumbnail %q, got %q", tc.name, tc.thumbnail, resp.Items[0].Thumbnail)
+		}
+	}
+}
+
+func TestConvertJSONFeedWithHTMLContent(t *testing.T) {
+	body := "\n" + `{"version":"https://jsonfeed.org/version/1.1","title":"J","items":[{"id":"1","title":"Post","url":"https://example.com/1","content_html":"<p>Hello <b>world</b></p>"}]}`
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+	resp, err := ConvertWithOptions(context.Background(), "https://example.com/feed.json", Options{})
+	if err != nil {
+		t.Fatalf("convert json feed: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Title != "Post" {
+		t.Fatalf("unexpected items %+v", resp.Items)
+	}
+}
@@ -0,0 +1,60 @@
+package rss
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const proxyEnv = "RSS_PROXY"
+
+// ValidateProxy 检查 RSS_PROXY 的取值：空串表示不使用代理，否则须为带主机名的
+// http、https、socks5 或 socks5h 地址。
+func ValidateProxy(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", proxyEnv, err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid %s: unsupported scheme %q", proxyEnv, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid %s: missing host", proxyEnv)
+	}
+	return nil
+}
+
+// Proxy 返回当前生效的 RSS_PROXY。
+func Proxy() string {
+	return strings.TrimSpace(os.Getenv(proxyEnv))
+}
+
+// SetProxy 校验并热更新出站代理：按新的 RSS_PROXY 重建客户端后原子替换，进行中的请求继续使用旧客户端，
+// 旧客户端的空闲连接随即关闭；同时清空失败缓存，让此前因代理失败的 feed 立即重试。
+// 取值不合法时返回错误，当前代理保持不变。
+func SetProxy(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if err := ValidateProxy(raw); err != nil {
+		return err
+	}
+	if err := os.Setenv(proxyEnv, raw); err != nil {
+		return err
+	}
+	ReloadHTTPClientFromEnv()
+	failures.flush()
+	return nil
+}
+
+func closeIdle(d httpDoer) {
+	if c, ok := d.(*http.Client); ok {
+		c.CloseIdleConnections()
+	}
+}
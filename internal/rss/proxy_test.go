@@ -0,0 +1,92 @@
+package rss
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestSetProxy(t *testing.T) {
+	t.Setenv(proxyEnv, "")
+	prev := currentHTTPClient.Load()
+	defer currentHTTPClient.Store(prev)
+
+	if err := SetProxy("http://proxy.example.com:3128"); err != nil {
+		t.Fatalf("set proxy: %v", err)
+	}
+	tr := defaultHTTPClient().(*http.Client).Transport.(*http.Transport)
+	req, _ := http.NewRequest(http.MethodGet, "https://feeds.example.com/rss", nil)
+	if proxy, err := tr.Proxy(req); err != nil || proxy == nil || proxy.Host != "proxy.example.com:3128" {
+		t.Fatalf("expected requests to use the new proxy, got %v %v", proxy, err)
+	}
+
+	for _, raw := range []string{"ftp://proxy.example.com", "http://", "://bad"} {
+		if err := SetProxy(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+	if Proxy() != "http://proxy.example.com:3128" {
+		t.Fatalf("expected invalid proxies to leave the current one active, got %q", Proxy())
+	}
+}
+
+func TestReloadHTTPClientFromEnv(t *testing.T) {
+	prev := currentHTTPClient.Load()
+	defer currentHTTPClient.Store(prev)
+	currentHTTPClient.Store(nil)
+	req, _ := http.NewRequest(http.MethodGet, "https://feeds.example.com/rss", nil)
+
+	// 首次使用时才读取环境变量。
+	t.Setenv(proxyEnv, "http://first.example.com:3128")
+	tr := transportOf(t, defaultHTTPClient())
+	if proxy, err := tr.Proxy(req); err != nil || proxy == nil || proxy.Host != "first.example.com:3128" {
+		t.Fatalf("expected lazily built client to use RSS_PROXY, got %v %v", proxy, err)
+	}
+
+	t.Setenv(proxyEnv, "http://second.example.com:8080")
+	if transportOf(t, defaultHTTPClient()) != tr {
+		t.Fatal("expected the client to be reused until reloaded")
+	}
+	ReloadHTTPClientFromEnv()
+	tr = transportOf(t, defaultHTTPClient())
+	if proxy, err := tr.Proxy(req); err != nil || proxy == nil || proxy.Host != "second.example.com:8080" {
+		t.Fatalf("expected reloaded client to use the new proxy, got %v %v", proxy, err)
+	}
+
+	t.Setenv(proxyEnv, "socks5://127.0.0.1:1080")
+	ReloadHTTPClientFromEnv()
+	if tr = transportOf(t, defaultHTTPClient()); tr.Proxy != nil || tr.DialContext == nil {
+		t.Fatal("expected SOCKS5 transport after reload")
+	}
+}
+
+func TestDefaultHTTPClientConcurrentInit(t *testing.T) {
+	prev := currentHTTPClient.Load()
+	defer currentHTTPClient.Store(prev)
+	currentHTTPClient.Store(nil)
+
+	clients := make(chan httpDoer, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(clients); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients <- defaultHTTPClient()
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ReloadHTTPClientFromEnv()
+	}()
+	wg.Wait()
+	close(clients)
+	for c := range clients {
+		if c == nil {
+			t.Fatal("expected a client from concurrent initialisation")
+		}
+	}
+	if first, second := defaultHTTPClient(), defaultHTTPClient(); first != second {
+		t.Fatal("expected a single shared client once initialised")
+	}
+}
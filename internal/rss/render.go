@@ -0,0 +1,99 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+// rssDocument 为重新序列化输出的 RSS 2.0 文档，仅包含核心元素。
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title,omitempty"`
+	Link        string `xml:"link,omitempty"`
+	Description string `xml:"description,omitempty"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	GUID        string `xml:"guid,omitempty"`
+}
+
+// RenderRSS 将规范化后的转换结果重新输出为 RSS 2.0：日期统一为 RFC 1123，
+// 相对链接按 feed 链接解析为绝对地址。
+func RenderRSS(resp model.Response) ([]byte, error) {
+	doc := rssDocument{Version: "2.0"}
+	var base *url.URL
+	if resp.Feed != nil && resp.Feed.Feed != nil {
+		feed := resp.Feed.Feed
+		base, _ = url.Parse(feed.Link)
+		doc.Channel.Title = feed.Title
+		doc.Channel.Link = feed.Link
+		doc.Channel.Description = feed.Description
+		doc.Channel.LastBuildDate = formatRSSDate(feed.UpdatedParsed, feed.Updated)
+	}
+	for _, item := range resp.Items {
+		if item == nil || item.Item == nil {
+			continue
+		}
+		description := item.Description
+		if description == "" {
+			description = item.Content
+		}
+		if item.OmitContent {
+			description = ""
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        resolveLink(base, item.Link),
+			Description: description,
+			PubDate:     formatRSSDate(item.PublishedParsed, item.Published),
+			GUID:        item.GUID,
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// formatRSSDate 优先使用解析后的时间，无法解析时保留原始字符串。
+func formatRSSDate(parsed *time.Time, raw string) string {
+	if parsed != nil {
+		return parsed.UTC().Format(time.RFC1123Z)
+	}
+	return strings.TrimSpace(raw)
+}
+
+// resolveLink 将相对链接解析为基于 base 的绝对地址。
+func resolveLink(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if base == nil || link == "" {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil || ref.IsAbs() {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,70 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const renderInputRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Render &amp; Co</title>
+    <link>https://example.com/blog/</link>
+    <description>Demo feed</description>
+    <lastBuildDate>Tue, 02 Jan 2024 10:00:00 +0800</lastBuildDate>
+    <item>
+      <title>First</title>
+      <link>posts/1</link>
+      <description><![CDATA[<p>One</p>]]></description>
+      <pubDate>Mon, 01 Jan 2024 08:00:00 +0800</pubDate>
+      <guid>post-1</guid>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>https://other.example.com/2</link>
+      <pubDate>not a date</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+const renderGoldenRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Render &amp; Co</title>
+    <link>https://example.com/blog/</link>
+    <description>Demo feed</description>
+    <lastBuildDate>Tue, 02 Jan 2024 02:00:00 +0000</lastBuildDate>
+    <item>
+      <title>First</title>
+      <link>https://example.com/blog/posts/1</link>
+      <description>&lt;p&gt;One&lt;/p&gt;</description>
+      <pubDate>Mon, 01 Jan 2024 00:00:00 +0000</pubDate>
+      <guid>post-1</guid>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>https://other.example.com/2</link>
+      <pubDate>not a date</pubDate>
+      <guid>urn:rss2json:529f1bf26bba387fae782412ac1e50b5</guid>
+    </item>
+  </channel>
+</rss>
+`
+
+func TestRenderRSSGolden(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: renderInputRSS, status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https://example.com/rss")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := RenderRSS(resp)
+	if err != nil {
+		t.Fatalf("render error: %v", err)
+	}
+	if string(out) != renderGoldenRSS {
+		t.Fatalf("unexpected xml:\n%s", out)
+	}
+}
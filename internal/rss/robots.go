@@ -0,0 +1,239 @@
+package rss
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	respectRobotsEnv = "RESPECT_ROBOTS"
+	robotsCacheTTL   = 5 * time.Minute
+	robotsMaxBytes   = int64(512 << 10) // 512 KiB，独立于 RSS_MAX_BYTES
+	robotsMaxHosts   = 1024
+)
+
+// robotsRule 表示一条 Allow/Disallow 规则。
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsGroup 表示同一组 User-agent 下的规则。
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsRules 表示解析后的 robots.txt。
+type robotsRules struct {
+	groups []robotsGroup
+}
+
+// parseRobots 解析 robots.txt，仅关注 User-agent/Allow/Disallow。
+func parseRobots(data []byte) *robotsRules {
+	rules := &robotsRules{}
+	var current *robotsGroup
+	inAgents := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			// 连续的 User-agent 行属于同一组。
+			if !inAgents {
+				rules.groups = append(rules.groups, robotsGroup{})
+				current = &rules.groups[len(rules.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			// 空的 Disallow 表示不限制，忽略即可。
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+		default:
+			inAgents = false
+		}
+	}
+	return rules
+}
+
+// allowed 判断给定 User-Agent 是否可以访问 path（含查询串）。
+// 选择最匹配的 User-agent 组，组内最长匹配优先，长度相同时 Allow 优先。
+func (r *robotsRules) allowed(userAgent, path string) bool {
+	group := r.match(userAgent)
+	if group == nil {
+		return true
+	}
+	bestLen := -1
+	allow := true
+	for _, rule := range group.rules {
+		if !robotsPathMatch(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > bestLen || (n == bestLen && rule.allow) {
+			bestLen = n
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// match 选择 agent 名称包含于 User-Agent 中且最长的组，找不到时回退到 "*"。
+func (r *robotsRules) match(userAgent string) *robotsGroup {
+	ua := strings.ToLower(userAgent)
+	var best, wildcard *robotsGroup
+	bestLen := 0
+	for i := range r.groups {
+		group := &r.groups[i]
+		for _, agent := range group.agents {
+			if agent == "*" {
+				if wildcard == nil {
+					wildcard = group
+				}
+				continue
+			}
+			if agent != "" && strings.Contains(ua, agent) && len(agent) > bestLen {
+				best = group
+				bestLen = len(agent)
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return wildcard
+}
+
+// robotsPathMatch 支持 * 通配与 $ 结尾锚定，匹配从路径开头开始。
+func robotsPathMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(path)
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+// robotsCache 按 scheme://host 缓存 robots.txt，条目数有上限。
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+var defaultRobotsCache = &robotsCache{entries: make(map[string]robotsEntry)}
+
+func (c *robotsCache) get(key string, now time.Time) (*robotsRules, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry.rules, true
+}
+
+func (c *robotsCache) set(key string, rules *robotsRules, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= robotsMaxHosts {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// 仍然超限时随机淘汰一个，保证内存有界。
+		for k := range c.entries {
+			if len(c.entries) < robotsMaxHosts {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = robotsEntry{rules: rules, expires: now.Add(robotsCacheTTL)}
+}
+
+func respectRobots() bool {
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(respectRobotsEnv)))
+	return val == "1" || val == "true" || val == "on"
+}
+
+// robotsAllowed 判断 feed 请求是否被目标站点的 robots.txt 禁止。
+// robots.txt 获取失败时放行（fail open）。
+func robotsAllowed(ctx context.Context, req *http.Request) bool {
+	target := req.URL
+	key := target.Scheme + "://" + target.Host
+	now := time.Now()
+	rules, ok := defaultRobotsCache.get(key, now)
+	if !ok {
+		rules = fetchRobots(ctx, key, req.Header.Get("User-Agent"))
+		defaultRobotsCache.set(key, rules, now)
+	}
+	return rules.allowed(req.Header.Get("User-Agent"), target.RequestURI())
+}
+
+// fetchRobots 拉取并解析 robots.txt；任何失败都返回空规则（全部放行）。
+func fetchRobots(ctx context.Context, base, userAgent string) *robotsRules {
+	robotsURL, err := url.JoinPath(base, "robots.txt")
+	if err != nil {
+		return &robotsRules{}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := defaultHTTPClient().Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &robotsRules{}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxBytes))
+	if err != nil {
+		return &robotsRules{}
+	}
+	return parseRobots(data)
+}
@@ -0,0 +1,106 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+const sampleRobots = `
+User-agent: *
+Disallow: /private/
+Allow: /private/feed.xml
+Disallow: /*.php$
+
+User-agent: BadBot
+User-agent: WorseBot
+Disallow: /
+`
+
+func TestRobotsAllowDisallowPrecedence(t *testing.T) {
+	rules := parseRobots([]byte(sampleRobots))
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"/feed.xml", true},
+		{"/private/secret.xml", false},
+		{"/private/feed.xml", true},
+		{"/index.php", false},
+		{"/index.php?x=1", true},
+		{"/php/feed", true},
+	}
+	for _, tc := range cases {
+		if got := rules.allowed("rss2json", tc.path); got != tc.want {
+			t.Fatalf("path %s: expected %v, got %v", tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestRobotsAgentGroups(t *testing.T) {
+	rules := parseRobots([]byte(sampleRobots))
+	if rules.allowed("Mozilla/5.0 (compatible; WorseBot/1.0)", "/feed.xml") {
+		t.Fatal("expected WorseBot group to disallow everything")
+	}
+	if !rules.allowed("Mozilla/5.0 (compatible; GoodBot/1.0)", "/feed.xml") {
+		t.Fatal("expected wildcard group for unknown agent")
+	}
+}
+
+func TestRobotsWildcardPath(t *testing.T) {
+	rules := parseRobots([]byte("User-agent: *\nDisallow: /feeds/*/private\n"))
+	if rules.allowed("any", "/feeds/a/b/private/rss") {
+		t.Fatal("expected wildcard path to be disallowed")
+	}
+	if !rules.allowed("any", "/feeds/public") {
+		t.Fatal("expected unrelated path to be allowed")
+	}
+}
+
+func TestConvertBlockedByRobots(t *testing.T) {
+	t.Setenv(respectRobotsEnv, "1")
+	defaultRobotsCache = &robotsCache{entries: make(map[string]robotsEntry)}
+	restore := WithHTTPClient(robotsDoer{robots: "User-agent: *\nDisallow: /rss\n", robotsStatus: http.StatusOK})
+	defer restore()
+
+	_, err := Convert(context.Background(), "https://blocked.example.com/rss")
+	if !IsBlocked(err) {
+		t.Fatalf("expected blocked error, got %v", err)
+	}
+}
+
+func TestConvertRobotsFailOpen(t *testing.T) {
+	t.Setenv(respectRobotsEnv, "1")
+	defaultRobotsCache = &robotsCache{entries: make(map[string]robotsEntry)}
+	restore := WithHTTPClient(robotsDoer{robotsErr: errors.New("connection refused")})
+	defer restore()
+
+	if _, err := Convert(context.Background(), "https://down.example.com/rss"); err != nil {
+		t.Fatalf("expected fail open, got %v", err)
+	}
+}
+
+type robotsDoer struct {
+	robots       string
+	robotsStatus int
+	robotsErr    error
+}
+
+func (d robotsDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/robots.txt" {
+		if d.robotsErr != nil {
+			return nil, d.robotsErr
+		}
+		return &http.Response{
+			StatusCode: d.robotsStatus,
+			Body:       io.NopCloser(bytes.NewBufferString(d.robots)),
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(sampleRSS)),
+	}, nil
+}
@@ -0,0 +1,239 @@
+package rss
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+const (
+	// shortlinkConcurrency 为同时展开的短链数量上限。
+	shortlinkConcurrency = 4
+	// shortlinkTimeout 为展开单个短链（含全部跳转）的超时。
+	shortlinkTimeout = 3 * time.Second
+	// shortlinkMaxRedirects 为展开单个短链时最多跟随的跳转次数。
+	shortlinkMaxRedirects = 5
+	// shortlinkCacheTTL/shortlinkCacheMaxEntries 控制展开结果的缓存，短链指向通常不会改变。
+	shortlinkCacheTTL        = 24 * time.Hour
+	shortlinkCacheMaxEntries = 10000
+)
+
+// shortlinkHosts 为 resolve_shortlinks 会展开的短链域名，其余链接保持不变。
+var shortlinkHosts = map[string]bool{
+	"t.co":        true,
+	"bit.ly":      true,
+	"bitly.com":   true,
+	"buff.ly":     true,
+	"dlvr.it":     true,
+	"fb.me":       true,
+	"goo.gl":      true,
+	"ift.tt":      true,
+	"is.gd":       true,
+	"lnkd.in":     true,
+	"ow.ly":       true,
+	"tinyurl.com": true,
+	"trib.al":     true,
+}
+
+type shortlinkEntry struct {
+	target  string
+	expires time.Time
+}
+
+// shortlinkCache 按短链记录展开结果，失败不缓存。
+type shortlinkCache struct {
+	mu      sync.Mutex
+	entries map[string]shortlinkEntry
+	now     func() time.Time
+}
+
+var shortlinks = &shortlinkCache{entries: make(map[string]shortlinkEntry), now: time.Now}
+
+func (c *shortlinkCache) get(link string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[link]
+	if !ok || !c.now().Before(entry.expires) {
+		return "", false
+	}
+	return entry.target, true
+}
+
+func (c *shortlinkCache) set(link, target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= shortlinkCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= shortlinkCacheMaxEntries {
+			return
+		}
+	}
+	c.entries[link] = shortlinkEntry{target: target, expires: now.Add(shortlinkCacheTTL)}
+}
+
+// resolveShortlinks 将条目 link 与 links 中的短链替换为跳转后的最终地址，
+// 并发数与单链耗时受限；展开失败时保留原链接。
+func resolveShortlinks(ctx context.Context, items []*model.ItemMeta) {
+	targets := make(map[string]string)
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		if isShortlink(meta.Link) {
+			targets[meta.Link] = ""
+		}
+		for _, link := range meta.Links {
+			if isShortlink(link.Href) {
+				targets[link.Href] = ""
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, shortlinkConcurrency)
+	for link := range targets {
+		wg.Add(1)
+		go func(link string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			target, ok := expandShortlink(ctx, link)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			targets[link] = target
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		if target := targets[meta.Link]; target != "" {
+			meta.Link = target
+		}
+		for i := range meta.Links {
+			if target := targets[meta.Links[i].Href]; target != "" {
+				meta.Links[i].Href = target
+			}
+		}
+	}
+}
+
+// isShortlink 判断链接是否为已知短链域名下的 http(s) 地址。
+func isShortlink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return shortlinkHosts[strings.ToLower(u.Hostname())]
+}
+
+// expandShortlink 逐跳发送 HEAD 请求（不支持 HEAD 时改用 GET 且不读取响应体）跟随跳转，
+// 每一跳都要求为公网 http(s) 地址；跳转次数超限或请求失败时返回 false。
+func expandShortlink(ctx context.Context, link string) (string, bool) {
+	if target, ok := shortlinks.get(link); ok {
+		return target, true
+	}
+	ctx, cancel := context.WithTimeout(ctx, shortlinkTimeout)
+	defer cancel()
+	client := shortlinkClient()
+
+	current, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	for hop := 0; hop <= shortlinkMaxRedirects; hop++ {
+		if !publicHTTPURL(current) {
+			return "", false
+		}
+		resp, err := shortlinkRequest(ctx, client, http.MethodHead, current.String())
+		if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			resp, err = shortlinkRequest(ctx, client, http.MethodGet, current.String())
+		}
+		if err != nil {
+			return "", false
+		}
+		location := resp.Header.Get("Location")
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400 && location != "":
+			next, err := current.Parse(location)
+			if err != nil {
+				return "", false
+			}
+			current = next
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			if hop == 0 {
+				return "", false
+			}
+			target := current.String()
+			shortlinks.set(link, target)
+			return target, true
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// shortlinkRequest 发送不读取响应体的请求，仅返回状态码与响应头。
+func shortlinkRequest(ctx context.Context, client httpDoer, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(withPublicDial(ctx, req.URL.Hostname()))
+	req.Header.Set("User-Agent", configuredUserAgent())
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// shortlinkClient 返回不自动跟随跳转的客户端，以便对每一跳做地址检查。
+func shortlinkClient() httpDoer {
+	client := defaultHTTPClient()
+	c, ok := client.(*http.Client)
+	if !ok {
+		return client
+	}
+	copied := *c
+	copied.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &copied
+}
+
+// publicHTTPURL 拒绝非 http(s)、localhost 以及回环、私有、链路本地等内网 IP 字面量地址，
+// 避免短链被用来探测内网；域名解析到的地址由拨号时的 withPublicDial 检查。
+func publicHTTPURL(u *url.URL) bool {
+	if u == nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || publicIP(ip)
+}
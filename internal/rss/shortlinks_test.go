@@ -0,0 +1,66 @@
+package rss
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// redirectDoer 为 feed 地址返回 body，为短链返回 301 跳转到 locations 中的地址。
+type redirectDoer struct {
+	body      string
+	locations map[string]string
+	hops      atomic.Int32
+}
+
+func (d *redirectDoer) Do(req *http.Request) (*http.Response, error) {
+	if location, ok := d.locations[req.URL.String()]; ok {
+		d.hops.Add(1)
+		if req.Method != http.MethodHead {
+			return &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusMovedPermanently, Header: http.Header{"Location": {location}}, Body: http.NoBody}, nil
+	}
+	if req.URL.Host == "example.com" && req.URL.Path != "/rss" {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(d.body))}, nil
+}
+
+func TestResolveShortlinks(t *testing.T) {
+	body := `<rss version="2.0"><channel><title>Social</title>
+<item><title>a</title><link>https://t.co/abc</link></item>
+<item><title>b</title><link>https://bit.ly/internal</link></item>
+</channel></rss>`
+	doer := &redirectDoer{body: body, locations: map[string]string{
+		"https://t.co/abc":               "/redirect?to=final",
+		"https://t.co/redirect?to=final": "https://example.com/article?utm_source=twitter",
+		"https://bit.ly/internal":        "http://127.0.0.1/admin",
+	}}
+	restore := WithHTTPClient(doer)
+	defer restore()
+	defer func() { shortlinks.entries = make(map[string]shortlinkEntry) }()
+
+	opts := Options{ResolveShortlinks: true, StripTracking: true}
+	resp, err := ConvertWithOptions(context.Background(), "https://example.com/rss", opts)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if got := resp.Items[0].Link; got != "https://example.com/article" {
+		t.Fatalf("expected expanded and cleaned link, got %q", got)
+	}
+	if got := resp.Items[1].Link; got != "https://bit.ly/internal" {
+		t.Fatalf("expected redirect to private address to keep the original, got %q", got)
+	}
+
+	hops := doer.hops.Load()
+	if _, err := ConvertWithOptions(context.Background(), "https://example.com/rss", opts); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if got := doer.hops.Load() - hops; got != 1 {
+		t.Fatalf("expected cached t.co resolution (only bit.ly retried), got %d new hops", got)
+	}
+}
@@ -0,0 +1,102 @@
+package rss
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// defaultSummaryLen 为未指定 summary_len 时摘要的最大字符数。
+const defaultSummaryLen = 200
+
+// summaryEllipsis 为摘要被截断时追加的省略号。
+const summaryEllipsis = "…"
+
+// blockElements 为提取纯文本时需与相邻文本以空格分隔的块级元素。
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Ul: true, atom.Ol: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+	atom.Table: true, atom.Figure: true, atom.Figcaption: true, atom.Section: true, atom.Article: true,
+	atom.Hr: true, atom.Dt: true, atom.Dd: true,
+}
+
+// applySummaries 为每个条目生成 summary：优先取 description，为空时取 content。
+func applySummaries(items []*model.ItemMeta, maxLen int) {
+	if maxLen <= 0 {
+		maxLen = defaultSummaryLen
+	}
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		text := plainText(meta.Description)
+		if text == "" {
+			text = plainText(meta.Content)
+		}
+		meta.Summary = truncateAtWord(text, maxLen)
+	}
+}
+
+// plainText 去除 HTML 标记（脚本类元素整体丢弃）、解码实体并合并空白。
+func plainText(raw string) string {
+	if strings.TrimSpace(raw) == "" {
+		return ""
+	}
+	parent := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), parent)
+	if err != nil {
+		return strings.Join(strings.Fields(raw), " ")
+	}
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			b.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if unsafeElements[node.DataAtom] {
+				return
+			}
+		}
+		block := node.Type == html.ElementNode && blockElements[node.DataAtom]
+		if block {
+			b.WriteByte(' ')
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteByte(' ')
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return strings.Join(strings.FieldsFunc(b.String(), unicode.IsSpace), " ")
+}
+
+// truncateAtWord 将文本截断到 maxLen 个字符以内，尽量在空白处断开并追加省略号；
+// 截断范围内没有空白（如中文）时按字符截断。
+func truncateAtWord(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	cut := runes[:maxLen]
+	if !unicode.IsSpace(runes[maxLen]) {
+		for i := len(cut) - 1; i > 0; i-- {
+			if unicode.IsSpace(cut[i]) {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + summaryEllipsis
+}
@@ -0,0 +1,62 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestPlainText(t *testing.T) {
+	got := plainText(`<p>Hello&nbsp;<b>big</b>   world</p><script>alert(1)</script><ul><li>one</li><li>two</li></ul>`)
+	if got != "Hello big world one two" {
+		t.Fatalf("unexpected plain text %q", got)
+	}
+	if strings.ContainsAny(got, "<>") || strings.Contains(got, "alert") {
+		t.Fatalf("expected html removed, got %q", got)
+	}
+}
+
+func TestTruncateAtWord(t *testing.T) {
+	cases := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"short text", 20, "short text"},
+		{"the quick brown fox jumps", 12, "the quick…"},
+		{"the quick brown fox jumps", 15, "the quick brown…"},
+		{"hello, world again", 8, "hello…"},
+		{"中文没有空格的句子", 4, "中文没有…"},
+	}
+	for _, tc := range cases {
+		if got := truncateAtWord(tc.text, tc.max); got != tc.want {
+			t.Fatalf("truncateAtWord(%q, %d) = %q, want %q", tc.text, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestApplySummaries(t *testing.T) {
+	long := "<p>" + strings.Repeat("lorem <em>ipsum</em> ", 40) + "</p>"
+	items := []*model.ItemMeta{
+		model.NewItemMeta(&model.Item{Description: long}, ""),
+		model.NewItemMeta(&model.Item{Content: "<div>Only <a href=\"#\">content</a></div>"}, ""),
+	}
+	applySummaries(items, 0)
+
+	summary := items[0].Summary
+	if !strings.HasSuffix(summary, summaryEllipsis) {
+		t.Fatalf("expected ellipsis, got %q", summary)
+	}
+	body := strings.TrimSuffix(summary, summaryEllipsis)
+	if utf8.RuneCountInString(body) > defaultSummaryLen || strings.ContainsAny(summary, "<>") {
+		t.Fatalf("unexpected summary %q", summary)
+	}
+	if !strings.HasSuffix(body, "lorem") && !strings.HasSuffix(body, "ipsum") {
+		t.Fatalf("expected cut at word boundary, got %q", summary)
+	}
+	if items[1].Summary != "Only content" {
+		t.Fatalf("expected content fallback, got %q", items[1].Summary)
+	}
+}
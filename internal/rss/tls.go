@@ -0,0 +1,51 @@
+package rss
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	tlsSkipVerifyEnv = "RSS_TLS_SKIP_VERIFY"
+	tlsCAFileEnv     = "RSS_TLS_CA_FILE"
+	tlsMinVersionEnv = "RSS_TLS_MIN_VERSION"
+)
+
+// tlsVersions 为 RSS_TLS_MIN_VERSION 支持的取值。
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfigFromEnv 根据环境变量构造出站 TLS 配置。
+// RSS_TLS_CA_FILE 指定后仅信任该文件中的证书；文件无法加载时不信任任何证书（fail closed）。
+func tlsConfigFromEnv() *tls.Config {
+	cfg := &tls.Config{}
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(tlsSkipVerifyEnv)))
+	cfg.InsecureSkipVerify = val == "1" || val == "true" || val == "on"
+
+	if raw := strings.TrimSpace(os.Getenv(tlsMinVersionEnv)); raw != "" {
+		if version, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(raw), "tls")]; ok {
+			cfg.MinVersion = version
+		} else {
+			log.Printf("[warn] ignoring unsupported %s=%q", tlsMinVersionEnv, raw)
+		}
+	}
+
+	if path := strings.TrimSpace(os.Getenv(tlsCAFileEnv)); path != "" {
+		pool := x509.NewCertPool()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[error] cannot read %s: %v; upstream TLS will fail", tlsCAFileEnv, err)
+		} else if !pool.AppendCertsFromPEM(data) {
+			log.Printf("[error] no certificates found in %s=%s; upstream TLS will fail", tlsCAFileEnv, path)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg
+}
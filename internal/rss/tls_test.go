@@ -0,0 +1,142 @@
+package rss
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+)
+
+func newFeedTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(sampleRSS))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTLSSelfSignedFailsByDefault(t *testing.T) {
+	srv := newFeedTLSServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	if _, err := Convert(context.Background(), srv.URL+"/rss"); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
+
+func TestTLSSkipVerify(t *testing.T) {
+	t.Setenv(tlsSkipVerifyEnv, "1")
+	srv := newFeedTLSServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	if _, err := Convert(context.Background(), srv.URL+"/rss"); err != nil {
+		t.Fatalf("expected skip-verify to succeed, got %v", err)
+	}
+}
+
+func TestTLSCustomCAFile(t *testing.T) {
+	srv := newFeedTLSServer(t)
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(tlsCAFileEnv, path)
+	t.Setenv(tlsMinVersionEnv, "1.2")
+	client := newHTTPClientFromEnv()
+	tr := client.(*http.Client).Transport.(*http.Transport)
+	if tr.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected min version TLS 1.2, got %x", tr.TLSClientConfig.MinVersion)
+	}
+	restore := WithHTTPClient(client)
+	defer restore()
+
+	if _, err := Convert(context.Background(), srv.URL+"/rss"); err != nil {
+		t.Fatalf("expected pinned CA to verify, got %v", err)
+	}
+}
+
+func TestTLSPerRequestInsecure(t *testing.T) {
+	insecureHTTPClientMu.Lock()
+	insecureHTTPClient = nil
+	insecureHTTPClientMu.Unlock()
+	srv := newFeedTLSServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	if _, err := ConvertWithOptions(context.Background(), srv.URL+"/rss", Options{Insecure: true}); err != nil {
+		t.Fatalf("expected insecure request to succeed, got %v", err)
+	}
+}
+
+func TestTLSPerRequestInsecureNotCached(t *testing.T) {
+	insecureHTTPClientMu.Lock()
+	insecureHTTPClient = nil
+	insecureHTTPClientMu.Unlock()
+	srv := newFeedTLSServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	opts := Options{Cache: cache.NewMemory(10), CacheTTL: time.Minute}
+	insecure := opts
+	insecure.Insecure = true
+	if _, err := ConvertWithOptions(context.Background(), srv.URL+"/rss", insecure); err != nil {
+		t.Fatalf("expected insecure request to succeed, got %v", err)
+	}
+	if _, err := ConvertWithOptions(context.Background(), srv.URL+"/rss", opts); err == nil {
+		t.Fatal("expected default request to verify the certificate instead of using the insecure result")
+	}
+}
+
+func newDowngradeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(sampleRSS))
+	}))
+	t.Cleanup(plain.Close)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL+r.URL.Path, http.StatusFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRedirectDowngradeBlocked(t *testing.T) {
+	t.Setenv(tlsSkipVerifyEnv, "1")
+	srv := newDowngradeServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	_, err := Convert(context.Background(), srv.URL+"/blocked")
+	if err == nil {
+		t.Fatal("expected https to http redirect to be rejected")
+	}
+	if !errors.Is(err, errRedirectDowngrade) || ErrorCodeOf(err) != CodeFetchFailed {
+		t.Fatalf("expected fetch_failed downgrade error, got %v (%s)", err, ErrorCodeOf(err))
+	}
+}
+
+func TestRedirectDowngradeAllowed(t *testing.T) {
+	t.Setenv(tlsSkipVerifyEnv, "1")
+	t.Setenv(allowDowngradeEnv, "1")
+	srv := newDowngradeServer(t)
+	restore := WithHTTPClient(newHTTPClientFromEnv())
+	defer restore()
+
+	if _, err := Convert(context.Background(), srv.URL+"/allowed"); err != nil {
+		t.Fatalf("expected downgrade to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package rss
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/zdev0x/rss2json/internal/rss"
+
+// tracer 使用全局 TracerProvider；未启用 OTEL 时为 no-op 实现，开销可忽略。
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// endSpan 记录错误（如有）并结束 span。
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,267 @@
+package rss
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// unsafeElements 为 sanitize 时整体移除（含子节点）的元素。
+var unsafeElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Base:     true,
+	atom.Meta:     true,
+	atom.Link:     true,
+}
+
+const trackingParamsEnv = "TRACKING_PARAMS"
+
+// defaultTrackingParams 为 strip_tracking 默认移除的查询参数，以 * 结尾表示前缀匹配。
+var defaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "igshid", "yclid", "_hsenc", "_hsmi",
+}
+
+// preformattedElements 为 minify_html 时保留原始空白的元素（含子孙节点）。
+var preformattedElements = map[atom.Atom]bool{
+	atom.Pre:      true,
+	atom.Code:     true,
+	atom.Textarea: true,
+	atom.Script:   true,
+	atom.Style:    true,
+}
+
+// applyTransforms 按选项处理条目：sanitize 清理 HTML，minify_html 压缩空白并移除注释，
+// strip_tracking 移除链接、links 与 enclosure 地址中的跟踪参数。
+func applyTransforms(feed *model.Feed, items []*model.ItemMeta, opts Options) {
+	if opts.Sanitize && feed != nil {
+		feed.Description = sanitizeHTML(feed.Description)
+	}
+	if opts.MinifyHTML && feed != nil {
+		feed.Description = minifyHTML(feed.Description)
+	}
+	for _, meta := range items {
+		if meta == nil || meta.Item == nil {
+			continue
+		}
+		if opts.Sanitize {
+			meta.Description = sanitizeHTML(meta.Description)
+			meta.Content = sanitizeHTML(meta.Content)
+		}
+		if opts.MinifyHTML {
+			meta.Description = minifyHTML(meta.Description)
+			meta.Content = minifyHTML(meta.Content)
+		}
+		if opts.StripTracking {
+			meta.Link = stripTrackingParams(meta.Link)
+			for i := range meta.Links {
+				meta.Links[i].Href = stripTrackingParams(meta.Links[i].Href)
+			}
+			for _, enc := range meta.Enclosures {
+				if enc != nil {
+					enc.URL = stripTrackingParams(enc.URL)
+				}
+			}
+		}
+	}
+}
+
+// sanitizeHTML 移除脚本类元素、on* 事件属性以及 javascript: 链接，保留其余标记。
+func sanitizeHTML(raw string) string {
+	if strings.TrimSpace(raw) == "" || !strings.Contains(raw, "<") {
+		return raw
+	}
+	parent := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), parent)
+	if err != nil {
+		return raw
+	}
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		if !sanitizeNode(node) {
+			continue
+		}
+		if err := html.Render(&buf, node); err != nil {
+			return raw
+		}
+	}
+	return buf.String()
+}
+
+// sanitizeNode 原地清理节点，返回 false 表示该节点应被整体移除。
+func sanitizeNode(node *html.Node) bool {
+	if node.Type == html.ElementNode && unsafeElements[node.DataAtom] {
+		return false
+	}
+	if node.Type == html.ElementNode {
+		attrs := node.Attr[:0]
+		for _, attr := range node.Attr {
+			key := strings.ToLower(attr.Key)
+			if strings.HasPrefix(key, "on") {
+				continue
+			}
+			if (key == "href" || key == "src" || key == "action") && isScriptURL(attr.Val) {
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		node.Attr = attrs
+	}
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if !sanitizeNode(child) {
+			node.RemoveChild(child)
+		}
+		child = next
+	}
+	return true
+}
+
+// minifyHTML 移除 HTML 注释，并将连续空白压缩为单个空格，不改变渲染结果；
+// pre、code、textarea 等元素内的内容原样保留。解析失败或不含标记时原样返回。
+func minifyHTML(raw string) string {
+	if strings.TrimSpace(raw) == "" || !strings.Contains(raw, "<") {
+		return raw
+	}
+	parent := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), parent)
+	if err != nil {
+		return raw
+	}
+	for _, node := range nodes {
+		parent.AppendChild(node)
+	}
+	minifyNode(parent)
+	var buf bytes.Buffer
+	for node := parent.FirstChild; node != nil; node = node.NextSibling {
+		if err := html.Render(&buf, node); err != nil {
+			return raw
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// minifyNode 原地压缩文本节点的空白并移除注释节点。
+func minifyNode(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		node.Data = collapseHTMLSpace(node.Data)
+		return
+	case html.ElementNode:
+		if preformattedElements[node.DataAtom] {
+			return
+		}
+	}
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode {
+			node.RemoveChild(child)
+		} else {
+			minifyNode(child)
+		}
+		child = next
+	}
+	// 移除注释后相邻的文本节点合并，避免留下连续空格。
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		for next := child.NextSibling; child.Type == html.TextNode && next != nil && next.Type == html.TextNode; next = child.NextSibling {
+			child.Data = collapseHTMLSpace(child.Data + next.Data)
+			node.RemoveChild(next)
+		}
+	}
+}
+
+// collapseHTMLSpace 将 HTML 空白字符（不含 &nbsp;）的连续片段替换为单个空格。
+func collapseHTMLSpace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inSpace := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+		default:
+			b.WriteByte(s[i])
+			inSpace = false
+		}
+	}
+	return b.String()
+}
+
+func isScriptURL(raw string) bool {
+	v := strings.ToLower(strings.Join(strings.Fields(raw), ""))
+	return strings.HasPrefix(v, "javascript:") || strings.HasPrefix(v, "vbscript:")
+}
+
+// trackingParams 返回需要移除的参数规则，TRACKING_PARAMS（逗号分隔）可覆盖默认列表。
+func trackingParams() []string {
+	raw := strings.TrimSpace(os.Getenv(trackingParamsEnv))
+	if raw == "" {
+		return defaultTrackingParams
+	}
+	var params []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func isTrackingParam(key string, params []string) bool {
+	key = strings.ToLower(key)
+	for _, p := range params {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == p {
+			return true
+		}
+	}
+	return false
+}
+
+// stripTrackingParams 移除链接中的跟踪参数，其余参数保持原有顺序与编码，fragment 保留；
+// 解析失败时原样返回。
+func stripTrackingParams(link string) string {
+	if link == "" || !strings.Contains(link, "?") {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.RawQuery == "" {
+		return link
+	}
+	params := trackingParams()
+	parts := strings.Split(u.RawQuery, "&")
+	kept := parts[:0]
+	for _, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if isTrackingParam(key, params) {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if len(kept) == len(parts) {
+		return link
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return u.String()
+}
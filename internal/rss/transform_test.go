@@ -0,0 +1,53 @@
+package rss
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	in := `<p onclick="steal()">Hi <a href="javascript:alert(1)">x</a><script>alert(1)</script><img src="https://example.com/a.png"></p>`
+	want := `<p>Hi <a>x</a><img src="https://example.com/a.png"/></p>`
+	if got := sanitizeHTML(in); got != want {
+		t.Fatalf("unexpected sanitized html:\n got %s\nwant %s", got, want)
+	}
+	if got := sanitizeHTML("plain text & more"); got != "plain text & more" {
+		t.Fatalf("plain text should be untouched, got %q", got)
+	}
+}
+
+func TestMinifyHTML(t *testing.T) {
+	in := "<div>\n    <!-- generated -->\n    <p>Hello,\n        <b>world</b>   again</p>\n    <pre>  keep\n    this  </pre>\n    <p><code>a  =  b</code></p>\n</div>\n"
+	want := "<div> <p>Hello, <b>world</b> again</p> <pre>  keep\n    this  </pre> <p><code>a  =  b</code></p> </div>"
+	got := minifyHTML(in)
+	if got != want {
+		t.Fatalf("unexpected minified html:\n got %q\nwant %q", got, want)
+	}
+	if len(got) >= len(in) {
+		t.Fatalf("expected smaller output, got %d >= %d bytes", len(got), len(in))
+	}
+	if got := minifyHTML("plain   text"); got != "plain   text" {
+		t.Fatalf("plain text should be untouched, got %q", got)
+	}
+}
+
+func TestStripTrackingParams(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/a?utm_source=x&utm_medium=y&id=3": "https://example.com/a?id=3",
+		"https://example.com/a?fbclid=abc":                     "https://example.com/a",
+		"https://example.com/a?id=3":                           "https://example.com/a?id=3",
+		"https://example.com/a":                                "https://example.com/a",
+		"https://example.com/a?z=1&utm_campaign=c&b=2#section": "https://example.com/a?z=1&b=2#section",
+		"https://example.com/a?gclid=1#top":                    "https://example.com/a#top",
+	}
+	for in, want := range cases {
+		if got := stripTrackingParams(in); got != want {
+			t.Fatalf("%s: expected %s, got %s", in, want, got)
+		}
+	}
+}
+
+func TestStripTrackingParamsConfigurable(t *testing.T) {
+	t.Setenv(trackingParamsEnv, "ref, src_*")
+	got := stripTrackingParams("https://example.com/a?ref=home&src_id=9&utm_source=x")
+	if got != "https://example.com/a?utm_source=x" {
+		t.Fatalf("unexpected result with custom params: %s", got)
+	}
+}
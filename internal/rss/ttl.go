@@ -0,0 +1,117 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// syPeriods 为 sy:updatePeriod 对应的时长。
+var syPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// extractUpdateInterval 读取频道级 <ttl>（分钟）或 sy:updatePeriod/sy:updateFrequency，
+// 返回 feed 建议的更新间隔；两者都存在时取 <ttl>，都不存在时返回 0。
+func extractUpdateInterval(body []byte) time.Duration {
+	if len(body) == 0 {
+		return 0
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	depthInItem := 0
+	var ttl, period time.Duration
+	frequency := 1
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == "item" || name == "entry" {
+				depthInItem++
+				continue
+			}
+			if depthInItem > 0 {
+				continue
+			}
+			switch name {
+			case "ttl", "updateperiod", "updatefrequency":
+			default:
+				continue
+			}
+			var value string
+			if err := decoder.DecodeElement(&value, &t); err != nil {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch name {
+			case "ttl":
+				if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+					ttl = time.Duration(minutes) * time.Minute
+				}
+			case "updateperiod":
+				period = syPeriods[strings.ToLower(value)]
+			case "updatefrequency":
+				if n, err := strconv.Atoi(value); err == nil && n > 0 {
+					frequency = n
+				}
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if (name == "item" || name == "entry") && depthInItem > 0 {
+				depthInItem--
+			}
+		}
+	}
+	if ttl > 0 {
+		return ttl
+	}
+	if period > 0 {
+		return period / time.Duration(frequency)
+	}
+	return 0
+}
+
+// defaultPollAfter 为 feed 与上游都未声明更新间隔时建议的轮询间隔。
+const defaultPollAfter = 5 * time.Minute
+
+// pollAfter 返回建议的轮询间隔：feed 声明的间隔与上游 max-age 不一致时取较大者，
+// 避免客户端比任一方期望的更频繁地轮询；都未声明时返回 defaultPollAfter。
+func pollAfter(ttl, maxAge time.Duration) time.Duration {
+	interval := max(ttl, maxAge)
+	if interval <= 0 {
+		return defaultPollAfter
+	}
+	return interval
+}
+
+// upstreamMaxAge 读取上游响应 Cache-Control 的 max-age；声明 no-store、no-cache
+// 或未声明时返回 0。
+func upstreamMaxAge(header http.Header) time.Duration {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`)); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return maxAge
+}
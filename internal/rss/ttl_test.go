@@ -0,0 +1,52 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractUpdateInterval(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want time.Duration
+	}{
+		{"ttl", `<rss><channel><ttl>30</ttl><item><ttl>1</ttl></item></channel></rss>`, 30 * time.Minute},
+		{"sy", `<rss xmlns:sy="http://purl.org/rss/1.0/modules/syndication/"><channel><sy:updatePeriod>hourly</sy:updatePeriod><sy:updateFrequency>2</sy:updateFrequency></channel></rss>`, 30 * time.Minute},
+		{"none", `<rss><channel><title>x</title></channel></rss>`, 0},
+	}
+	for _, tc := range cases {
+		if got := extractUpdateInterval([]byte(tc.body)); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPollAfterSeconds(t *testing.T) {
+	withTTL := strings.Replace(sampleRSS, "<channel>", "<channel><ttl>60</ttl>", 1)
+	cases := []struct {
+		name string
+		doer fakeDoer
+		want int
+	}{
+		{"feed ttl", fakeDoer{body: withTTL, status: http.StatusOK}, 3600},
+		{"upstream max-age", fakeDoer{body: sampleRSS, status: http.StatusOK, header: http.Header{"Cache-Control": {"public, max-age=900"}}}, 900},
+		{"largest wins", fakeDoer{body: withTTL, status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=7200"}}}, 7200},
+		{"no-cache ignored", fakeDoer{body: sampleRSS, status: http.StatusOK, header: http.Header{"Cache-Control": {"no-cache, max-age=900"}}}, int(defaultPollAfter.Seconds())},
+		{"default", fakeDoer{body: sampleRSS, status: http.StatusOK}, int(defaultPollAfter.Seconds())},
+	}
+	for _, tc := range cases {
+		restore := WithHTTPClient(tc.doer)
+		resp, err := Convert(context.Background(), "https://example.com/rss")
+		restore()
+		if err != nil {
+			t.Fatalf("%s: convert: %v", tc.name, err)
+		}
+		if resp.PollAfterSeconds != tc.want {
+			t.Fatalf("%s: expected poll_after_seconds %d, got %d", tc.name, tc.want, resp.PollAfterSeconds)
+		}
+	}
+}
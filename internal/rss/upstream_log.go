@@ -0,0 +1,137 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	upstreamLogEnv = "UPSTREAM_LOG"
+	// upstreamLogBodyBytes 为 debug 级别下解析失败时记录的响应体前缀长度。
+	upstreamLogBodyBytes = 512
+)
+
+type upstreamLogLevel int
+
+const (
+	upstreamLogOff upstreamLogLevel = iota
+	upstreamLogOn
+	upstreamLogDebug
+)
+
+// redactedHeaders 列出 debug 日志中需要脱敏的请求头。
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+type requestIDKey struct{}
+
+// WithRequestID 将入站请求 ID 写入 ctx，用于出站日志关联。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 返回 ctx 中携带的请求 ID，不存在时返回空字符串。
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// upstreamLog 返回 UPSTREAM_LOG 配置：1/true/on 记录摘要，debug 额外记录请求头与响应体前缀。
+func upstreamLog() upstreamLogLevel {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(upstreamLogEnv))) {
+	case "1", "true", "on":
+		return upstreamLogOn
+	case "debug":
+		return upstreamLogDebug
+	default:
+		return upstreamLogOff
+	}
+}
+
+// logUpstreamFetch 记录一次出站请求的方法、最终 URL、状态码、大小、耗时、类型与是否走代理。
+func logUpstreamFetch(ctx context.Context, req *http.Request, resp *http.Response, size int, elapsed time.Duration, err error) {
+	level := upstreamLog()
+	if level == upstreamLogOff {
+		return
+	}
+	finalURL := req.URL
+	status := 0
+	contentType := ""
+	if resp != nil {
+		if resp.Request != nil && resp.Request.URL != nil {
+			finalURL = resp.Request.URL
+		}
+		status = resp.StatusCode
+		contentType = resp.Header.Get("Content-Type")
+	}
+	line := fmt.Sprintf("[upstream] id=%s %s %s status=%d bytes=%d duration=%s type=%q proxy=%t",
+		RequestID(ctx), req.Method, redactURL(finalURL), status, size, elapsed, contentType, usesProxy(req))
+	if err != nil {
+		line += fmt.Sprintf(" err=%q", err.Error())
+	}
+	if level == upstreamLogDebug {
+		line += " headers=" + redactHeaders(req.Header)
+	}
+	log.Print(line)
+}
+
+// logUpstreamParseFailure 在 debug 级别下记录解析失败时响应体的前缀，便于排查。
+func logUpstreamParseFailure(ctx context.Context, body []byte, err error) {
+	if upstreamLog() != upstreamLogDebug {
+		return
+	}
+	if len(body) > upstreamLogBodyBytes {
+		body = body[:upstreamLogBodyBytes]
+	}
+	log.Printf("[upstream] id=%s parse failed: %v body=%q", RequestID(ctx), err, body)
+}
+
+// redactURL 隐藏 URL 中的 userinfo。
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.User == nil {
+		return u.String()
+	}
+	clone := *u
+	clone.User = url.User("xxxxx")
+	return clone.String()
+}
+
+// redactHeaders 按名称排序输出请求头，鉴权类请求头替换为 xxxxx。
+func redactHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := strings.Join(header[k], ",")
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			value = "xxxxx"
+		}
+		parts = append(parts, k+": "+value)
+	}
+	return "{" + strings.Join(parts, "; ") + "}"
+}
+
+// usesProxy 判断出站请求是否经过 RSS_PROXY 或系统代理环境变量。
+func usesProxy(req *http.Request) bool {
+	if strings.TrimSpace(os.Getenv(proxyEnv)) != "" {
+		return true
+	}
+	proxy, err := http.ProxyFromEnvironment(req)
+	return err == nil && proxy != nil
+}
@@ -0,0 +1,129 @@
+package rss
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxURLLengthEnv     = "RSS_MAX_URL_LENGTH"
+	defaultMaxURLLength = 2048
+)
+
+// doubleEncodedWarning 为检测到 url 被多编码一层并自动解码时附带的警告。
+const doubleEncodedWarning = "url was double-encoded; decoded one extra layer"
+
+// unsafeURLBytes 为 URL 中需转义、但出现在复制来的链接里并不代表链接错误的字符。
+const unsafeURLBytes = "\"<>\\^`{|}"
+
+// maxURLLength 读取 RSS_MAX_URL_LENGTH，默认 2048 字节。
+func maxURLLength() int {
+	raw := strings.TrimSpace(os.Getenv(maxURLLengthEnv))
+	if raw == "" {
+		return defaultMaxURLLength
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil || val <= 0 {
+		log.Printf("[warn] ignoring invalid %s=%q", maxURLLengthEnv, raw)
+		return defaultMaxURLLength
+	}
+	return val
+}
+
+// normalizeFeedURL 在发起请求前清理客户端传入的 url：去除首尾空白，超长或内含空白、
+// 控制字符时返回 malformed_url；形如 https%3A%2F%2F 的双重编码解码一层并返回警告；
+// 其余需转义的字符按字节百分号编码。data: URI 的大小由 RSS_MAX_BYTES 限制，原样返回。
+func normalizeFeedURL(raw string) (normalized string, warning string, err error) {
+	raw = strings.TrimSpace(raw)
+	if isDataURL(raw) {
+		return raw, "", nil
+	}
+	if limit := maxURLLength(); len(raw) > limit {
+		return "", "", newInvalidInputErr(CodeMalformedURL, fmt.Errorf("URL 过长: %d 字节，上限 %d", len(raw), limit))
+	}
+	if decoded, ok := decodeExtraLayer(raw); ok {
+		raw = decoded
+		warning = doubleEncodedWarning
+	}
+	for _, r := range raw {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", "", newInvalidInputErr(CodeMalformedURL, fmt.Errorf("URL 包含空白或控制字符: %q", r))
+		}
+	}
+	return escapeUnsafeURLBytes(raw), warning, nil
+}
+
+// decodeExtraLayer 识别 scheme 后的 :// 被编码的 url 并解码一层。
+func decodeExtraLayer(raw string) (string, bool) {
+	lower := strings.ToLower(raw)
+	if !strings.HasPrefix(lower, "http%3a%2f%2f") && !strings.HasPrefix(lower, "https%3a%2f%2f") {
+		return "", false
+	}
+	decoded, err := url.PathUnescape(raw)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(decoded), true
+}
+
+// escapeUnsafeURLBytes 对非 ASCII 字节与 unsafeURLBytes 中的字符做百分号编码，其余原样保留。
+func escapeUnsafeURLBytes(raw string) string {
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c >= 0x80 || strings.IndexByte(unsafeURLBytes, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// defaultPorts 为各 scheme 的默认端口，规范化时省略。
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// canonicalFeedURL 将 url 规范化为抓取与缓存使用的形式：scheme 与主机名小写、
+// Unicode 主机名转为 punycode、省略默认端口、移除 fragment 并解析 . 与 .. 路径段；
+// stripTracking 时同时移除跟踪参数。无法解析的 url 原样返回，由后续校验报告错误。
+func canonicalFeedURL(raw string, stripTracking bool) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Opaque != "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if strings.Contains(u.Path, ".") {
+		u = u.ResolveReference(&url.URL{})
+	}
+	canonical := u.String()
+	if stripTracking {
+		canonical = stripTrackingParams(canonical)
+	}
+	return canonical
+}
@@ -0,0 +1,112 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestNormalizeFeedURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		warning bool
+		code    ErrorCode
+	}{
+		{"trimmed", "  https://example.com/rss\n", "https://example.com/rss", false, ""},
+		{"too long", "https://example.com/" + strings.Repeat("a", defaultMaxURLLength), "", false, CodeMalformedURL},
+		{"embedded newline", "https://example.com/\nrss", "", false, CodeMalformedURL},
+		{"embedded space", "https://example.com/my feed", "", false, CodeMalformedURL},
+		{"control character", "https://example.com/\x00rss", "", false, CodeMalformedURL},
+		{"escaped", "https://example.com/rss?q={a|b}&t=日", "https://example.com/rss?q=%7Ba%7Cb%7D&t=%E6%97%A5", false, ""},
+		{"double encoded", "https%3A%2F%2Fexample.com%2Frss%3Fa%3D1", "https://example.com/rss?a=1", true, ""},
+		{"encoded once is untouched", "https://example.com/rss?next=https%3A%2F%2Fother", "https://example.com/rss?next=https%3A%2F%2Fother", false, ""},
+	}
+	for _, tc := range cases {
+		got, warning, err := normalizeFeedURL(tc.raw)
+		if tc.code != "" {
+			if ErrorCodeOf(err) != tc.code {
+				t.Fatalf("%s: expected %s, got %v", tc.name, tc.code, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want || (warning != "") != tc.warning {
+			t.Fatalf("%s: got %q warning=%q err=%v", tc.name, got, warning, err)
+		}
+	}
+}
+
+func TestMaxURLLengthFromEnv(t *testing.T) {
+	t.Setenv(maxURLLengthEnv, "32")
+	if _, _, err := normalizeFeedURL("https://example.com/" + strings.Repeat("a", 20)); ErrorCodeOf(err) != CodeMalformedURL {
+		t.Fatalf("expected configured limit to apply, got %v", err)
+	}
+}
+
+func TestConvertWarnsOnDoubleEncodedURL(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https%3A%2F%2Fexample.com%2Frss")
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(resp.Warnings) == 0 || resp.Warnings[0] != doubleEncodedWarning {
+		t.Fatalf("expected double-encoding warning, got %v", resp.Warnings)
+	}
+}
+
+func TestCanonicalFeedURL(t *testing.T) {
+	cases := []struct {
+		name          string
+		raw           string
+		stripTracking bool
+		want          string
+	}{
+		{"uppercase scheme and host", "HTTP://Example.COM/Feed/", false, "http://example.com/Feed/"},
+		{"default http port", "http://example.com:80/feed", false, "http://example.com/feed"},
+		{"default https port", "https://example.com:443/feed", false, "https://example.com/feed"},
+		{"non-default port kept", "https://example.com:8443/feed", false, "https://example.com:8443/feed"},
+		{"fragment removed", "https://example.com/feed#top", false, "https://example.com/feed"},
+		{"dot segments", "https://example.com/a/./b/../feed.xml", false, "https://example.com/a/feed.xml"},
+		{"unicode host", "https://例え.jp/feed", false, "https://xn--r8jz45g.jp/feed"},
+		{"escaped unicode host", "https://%E4%BE%8B%E3%81%88.jp/feed", false, "https://xn--r8jz45g.jp/feed"},
+		{"tracking kept by default", "http://example.com/feed?utm_source=x", false, "http://example.com/feed?utm_source=x"},
+		{"tracking stripped", "http://example.com/feed?utm_source=x&page=2", true, "http://example.com/feed?page=2"},
+		{"ipv6 default port", "http://[::1]:80/feed", false, "http://[::1]/feed"},
+		{"unparseable untouched", "http://exa mple.com/%zz", false, "http://exa mple.com/%zz"},
+	}
+	for _, tc := range cases {
+		if got := canonicalFeedURL(tc.raw, tc.stripTracking); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConvertUsesCanonicalURL(t *testing.T) {
+	doer := &countingDoer{body: sampleRSS, status: http.StatusOK}
+	restore := WithHTTPClient(doer)
+	defer restore()
+
+	opts := Options{Cache: cache.NewMemory(10), CacheTTL: time.Minute}
+	var last model.Response
+	for _, raw := range []string{"HTTP://Example.COM:80/feed#latest", "http://example.com/feed"} {
+		resp, err := ConvertWithOptions(context.Background(), raw, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		last = resp
+	}
+	if got := doer.calls.Load(); got != 1 {
+		t.Fatalf("expected canonical URLs to share the cache, got %d upstream calls", got)
+	}
+	if last.Feed == nil || last.Feed.RequestedURL != "http://example.com/feed" {
+		t.Fatalf("unexpected requestedUrl: %+v", last.Feed)
+	}
+}
@@ -0,0 +1,42 @@
+package rss
+
+import (
+	"os"
+	"strings"
+
+	"github.com/zdev0x/rss2json/internal/version"
+)
+
+const userAgentEnv = "RSS_USER_AGENT"
+
+// browserUserAgent 为 RSS_USER_AGENT=browser 时使用的桌面 Chrome UA，用于只放行浏览器的源站。
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"
+
+// serviceUserAgent 返回标识本服务的默认 UA，便于 feed 发布方识别抓取方。
+func serviceUserAgent() string {
+	return "rss2json/" + version.Version + " (+https://github.com/zdev0x/rss2json)"
+}
+
+// configuredUserAgent 读取 RSS_USER_AGENT，browser 表示使用浏览器 UA，未设置时使用服务 UA。
+func configuredUserAgent() string {
+	raw := strings.TrimSpace(os.Getenv(userAgentEnv))
+	switch {
+	case raw == "":
+		return serviceUserAgent()
+	case strings.EqualFold(raw, "browser"):
+		return browserUserAgent
+	default:
+		return raw
+	}
+}
+
+// DefaultUserAgent 返回未指定单次请求 user_agent 时实际发送的 UA：
+// RSS_HEADERS 中的 User-Agent 优先，其次 RSS_USER_AGENT，最后为服务默认 UA。
+func DefaultUserAgent() string {
+	for k, v := range customHeadersFromEnv() {
+		if strings.EqualFold(k, "User-Agent") {
+			return v
+		}
+	}
+	return configuredUserAgent()
+}
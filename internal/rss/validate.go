@@ -0,0 +1,66 @@
+package rss
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// Validate 拉取并解析 feed，仅返回是否可解析、条目数与诊断信息，不输出完整条目。
+// 下载失败等错误直接返回；内容无法解析（含 HTML 页面）时返回 valid=false、错误说明与诊断信息。
+// 诊断总是基于一次实际抓取，不读写缓存。
+func Validate(ctx context.Context, url string, opts Options) (model.Validation, error) {
+	url, urlWarning, err := normalizeFeedURL(url)
+	if err != nil {
+		return model.Validation{}, err
+	}
+	if url == "" {
+		return model.Validation{}, newInvalidInputErr(CodeMissingURL, errors.New("缺少 rss url"))
+	}
+	url = canonicalFeedURL(url, opts.StripTracking)
+
+	diag := &fetchDiag{}
+	opts.diag = diag
+	opts.Cache = nil
+	body, _, err := fetchFeed(ctx, url, opts)
+	if err != nil {
+		return model.Validation{}, err
+	}
+
+	result := model.Validation{
+		Status:      "ok",
+		Version:     model.APIVersion,
+		Errors:      []string{},
+		Diagnostics: diagnose(body, diag.contentType),
+	}
+	if result.Diagnostics.FeedTypeGuess == "html" {
+		result.Errors = append(result.Errors, errHTMLPage.Error())
+		return result, nil
+	}
+	trimmed := trimFeedPrologue(body)
+	err = guardXML(ctx, trimmed)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return model.Validation{}, ctxErr
+	}
+	var feed *gofeed.Feed
+	if err == nil {
+		feed, err = parseFeed(ctx, trimmed)
+	}
+	if err != nil {
+		logUpstreamParseFailure(ctx, body, err)
+		result.Errors = append(result.Errors, err.Error())
+		result.Diagnostics.ParseError = parseDiagnostic(err)
+		return result, nil
+	}
+	missingElements(feed, result.Diagnostics)
+	result.Valid = true
+	result.FeedType = feed.FeedType
+	result.ItemCount = len(feed.Items)
+	result.Warnings = collectWarnings(feed, body)
+	if urlWarning != "" {
+		result.Warnings = append([]string{urlWarning}, result.Warnings...)
+	}
+	return result, nil
+}
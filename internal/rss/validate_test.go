@@ -0,0 +1,134 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestValidateValidFeed(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("expected valid feed, errors: %v", result.Errors)
+	}
+	if result.FeedType != "rss" {
+		t.Fatalf("unexpected feed type: %s", result.FeedType)
+	}
+	if result.ItemCount != 1 {
+		t.Fatalf("expected 1 item, got %d", result.ItemCount)
+	}
+}
+
+func TestValidateMalformedFeed(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: "<rss><channel><title>bad</title></channel>", status: http.StatusOK})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid {
+		t.Fatal("expected valid=false for malformed feed")
+	}
+	if len(result.Errors) == 0 || result.Errors[0] == "" {
+		t.Fatalf("expected error message, got %v", result.Errors)
+	}
+}
+
+func TestValidateDownloadError(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: "", status: http.StatusNotFound})
+	defer restore()
+
+	if _, err := Validate(context.Background(), "https://example.com/rss", Options{}); err == nil {
+		t.Fatal("expected download error")
+	}
+}
+
+func TestValidateDiagnosticsWellFormed(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK, header: http.Header{"Content-Type": {"application/rss+xml; charset=UTF-8"}}})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := result.Diagnostics
+	if d == nil {
+		t.Fatal("expected diagnostics")
+	}
+	if d.ContentType != "application/rss+xml; charset=UTF-8" || d.Bytes != len(sampleRSS) {
+		t.Fatalf("unexpected content info: %+v", d)
+	}
+	if d.Encoding != "utf-8" || d.EncodingSource != "content-type" {
+		t.Fatalf("unexpected encoding %q from %q", d.Encoding, d.EncodingSource)
+	}
+	if d.FeedTypeGuess != "rss" || !d.WellFormed || d.XMLError != nil || d.ParseError != nil {
+		t.Fatalf("expected well-formed rss, got %+v", d)
+	}
+}
+
+func TestValidateDiagnosticsBrokenXML(t *testing.T) {
+	body := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<rss version=\"2.0\">\n<channel>\n<title>bad</title>\n<item><title>one"
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := result.Diagnostics
+	if d.Encoding != "iso-8859-1" || d.EncodingSource != "xml-declaration" {
+		t.Fatalf("unexpected encoding %q from %q", d.Encoding, d.EncodingSource)
+	}
+	if d.WellFormed || d.XMLError == nil || d.XMLError.Line != 5 {
+		t.Fatalf("expected xml error on line 5, got %+v", d.XMLError)
+	}
+	if result.Valid || d.ParseError == nil || d.ParseError.Message == "" {
+		t.Fatalf("expected parse error, got valid=%v %+v", result.Valid, d.ParseError)
+	}
+}
+
+func TestValidateDiagnosticsHTMLPage(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: "<!DOCTYPE html><html><body>Access denied</body></html>", status: http.StatusOK, header: http.Header{"Content-Type": {"text/html"}}})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil {
+		t.Fatalf("expected report instead of error, got %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0] != errHTMLPage.Error() {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Diagnostics.FeedTypeGuess != "html" || result.Diagnostics.ContentType != "text/html" {
+		t.Fatalf("unexpected diagnostics: %+v", result.Diagnostics)
+	}
+}
+
+func TestValidateDiagnosticsMissingElements(t *testing.T) {
+	body := `<rss version="2.0"><channel><title>t</title><link>https://example.com/</link>
+<item><title>a</title><link>https://example.com/a</link><guid>a</guid><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+<item><description>no title</description></item>
+</channel></rss>`
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+
+	result, err := Validate(context.Background(), "https://example.com/rss", Options{})
+	if err != nil || !result.Valid {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+	d := result.Diagnostics
+	if len(d.FeedMissing) != 0 {
+		t.Fatalf("unexpected feed missing: %v", d.FeedMissing)
+	}
+	for _, key := range []string{"title", "link", "guid", "published"} {
+		if d.ItemsMissing[key] != 1 {
+			t.Fatalf("expected 1 item missing %s, got %v", key, d.ItemsMissing)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// errNonUTF8 表示 feed 声明了非 UTF-8 编码，此时跳过严格 XML 校验。
+var errNonUTF8 = errors.New("non utf-8 encoding")
+
+// collectWarnings 汇总解析成功但不规范的问题，例如宽松解析才能通过的 XML、
+// 缺少必需字段的条目以及无法识别的日期。返回的提示面向 API 调用方，使用英文。
+func collectWarnings(feed *gofeed.Feed, body []byte) []string {
+	var warnings []string
+	if err := strictXMLError(body); err != nil {
+		warnings = append(warnings, fmt.Sprintf("malformed XML recovered by lenient parser: %v", err))
+	}
+	if feed == nil {
+		return warnings
+	}
+	if strings.TrimSpace(feed.Title) == "" {
+		warnings = append(warnings, "feed: missing title")
+	}
+	for i, item := range feed.Items {
+		if item == nil {
+			continue
+		}
+		n := i + 1
+		if strings.TrimSpace(item.Title) == "" && strings.TrimSpace(item.Description) == "" {
+			warnings = append(warnings, fmt.Sprintf("item %d: missing title and description", n))
+		}
+		if strings.TrimSpace(item.Link) == "" {
+			warnings = append(warnings, fmt.Sprintf("item %d: missing link", n))
+		}
+		if item.Published != "" && item.PublishedParsed == nil {
+			warnings = append(warnings, fmt.Sprintf("item %d: unparseable published date %q", n, item.Published))
+		}
+		if item.Updated != "" && item.UpdatedParsed == nil {
+			warnings = append(warnings, fmt.Sprintf("item %d: unparseable updated date %q", n, item.Updated))
+		}
+	}
+	return warnings
+}
+
+// strictXMLError 使用严格模式扫描 XML，返回第一个语法错误；非 UTF-8 编码的 feed 不做检查。
+func strictXMLError(body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return nil, errNonUTF8
+	}
+	for {
+		_, err := decoder.Token()
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, errNonUTF8) {
+			return nil
+		}
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return syntaxErr
+		}
+		return nil
+	}
+}
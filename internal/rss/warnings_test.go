@@ -0,0 +1,66 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConvertWarnsOnMissingItemLink(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleNoLinkRSS, status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https://example.com/rss")
+	if err != nil {
+		t.Fatalf("expected conversion to succeed, got %v", err)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(resp.Items))
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0] != "item 2: missing link" {
+		t.Fatalf("unexpected warnings: %v", resp.Warnings)
+	}
+}
+
+func TestConvertWarnsOnRecoveredXML(t *testing.T) {
+	body := strings.Replace(sampleRSS, "<title>Hello</title>", "<title>Tom & Jerry</title>", 1)
+	restore := WithHTTPClient(fakeDoer{body: body, status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https://example.com/rss")
+	if err != nil {
+		t.Fatalf("expected lenient parse to succeed, got %v", err)
+	}
+	if len(resp.Warnings) == 0 || !strings.HasPrefix(resp.Warnings[0], "malformed XML") {
+		t.Fatalf("expected malformed XML warning, got %v", resp.Warnings)
+	}
+}
+
+func TestConvertWellFormedFeedHasNoWarnings(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	resp, err := Convert(context.Background(), "https://example.com/rss")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", resp.Warnings)
+	}
+}
+
+const sampleNoLinkRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>No Link Feed</title>
+    <link>https://example.com</link>
+    <item>
+      <title>Linked</title>
+      <link>https://example.com/a</link>
+    </item>
+    <item>
+      <title>Unlinked</title>
+    </item>
+  </channel>
+</rss>`
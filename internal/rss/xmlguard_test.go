@@ -0,0 +1,92 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const lolEntityRSS = `<?xml version="1.0"?>
+<!DOCTYPE lolz [
+  <!ENTITY lol "lol">
+  <!ENTITY lol1 "&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;">
+  <!ENTITY lol2 "&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;">
+  <!ENTITY lol3 "&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;">
+  <!ENTITY lol9 "&lol3;&lol3;&lol3;&lol3;&lol3;&lol3;&lol3;&lol3;&lol3;&lol3;">
+]>
+<rss version="2.0"><channel><title>&lol9;</title><item><title>&lol9;</title></item></channel></rss>`
+
+func deepNestingRSS(depth int) string {
+	return `<?xml version="1.0"?><rss version="2.0"><channel><title>Deep</title><item><title>x</title>` +
+		strings.Repeat("<a>", depth) + strings.Repeat("</a>", depth) +
+		`</item></channel></rss>`
+}
+
+func TestConvertRejectsEntityDefinitions(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: lolEntityRSS, status: http.StatusOK})
+	defer restore()
+
+	start := time.Now()
+	_, err := Convert(context.Background(), "https://example.com/lol")
+	if !errors.Is(err, errXMLEntities) {
+		t.Fatalf("expected entity rejection, got %v", err)
+	}
+	if ErrorCodeOf(err) != CodeParseFailed {
+		t.Fatalf("expected parse_failed, got %s", ErrorCodeOf(err))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected fast failure, took %v", elapsed)
+	}
+}
+
+func TestConvertAllowsEntityDefinitionsWhenConfigured(t *testing.T) {
+	t.Setenv(allowXMLEntitiesEnv, "1")
+	restore := WithHTTPClient(fakeDoer{body: lolEntityRSS, status: http.StatusOK})
+	defer restore()
+
+	if _, err := Convert(context.Background(), "https://example.com/lol-allowed"); errors.Is(err, errXMLEntities) {
+		t.Fatalf("expected entity check disabled, got %v", err)
+	}
+}
+
+func TestConvertRejectsDeepNesting(t *testing.T) {
+	restore := WithHTTPClient(fakeDoer{body: deepNestingRSS(10000), status: http.StatusOK})
+	defer restore()
+
+	start := time.Now()
+	_, err := Convert(context.Background(), "https://example.com/deep")
+	if !errors.Is(err, errXMLTooDeep) {
+		t.Fatalf("expected nesting rejection, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected fast failure, took %v", elapsed)
+	}
+}
+
+func TestGuardXMLAllowsOrdinaryFeeds(t *testing.T) {
+	doctype := `<?xml version="1.0"?>
+<!DOCTYPE rss PUBLIC "-//Netscape Communications//DTD RSS 0.91//EN" "http://my.netscape.com/publish/formats/rss-0.91.dtd">
+<rss version="0.91"><channel><title>Old</title><item><title>A</title><link>https://example.com/a</link></item></channel></rss>`
+	for name, body := range map[string]string{
+		"rss":           sampleRSS,
+		"atom":          sampleAtom,
+		"doctype":       doctype,
+		"json":          `{"version":"https://jsonfeed.org/version/1.1","title":"J","items":[]}`,
+		"moderate nest": deepNestingRSS(100),
+	} {
+		if err := guardXML(context.Background(), []byte(body)); err != nil {
+			t.Fatalf("%s: unexpected guard error: %v", name, err)
+		}
+	}
+}
+
+func TestGuardXMLStopsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := guardXML(ctx, []byte(deepNestingRSS(100))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// /admin/cache/keys 每页的默认与最大键数。
+const (
+	defaultCacheKeysLimit = 100
+	maxCacheKeysLimit     = 1000
+)
+
+// newCacheFlushHandler 处理 POST /admin/cache/flush：清空缓存，或通过 url 参数仅删除单个条目。
+// 管理接口必须配置 API_KEY，鉴权由 withAPIKeyAuth 完成。
+func newCacheFlushHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdmin(w, r, opts, http.MethodPost) {
+			return
+		}
+		if _, ok := readLimitedBody(w, r); !ok {
+			return
+		}
+		writeCachePurge(w, r, opts, strings.TrimSpace(r.URL.Query().Get("url")))
+	}
+}
+
+// newCachePurgeHandler 处理 DELETE /admin/cache?url=<feed>：删除单个 feed 的缓存条目。
+func newCachePurgeHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdmin(w, r, opts, http.MethodDelete) {
+			return
+		}
+		target := strings.TrimSpace(r.URL.Query().Get("url"))
+		if target == "" {
+			writeBadRequest(w, "url is required; use DELETE /admin/cache/all to purge every entry")
+			return
+		}
+		writeCachePurge(w, r, opts, target)
+	}
+}
+
+// newCachePurgeAllHandler 处理 DELETE /admin/cache/all：清空全部缓存。
+func newCachePurgeAllHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdmin(w, r, opts, http.MethodDelete) {
+			return
+		}
+		writeCachePurge(w, r, opts, "")
+	}
+}
+
+// writeCachePurge 删除 target 对应的缓存条目，target 为空时清空全部缓存；
+// 记录调用方 IP 与请求 ID 后输出删除的数量。
+func writeCachePurge(w http.ResponseWriter, r *http.Request, opts Options, target string) {
+	removed, scope := 0, "all"
+	if target != "" {
+		// CacheKeyVariants 拒绝带 userinfo 的地址，日志中只记录规范化后的键，不会泄露凭据；
+		// 同时尝试 strip_tracking 请求存储的键，二者都可能命中。
+		keys := rss.CacheKeyVariants(target)
+		quoted := make([]string, len(keys))
+		for i, key := range keys {
+			quoted[i] = strconv.Quote(key)
+			if opts.Cache != nil && opts.Cache.Delete(key) {
+				removed++
+			}
+		}
+		scope = "key=" + strings.Join(quoted, ",")
+	} else if opts.Cache != nil {
+		removed = opts.Cache.Flush()
+	}
+	log.Printf("[admin] cache purge %s removed=%d ip=%s id=%s", scope, removed, clientIP(r), rss.RequestID(r.Context()))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, model.CacheFlush{
+		Status:  "ok",
+		Version: model.APIVersion,
+		Removed: removed,
+	})
+}
+
+// newCacheStatsHandler 处理 GET /admin/cache/stats：返回缓存条目数、占用字节数与命中率，
+// 后端不支持统计的字段省略。要求配置 API_KEY。
+func newCacheStatsHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		hits, misses := rss.CacheStats()
+		stats := model.CacheStats{
+			Status:  "ok",
+			Version: model.APIVersion,
+			Enabled: opts.Cache != nil,
+			Hits:    hits,
+			Misses:  misses,
+		}
+		if hits+misses > 0 {
+			stats.HitRatio = float64(hits) / float64(hits+misses)
+		}
+		if opts.Cache != nil {
+			if n, ok := cache.Len(opts.Cache); ok {
+				stats.Entries = &n
+			}
+			if n, ok := cache.Bytes(opts.Cache); ok {
+				stats.Bytes = &n
+			}
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+// newCacheKeysHandler 处理 GET /admin/cache/keys：按字典序分页列出缓存键，prefix 过滤前缀，
+// after 为上一页返回的 next，limit 为每页数量。要求配置 API_KEY。
+func newCacheKeysHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		query := r.URL.Query()
+		limit := defaultCacheKeysLimit
+		if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > maxCacheKeysLimit {
+				writeBadRequest(w, fmt.Sprintf("limit must be between 1 and %d", maxCacheKeysLimit))
+				return
+			}
+			limit = n
+		}
+		var keys []string
+		if opts.Cache != nil {
+			keys, _ = cache.Keys(opts.Cache, query.Get("prefix"))
+		}
+		if after := query.Get("after"); after != "" {
+			keys = keys[sort.SearchStrings(keys, after+"\x00"):]
+		}
+		page := model.CacheKeys{Status: "ok", Version: model.APIVersion, Keys: []string{}}
+		if len(keys) > limit {
+			keys = keys[:limit]
+			page.Next = keys[limit-1]
+		}
+		page.Keys = append(page.Keys, keys...)
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// newStatsHandler 处理 GET /admin/stats：返回按上游主机统计的转换次数、成功与失败数，
+// 便于定位持续失败的 feed 源。要求配置 API_KEY。
+func newStatsHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, model.AdminStats{
+			Status:  "ok",
+			Version: model.APIVersion,
+			Hosts:   rss.HostStats(),
+		})
+	}
+}
+
+// requireAdmin 校验管理接口的前置条件：必须使用 method 且服务端已配置 API_KEY。
+func requireAdmin(w http.ResponseWriter, r *http.Request, opts Options, method string) bool {
+	if !requireAPIKeyConfigured(w, opts) {
+		return false
+	}
+	if r.Method != method {
+		writeMethodNotAllowed(w, method)
+		return false
+	}
+	return true
+}
+
+// requireAPIKeyConfigured 确保服务端配置了 API_KEY，否则拒绝访问管理与调试接口。
+func requireAPIKeyConfigured(w http.ResponseWriter, opts Options) bool {
+	if strings.TrimSpace(opts.runtime().APIKey) != "" {
+		return true
+	}
+	writeJSON(w, http.StatusForbidden, errorResponse(codeAdminDisabled, "Admin endpoints require API_KEY to be configured."))
+	return false
+}
+
+// withAdminOnly 包装调试类 handler，仅在配置了 API_KEY 时放行。
+func withAdminOnly(next http.Handler, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// registerDebugEndpoints 挂载 /debug/pprof/ 与 /debug/vars。
+func registerDebugEndpoints(mux *http.ServeMux, opts Options) {
+	mux.Handle("/debug/pprof/", withAdminOnly(http.HandlerFunc(pprof.Index), opts))
+	mux.Handle("/debug/pprof/cmdline", withAdminOnly(http.HandlerFunc(pprof.Cmdline), opts))
+	mux.Handle("/debug/pprof/profile", withAdminOnly(http.HandlerFunc(pprof.Profile), opts))
+	mux.Handle("/debug/pprof/symbol", withAdminOnly(http.HandlerFunc(pprof.Symbol), opts))
+	mux.Handle("/debug/pprof/trace", withAdminOnly(http.HandlerFunc(pprof.Trace), opts))
+	mux.Handle("/debug/vars", withAdminOnly(expvar.Handler(), opts))
+}
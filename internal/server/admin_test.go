@@ -0,0 +1,328 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+type countingDoer struct {
+	calls atomic.Int32
+}
+
+func (c *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(sampleRSS)),
+	}, nil
+}
+
+func adminRequest(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer admin-key")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func flushRemoved(t *testing.T, rr *httptest.ResponseRecorder) int {
+	t.Helper()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var payload struct {
+		Removed int `json:"removed"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return payload.Removed
+}
+
+func TestCacheFlushAll(t *testing.T) {
+	doer := &countingDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	for _, host := range []string{"one", "two", "one"} {
+		adminRequest(handler, http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://"+host+".example.com/rss"))
+	}
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected cached second fetch, got %d upstream calls", got)
+	}
+
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodPost, "/admin/cache/flush")); removed != 2 {
+		t.Fatalf("expected 2 removed, got %d", removed)
+	}
+	adminRequest(handler, http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://one.example.com/rss"))
+	if got := doer.calls.Load(); got != 3 {
+		t.Fatalf("expected refetch after flush, got %d upstream calls", got)
+	}
+}
+
+func TestCacheFlushSingleURL(t *testing.T) {
+	restore := rss.WithHTTPClient(&countingDoer{})
+	defer restore()
+
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	feeds := []string{"https://keep.example.com/rss", "https://evict.example.com/rss"}
+	for _, feed := range feeds {
+		adminRequest(handler, http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape(feed))
+	}
+
+	target := "/admin/cache/flush?url=" + url.QueryEscape(feeds[1])
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodPost, target)); removed != 1 {
+		t.Fatalf("expected 1 removed, got %d", removed)
+	}
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodPost, target)); removed != 0 {
+		t.Fatalf("expected nothing left to evict, got %d", removed)
+	}
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodPost, "/admin/cache/flush")); removed != 1 {
+		t.Fatalf("expected the other entry kept, got %d", removed)
+	}
+}
+
+func TestCacheFlushRequiresAuthAndPost(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without key, got %d", rr.Code)
+	}
+	if rr := adminRequest(handler, http.MethodGet, "/admin/cache/flush"); rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	NewHandler(Options{CacheTTL: time.Minute}).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without configured API key, got %d", rr.Code)
+	}
+}
+
+func TestDebugEndpointsDisabled(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key"})
+	for _, path := range []string{"/debug/pprof/", "/debug/vars"} {
+		if rr := adminRequest(handler, http.MethodGet, path); rr.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404 when disabled, got %d", path, rr.Code)
+		}
+	}
+}
+
+func TestDebugEndpointsEnabled(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key", DebugEndpoints: true})
+
+	rr := adminRequest(handler, http.MethodGet, "/debug/vars")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "rss_conversions_total") {
+		t.Fatalf("expected expvars with custom counters, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := adminRequest(handler, http.MethodGet, "/debug/pprof/"); rr.Code != http.StatusOK {
+		t.Fatalf("expected pprof index, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without key, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	NewHandler(Options{DebugEndpoints: true}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when API key is not configured, got %d", rr.Code)
+	}
+}
+
+func TestAdminStatsPerHost(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key"})
+	convert := func(doer fakeDoer, host string) {
+		restore := rss.WithHTTPClient(doer)
+		defer restore()
+		adminRequest(handler, http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://"+host+"/rss"))
+	}
+	convert(fakeDoer{body: sampleRSS, status: http.StatusOK}, "stats-ok.example.com")
+	convert(fakeDoer{body: sampleRSS, status: http.StatusOK}, "stats-ok.example.com")
+	convert(fakeDoer{body: "down", status: http.StatusServiceUnavailable}, "stats-down.example.com")
+
+	rr := adminRequest(handler, http.MethodGet, "/admin/stats")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var payload struct {
+		Hosts []struct {
+			Host          string           `json:"host"`
+			Requests      int64            `json:"requests"`
+			Successes     int64            `json:"successes"`
+			Errors        int64            `json:"errors"`
+			ErrorsByCode  map[string]int64 `json:"errors_by_code"`
+			LastErrorAt   string           `json:"last_error_at"`
+			LastErrorCode string           `json:"last_error_code"`
+		} `json:"hosts"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	seen := 0
+	for _, host := range payload.Hosts {
+		switch host.Host {
+		case "stats-ok.example.com":
+			seen++
+			if host.Requests != 2 || host.Successes != 2 || host.Errors != 0 || host.LastErrorAt != "" {
+				t.Fatalf("unexpected ok host stats %+v", host)
+			}
+		case "stats-down.example.com":
+			seen++
+			if host.Requests != 1 || host.Errors != 1 || host.ErrorsByCode["upstream_status"] != 1 || host.LastErrorCode != "upstream_status" || host.LastErrorAt == "" {
+				t.Fatalf("unexpected failing host stats %+v", host)
+			}
+		}
+	}
+	if seen != 2 {
+		t.Fatalf("expected both hosts in stats, got %s", rr.Body.String())
+	}
+
+	if rr := adminRequest(NewHandler(Options{}), http.MethodGet, "/admin/stats"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without API_KEY, got %d", rr.Code)
+	}
+}
+
+func TestCachePurgeThenMiss(t *testing.T) {
+	doer := &countingDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	feed := "https://purge.example.com/rss"
+	convert := "/api/v1/rss2json?url=" + url.QueryEscape(feed)
+	adminRequest(handler, http.MethodGet, convert)
+	adminRequest(handler, http.MethodGet, convert)
+	if got := doer.calls.Load(); got != 1 {
+		t.Fatalf("expected warm cache, got %d upstream calls", got)
+	}
+
+	if rr := adminRequest(handler, http.MethodDelete, "/admin/cache"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without url, got %d", rr.Code)
+	}
+	if rr := adminRequest(handler, http.MethodGet, "/admin/cache?url="+url.QueryEscape(feed)); rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rr.Code)
+	}
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodDelete, "/admin/cache?url="+url.QueryEscape(feed))); removed != 1 {
+		t.Fatalf("expected 1 removed, got %d", removed)
+	}
+	adminRequest(handler, http.MethodGet, convert)
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected a cache miss after purge, got %d upstream calls", got)
+	}
+
+	if removed := flushRemoved(t, adminRequest(handler, http.MethodDelete, "/admin/cache/all")); removed != 1 {
+		t.Fatalf("expected purge all to remove 1 entry, got %d", removed)
+	}
+	adminRequest(handler, http.MethodGet, convert)
+	if got := doer.calls.Load(); got != 3 {
+		t.Fatalf("expected a cache miss after purging all, got %d upstream calls", got)
+	}
+}
+
+func TestCachePurgeCanonicalizesURL(t *testing.T) {
+	doer := &countingDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	cases := []struct {
+		name    string
+		feed    string
+		extra   string
+		removed int
+	}{
+		{"mixed case host and default port", "HTTP://Purge2.Example.COM:80/rss", "", 1},
+		{"tracking params stripped", "https://purge3.example.com/rss?utm_source=x&id=1", "&strip_tracking=1", 1},
+	}
+	for _, tc := range cases {
+		convert := "/api/v1/rss2json?url=" + url.QueryEscape(tc.feed) + tc.extra
+		before := doer.calls.Load()
+		adminRequest(handler, http.MethodGet, convert)
+		adminRequest(handler, http.MethodGet, convert)
+		if got := doer.calls.Load() - before; got != 1 {
+			t.Fatalf("%s: expected warm cache, got %d upstream calls", tc.name, got)
+		}
+		if removed := flushRemoved(t, adminRequest(handler, http.MethodDelete, "/admin/cache?url="+url.QueryEscape(tc.feed))); removed != tc.removed {
+			t.Fatalf("%s: expected %d removed, got %d", tc.name, tc.removed, removed)
+		}
+		adminRequest(handler, http.MethodGet, convert)
+		if got := doer.calls.Load() - before; got != 2 {
+			t.Fatalf("%s: expected a cache miss after purge, got %d upstream calls", tc.name, got)
+		}
+	}
+}
+
+func TestCacheStatsAndKeys(t *testing.T) {
+	restore := rss.WithHTTPClient(&countingDoer{})
+	defer restore()
+
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute})
+	for _, host := range []string{"a1", "a2", "a3", "b1"} {
+		adminRequest(handler, http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://"+host+".keys.example.com/rss"))
+	}
+
+	rr := adminRequest(handler, http.MethodGet, "/admin/cache/stats")
+	var stats struct {
+		Enabled  bool     `json:"enabled"`
+		Entries  *int     `json:"entries"`
+		Bytes    *int64   `json:"bytes"`
+		HitRatio *float64 `json:"hit_ratio"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("unexpected stats response %d: %s", rr.Code, rr.Body.String())
+	}
+	if !stats.Enabled || stats.Entries == nil || *stats.Entries != 4 || stats.Bytes == nil || *stats.Bytes != int64(4*len(sampleRSS)) || stats.HitRatio == nil {
+		t.Fatalf("unexpected stats %s", rr.Body.String())
+	}
+
+	var pages []string
+	next := ""
+	for {
+		target := "/admin/cache/keys?limit=2&prefix=" + url.QueryEscape("https://a")
+		if next != "" {
+			target += "&after=" + url.QueryEscape(next)
+		}
+		var page struct {
+			Keys []string `json:"keys"`
+			Next string   `json:"next"`
+		}
+		rr := adminRequest(handler, http.MethodGet, target)
+		if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("unexpected keys response %d: %s", rr.Code, rr.Body.String())
+		}
+		pages = append(pages, strings.Join(page.Keys, ","))
+		if next = page.Next; next == "" {
+			break
+		}
+	}
+	want := []string{
+		"https://a1.keys.example.com/rss,https://a2.keys.example.com/rss",
+		"https://a3.keys.example.com/rss",
+	}
+	if strings.Join(pages, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected pages %q", pages)
+	}
+	if rr := adminRequest(handler, http.MethodGet, "/admin/cache/keys?limit=0"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid limit, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	NewHandler(Options{CacheTTL: time.Minute}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/cache/keys", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without configured API key, got %d", rr.Code)
+	}
+}
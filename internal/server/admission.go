@@ -0,0 +1,142 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultQueueTimeout 为未配置 QUEUE_TIMEOUT 时请求在队列中等待空位的最长时间。
+const defaultQueueTimeout = 5 * time.Second
+
+// admission 为准入控制：同时处理的请求数不超过 maxActive，超出的请求按到达顺序排队，
+// 队列已满或等待超过 timeout 时拒绝。
+type admission struct {
+	maxActive int
+	maxQueue  int
+	timeout   time.Duration
+
+	mu      sync.Mutex
+	active  int
+	waiters []chan struct{}
+
+	rejected int64
+	timeouts int64
+}
+
+// newAdmission 按选项构造准入控制，MaxActive 为 0 时返回 nil（不限制）。
+func newAdmission(opts Options) *admission {
+	if opts.MaxActive <= 0 {
+		return nil
+	}
+	return &admission{
+		maxActive: opts.MaxActive,
+		maxQueue:  opts.MaxQueue,
+		timeout:   durationOr(opts.QueueTimeout, defaultQueueTimeout),
+	}
+}
+
+// acquire 返回一个空位；需要排队时返回等待通道，队列已满时返回 ok=false。
+func (a *admission) acquire() (wait chan struct{}, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.active < a.maxActive {
+		a.active++
+		return nil, true
+	}
+	if len(a.waiters) >= a.maxQueue {
+		a.rejected++
+		return nil, false
+	}
+	wait = make(chan struct{})
+	a.waiters = append(a.waiters, wait)
+	return wait, true
+}
+
+// release 归还空位：有排队请求时直接转交给最早到达的一个，否则空位数减一。
+func (a *admission) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.waiters) > 0 {
+		next := a.waiters[0]
+		a.waiters = a.waiters[1:]
+		close(next)
+		return
+	}
+	a.active--
+}
+
+// abandon 将放弃等待的请求移出队列；若空位已在此之前转交给它，则归还该空位。
+func (a *admission) abandon(wait chan struct{}, timedOut bool) {
+	a.mu.Lock()
+	if timedOut {
+		a.timeouts++
+	}
+	for i, w := range a.waiters {
+		if w == wait {
+			a.waiters = append(a.waiters[:i], a.waiters[i+1:]...)
+			a.mu.Unlock()
+			return
+		}
+	}
+	a.mu.Unlock()
+	a.release()
+}
+
+// stats 返回当前处理中与排队中的请求数，以及队列已满、等待超时的拒绝次数。
+func (a *admission) stats() (active, queued int, rejected, timeouts int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.active, len(a.waiters), a.rejected, a.timeouts
+}
+
+// admissionExempt 判断请求是否绕过准入控制：健康检查与指标需要在过载时仍然可用，
+// SSE 流为长连接，由 MAX_STREAMS 单独限制。
+func admissionExempt(path string) bool {
+	return path == "/metrics" || path == "/health" || strings.HasPrefix(path, "/health/") || path == "/api/v1/stream"
+}
+
+// withAdmission 在 adm 非 nil 时为请求加上准入控制。拒绝时返回 503 与 Retry-After；
+// 排队期间客户端断开则直接放弃，不写响应。
+func withAdmission(next http.Handler, adm *admission) http.Handler {
+	if adm == nil {
+		return next
+	}
+	retryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(adm.timeout.Seconds()))))
+	reject := func(w http.ResponseWriter) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Retry-After", retryAfter)
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse(codeOverloaded, "Server is busy. Please try again later."))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if admissionExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		wait, ok := adm.acquire()
+		if !ok {
+			reject(w)
+			return
+		}
+		if wait != nil {
+			timer := time.NewTimer(adm.timeout)
+			select {
+			case <-wait:
+				timer.Stop()
+			case <-timer.C:
+				adm.abandon(wait, true)
+				reject(w)
+				return
+			case <-r.Context().Done():
+				timer.Stop()
+				adm.abandon(wait, false)
+				return
+			}
+		}
+		defer adm.release()
+		next.ServeHTTP(w, r)
+	})
+}
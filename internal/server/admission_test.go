@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// blockingDoer 记录上游请求的主机顺序，每个请求阻塞到 release 收到一个信号或请求被取消。
+type blockingDoer struct {
+	mu      sync.Mutex
+	hosts   []string
+	release chan struct{}
+}
+
+func (b *blockingDoer) Do(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	b.hosts = append(b.hosts, req.URL.Host)
+	b.mu.Unlock()
+	select {
+	case <-b.release:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(sampleRSS))}, nil
+}
+
+func (b *blockingDoer) seen() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.hosts...)
+}
+
+// startConvert 在后台请求转换给定主机的 feed，返回响应结果通道。
+func startConvert(ctx context.Context, handler http.Handler, host string) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://"+host+"/rss"), nil)
+		handler.ServeHTTP(rr, req.WithContext(ctx))
+		done <- rr
+	}()
+	return done
+}
+
+// waitFor 轮询直到 cond 成立，超时则测试失败。
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestAdmissionServesQueuedRequestsInOrder(t *testing.T) {
+	doer := &blockingDoer{release: make(chan struct{})}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+	adm := newAdmission(Options{MaxActive: 1, MaxQueue: 3, QueueTimeout: 5 * time.Second})
+	handler := withAdmission(NewHandler(Options{}), adm)
+
+	hosts := []string{"a.queue.example", "b.queue.example", "c.queue.example"}
+	var results []<-chan *httptest.ResponseRecorder
+	for i, host := range hosts {
+		results = append(results, startConvert(context.Background(), handler, host))
+		if i == 0 {
+			waitFor(t, "first request to reach upstream", func() bool { return len(doer.seen()) == 1 })
+			continue
+		}
+		waitFor(t, "request to queue", func() bool { _, queued, _, _ := adm.stats(); return queued == i })
+	}
+	for range hosts {
+		doer.release <- struct{}{}
+	}
+	for i, done := range results {
+		if rr := <-done; rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d: %s", i, rr.Code, rr.Body.String())
+		}
+	}
+	if got := strings.Join(doer.seen(), ","); got != strings.Join(hosts, ",") {
+		t.Fatalf("expected upstream order %v, got %s", hosts, got)
+	}
+	if active, queued, _, _ := adm.stats(); active != 0 || queued != 0 {
+		t.Fatalf("expected all slots released, active=%d queued=%d", active, queued)
+	}
+}
+
+func TestAdmissionRejectsWhenSaturated(t *testing.T) {
+	doer := &blockingDoer{release: make(chan struct{})}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+	adm := newAdmission(Options{MaxActive: 1, MaxQueue: 1, QueueTimeout: 50 * time.Millisecond})
+	handler := withAdmission(NewHandler(Options{}), adm)
+
+	first := startConvert(context.Background(), handler, "busy.queue.example")
+	waitFor(t, "first request to reach upstream", func() bool { return len(doer.seen()) == 1 })
+	queued := startConvert(context.Background(), handler, "waiting.queue.example")
+	waitFor(t, "second request to queue", func() bool { _, n, _, _ := adm.stats(); return n == 1 })
+
+	// 队列已满：立即拒绝。
+	full := <-startConvert(context.Background(), handler, "full.queue.example")
+	if full.Code != http.StatusServiceUnavailable || full.Header().Get("Retry-After") != "1" || !strings.Contains(full.Body.String(), `"code":"overloaded"`) {
+		t.Fatalf("expected 503 overloaded with Retry-After, got %d %v %s", full.Code, full.Header(), full.Body.String())
+	}
+	// 排队超时：等待 QueueTimeout 后拒绝。
+	if rr := <-queued; rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected queued request to time out with 503, got %d", rr.Code)
+	}
+
+	// 健康检查与指标不受准入控制影响。
+	for _, path := range []string{"/health", "/metrics"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected %s to bypass the queue, got %d", path, rr.Code)
+		}
+	}
+
+	doer.release <- struct{}{}
+	if rr := <-first; rr.Code != http.StatusOK {
+		t.Fatalf("expected first request to complete, got %d", rr.Code)
+	}
+	if _, _, rejected, timeouts := adm.stats(); rejected != 1 || timeouts != 1 {
+		t.Fatalf("expected 1 rejection and 1 timeout, got %d and %d", rejected, timeouts)
+	}
+}
+
+func TestAdmissionClientDisconnectReleasesSlot(t *testing.T) {
+	doer := &blockingDoer{release: make(chan struct{})}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+	adm := newAdmission(Options{MaxActive: 1, MaxQueue: 1, QueueTimeout: 5 * time.Second})
+	handler := withAdmission(NewHandler(Options{}), adm)
+
+	first := startConvert(context.Background(), handler, "holder.queue.example")
+	waitFor(t, "first request to reach upstream", func() bool { return len(doer.seen()) == 1 })
+	ctx, cancel := context.WithCancel(context.Background())
+	gone := startConvert(ctx, handler, "gone.queue.example")
+	waitFor(t, "second request to queue", func() bool { _, n, _, _ := adm.stats(); return n == 1 })
+
+	cancel()
+	if rr := <-gone; rr.Body.Len() != 0 {
+		t.Fatalf("expected no response for a disconnected client, got %d %s", rr.Code, rr.Body.String())
+	}
+	// 断开的请求让出了队列位置，新请求可以排队并在空位释放后得到处理。
+	next := startConvert(context.Background(), handler, "next.queue.example")
+	waitFor(t, "replacement request to queue", func() bool { _, n, _, _ := adm.stats(); return n == 1 })
+	doer.release <- struct{}{}
+	doer.release <- struct{}{}
+	for _, done := range []<-chan *httptest.ResponseRecorder{first, next} {
+		if rr := <-done; rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	if got := strings.Join(doer.seen(), ","); got != "holder.queue.example,next.queue.example" {
+		t.Fatalf("expected the disconnected request never to reach upstream, got %s", got)
+	}
+	if _, _, rejected, timeouts := adm.stats(); rejected != 0 || timeouts != 0 {
+		t.Fatalf("expected no rejections, got %d and %d", rejected, timeouts)
+	}
+}
+
+func TestAdmissionMetrics(t *testing.T) {
+	handler := NewHandler(Options{MaxActive: 2, MaxQueue: 4})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, name := range []string{"rss2json_queue_active 0", "rss2json_queue_depth 0", "rss2json_queue_rejected_total 0", "rss2json_queue_timeouts_total 0"} {
+		if !strings.Contains(rr.Body.String(), name) {
+			t.Fatalf("expected %q in metrics, got %s", name, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// defaultResponseMaxAge 为未配置 RESPONSE_MAX_AGE 且 feed 未声明 ttl 时的缓存时长。
+const defaultResponseMaxAge = 5 * time.Minute
+
+// writeCacheableJSON 输出成功转换结果，附带强 ETag、Cache-Control 与 Last-Modified。
+// ETag 不受 meta.fetchMs 影响。
+// If-None-Match 命中时返回不带正文的 304；未携带 If-None-Match 时按 If-Modified-Since
+// 与最新条目时间比较，未更新则在序列化之前直接返回 304。
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, resp model.Response, maxAge time.Duration) {
+	header := w.Header()
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(responseMaxAge(resp, maxAge).Seconds())))
+	lastModified, hasLastModified := newestTime(resp)
+	if hasLastModified {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		if r.Header.Get("If-None-Match") == "" && notModifiedSince(r.Header.Get("If-Modified-Since"), lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	_, span := otel.Tracer(tracerName).Start(r.Context(), "serialize")
+	body, err := encodeJSON(resp)
+	span.SetAttributes(attribute.Int("rss.bytes", len(body)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "Failed to encode response."))
+		return
+	}
+
+	etag := jsonETag(body)
+	if stable := withoutFetchTime(resp); stable.Meta != resp.Meta {
+		if stableBody, err := encodeJSON(stable); err == nil {
+			etag = jsonETag(stableBody)
+		}
+	}
+	writeJSONTagged(w, r, body, etag)
+}
+
+// writeRSS2JSONCompat 以 rss2json.com 结构输出转换结果，附带强 ETag 与 Cache-Control。
+func writeRSS2JSONCompat(w http.ResponseWriter, r *http.Request, resp model.Response, maxAge time.Duration) {
+	body, err := encodeJSON(model.NewRSS2JSONResponse(resp))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "Failed to encode response."))
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(responseMaxAge(resp, maxAge).Seconds())))
+	writeJSONWithETag(w, r, body)
+}
+
+// withoutFetchTime 返回 meta.fetchMs 与 parseMs 置零的副本，用于计算 ETag：耗时每次请求都不同，
+// 不应让内容未变的响应失去 304。没有耗时信息时原样返回。
+func withoutFetchTime(resp model.Response) model.Response {
+	if resp.Meta == nil || (resp.Meta.FetchMs == 0 && resp.Meta.ParseMs == 0) {
+		return resp
+	}
+	meta := *resp.Meta
+	meta.FetchMs = 0
+	meta.ParseMs = 0
+	resp.Meta = &meta
+	return resp
+}
+
+// jsonETag 返回已序列化 JSON 的强 ETag。
+func jsonETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeJSONWithETag 为已序列化的 JSON 设置强 ETag，If-None-Match 命中时返回不带正文的 304。
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, body []byte) {
+	writeJSONTagged(w, r, body, jsonETag(body))
+}
+
+// writeJSONTagged 以给定 ETag 输出已序列化的 JSON，If-None-Match 命中时返回不带正文的 304。
+func writeJSONTagged(w http.ResponseWriter, r *http.Request, body []byte, etag string) {
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// responseMaxAge 优先使用 RESPONSE_MAX_AGE，其次 feed 声明的 ttl，最后回退到默认值。
+func responseMaxAge(resp model.Response, configured time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
+	}
+	if resp.TTL > 0 {
+		return resp.TTL
+	}
+	return defaultResponseMaxAge
+}
+
+// newestTime 返回条目中最新的发布/更新时间，没有可用条目时间时回退到 feed 的 lastBuildDate。
+func newestTime(resp model.Response) (time.Time, bool) {
+	var newest time.Time
+	for _, item := range resp.Items {
+		if item == nil || item.Item == nil {
+			continue
+		}
+		for _, t := range []*time.Time{item.PublishedParsed, item.UpdatedParsed} {
+			if t != nil && t.After(newest) {
+				newest = *t
+			}
+		}
+	}
+	if newest.IsZero() && resp.Feed != nil && resp.Feed.Feed != nil && resp.Feed.UpdatedParsed != nil {
+		newest = *resp.Feed.UpdatedParsed
+	}
+	return newest, !newest.IsZero()
+}
+
+// notModifiedSince 判断 lastModified 是否不晚于 If-Modified-Since（按秒精度比较）。
+func notModifiedSince(ifModifiedSince string, lastModified time.Time) bool {
+	if ifModifiedSince == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches 判断 If-None-Match 是否包含给定 ETag，按弱比较处理 W/ 前缀。
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+const (
+	// callbackAttempts 为单个回调最多投递的次数。
+	callbackAttempts = 3
+	// callbackJobTimeout 为回调任务中抓取与转换 feed 的超时。
+	callbackJobTimeout = 30 * time.Second
+	// callbackTimeout 为单次投递的超时。
+	callbackTimeout = 10 * time.Second
+	// defaultJobTTL 为任务状态在完成后保留的时长。
+	defaultJobTTL = time.Hour
+	// jobStoreMaxEntries 限制保留的任务数量，避免大量回调请求占满内存。
+	jobStoreMaxEntries = 10000
+
+	callbackSignatureHeader = "X-Rss2json-Signature"
+	callbackJobHeader       = "X-Rss2json-Job"
+)
+
+// 任务状态。
+const (
+	jobPending   = "pending"
+	jobDelivered = "delivered"
+	jobFailed    = "failed"
+)
+
+// callbackBackoff 为投递失败后的首次重试间隔，之后每次翻倍。
+var callbackBackoff = time.Second
+
+// callbackClient 用于投递回调，不跟随重定向，避免跳转到白名单以外的地址。
+var callbackClient = &http.Client{
+	Timeout: callbackTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// jobStore 在内存中保存回调任务状态，更新后超过 ttl 的任务视为不存在。
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[string]model.Job
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func newJobStore(ttl time.Duration) *jobStore {
+	if ttl <= 0 {
+		ttl = defaultJobTTL
+	}
+	return &jobStore{jobs: make(map[string]model.Job), ttl: ttl, now: time.Now}
+}
+
+// create 登记新任务，任务数达到上限且无过期任务可清理时返回 false。
+func (s *jobStore) create(job model.Job) (model.Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	if len(s.jobs) >= jobStoreMaxEntries {
+		for id, existing := range s.jobs {
+			if s.expired(existing, now) {
+				delete(s.jobs, id)
+			}
+		}
+		if len(s.jobs) >= jobStoreMaxEntries {
+			return model.Job{}, false
+		}
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	s.jobs[job.ID] = job
+	return job, true
+}
+
+// update 修改任务状态并刷新更新时间。
+func (s *jobStore) update(id string, fn func(*model.Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	fn(&job)
+	job.UpdatedAt = s.now().UTC()
+	s.jobs[id] = job
+}
+
+func (s *jobStore) get(id string) (model.Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return model.Job{}, false
+	}
+	if s.expired(job, s.now()) {
+		delete(s.jobs, id)
+		return model.Job{}, false
+	}
+	return job, true
+}
+
+// expired 判断任务是否已超过保留时长，进行中的任务不会过期。
+func (s *jobStore) expired(job model.Job, now time.Time) bool {
+	return job.State != jobPending && !now.Before(job.UpdatedAt.Add(s.ttl))
+}
+
+// withCallbacks 在开启 ALLOW_CALLBACKS 时为主接口增加 POST：带 callback_url 的请求立即返回 202
+// 与任务 ID，转换结果在后台完成后 POST 到回调地址。GET 请求携带 callback_url 时返回 405；
+// 开启 ALLOW_REQUEST_HEADERS 时不带 callback_url 的 POST 交给 next 同步处理。
+func withCallbacks(next http.HandlerFunc, jobs *jobStore, background context.Context, opts Options) http.HandlerFunc {
+	if !opts.AllowCallbacks {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		hasCallback := r.URL.Query().Get("callback_url") != ""
+		if r.Method != http.MethodPost {
+			if hasCallback {
+				writeMethodNotAllowed(w, http.MethodPost)
+				return
+			}
+			next(w, r)
+			return
+		}
+		if !hasCallback && opts.AllowRequestHeaders {
+			next(w, r)
+			return
+		}
+		startCallbackJob(w, r, jobs, background, opts)
+	}
+}
+
+// startCallbackJob 校验回调地址并登记任务，随后在后台转换与投递：不随客户端断开而取消，
+// 服务关闭（background 结束）时放弃尚未完成的投递。
+func startCallbackJob(w http.ResponseWriter, r *http.Request, jobs *jobStore, background context.Context, opts Options) {
+	body, ok := readLimitedBody(w, r)
+	if !ok {
+		return
+	}
+	if opts.AllowRequestHeaders && !mergeBodyHeaders(w, r, body) {
+		return
+	}
+	query := r.URL.Query()
+	callbackURL := strings.TrimSpace(query.Get("callback_url"))
+	if callbackURL == "" {
+		writeBadRequest(w, "Missing callback_url.")
+		return
+	}
+	if !callbackAllowed(callbackURL, opts.runtime().CallbackAllowlist) {
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusForbidden, errorResponse(codeCallbackNotAllowed, "callback_url host is not in CALLBACK_ALLOWLIST."))
+		return
+	}
+	// 与同步转换相同的参数校验在登记任务前完成，不合法的请求直接返回 400 而不是稍后投递失败。
+	feedURL := feedURLParam(query)
+	if feedURL == "" {
+		status, message := mapErrorCode(rss.CodeMissingURL, opts.ErrorStatusStyle)
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, status, errorResponse(string(rss.CodeMissingURL), message))
+		return
+	}
+	loc, ok := validateConvertQuery(w, query, opts)
+	if !ok {
+		return
+	}
+	convertOpts := resolveConvertOptions(query, opts)
+	convertOpts.Location = loc
+
+	job, ok := jobs.create(model.Job{
+		Status:      "ok",
+		Version:     model.APIVersion,
+		ID:          newRequestID(),
+		State:       jobPending,
+		URL:         jobFeedURL(feedURL),
+		CallbackURL: callbackURL,
+	})
+	if !ok {
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse(codeInternal, "Too many callback jobs. Please try again later."))
+		return
+	}
+	// 保留请求 ctx 中的请求 ID 等值，取消只跟随服务生命周期。
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	stop := context.AfterFunc(background, cancel)
+	go func() {
+		defer cancel()
+		defer stop()
+		runCallbackJob(ctx, jobs, job, feedURL, convertOpts, opts)
+	}()
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// jobFeedURL 返回记录在任务中、可经 /api/v1/jobs/{id} 读取的 feed 地址：去掉 userinfo，
+// xml_b64 等内联 feed 只保留 data: 的媒体类型部分，不保存整段内容。
+func jobFeedURL(feedURL string) string {
+	if strings.HasPrefix(feedURL, "data:") {
+		mediaType, _, _ := strings.Cut(feedURL, ",")
+		return mediaType
+	}
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		// 无法解析时不确定其中是否含凭据，宁可不记录；转换会以 URL 不合法失败。
+		return ""
+	}
+	if u.User == nil {
+		return feedURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// runCallbackJob 转换 feed 并将完整响应（失败时为错误响应）投递到回调地址，失败按指数退避重试；
+// 等待重试期间 ctx 结束时放弃投递并将任务标记为失败。
+func runCallbackJob(ctx context.Context, jobs *jobStore, job model.Job, feedURL string, convertOpts rss.Options, opts Options) {
+	convertCtx, cancel := context.WithTimeout(ctx, callbackJobTimeout)
+	resp, err := rss.ConvertWithOptions(convertCtx, feedURL, convertOpts)
+	cancel()
+	if err != nil {
+		_, resp = errorBody(err, opts.ErrorStatusStyle, false)
+	}
+	body, err := encodeJSON(resp)
+	if err != nil {
+		jobs.update(job.ID, func(j *model.Job) {
+			j.State = jobFailed
+			j.LastError = "failed to encode response"
+		})
+		return
+	}
+
+	backoff := callbackBackoff
+	for attempt := 1; attempt <= callbackAttempts; attempt++ {
+		err = deliverCallback(ctx, job, body, opts.CallbackSecret)
+		jobs.update(job.ID, func(j *model.Job) {
+			j.Attempts = attempt
+			j.LastError = ""
+			if err != nil {
+				j.LastError = err.Error()
+			}
+			switch {
+			case err == nil:
+				j.State = jobDelivered
+			case attempt == callbackAttempts:
+				j.State = jobFailed
+			}
+		})
+		if err == nil {
+			return
+		}
+		if attempt < callbackAttempts {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				jobs.update(job.ID, func(j *model.Job) {
+					j.State = jobFailed
+					j.LastError = ctx.Err().Error()
+				})
+				log.Printf("[callback] job %s delivery to %s abandoned: %v", job.ID, job.CallbackURL, ctx.Err())
+				return
+			}
+			backoff *= 2
+		}
+	}
+	log.Printf("[callback] job %s delivery to %s failed: %v", job.ID, job.CallbackURL, err)
+}
+
+// deliverCallback 投递一次回调，2xx 视为成功。
+func deliverCallback(ctx context.Context, job model.Job, body []byte, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(callbackJobHeader, job.ID)
+	req.Header.Set(callbackSignatureHeader, callbackSignature(secret, body))
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// callbackSignature 返回 sha256=<hex>，为以 CALLBACK_SECRET 为密钥对请求体计算的 HMAC-SHA256。
+func callbackSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// callbackAllowed 判断回调地址是否为 http(s) 且主机在白名单内（含其子域名），白名单为空时全部拒绝。
+func callbackAllowed(rawURL string, allowlist []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed != "" && (host == allowed || strings.HasSuffix(host, "."+allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// newJobsHandler 处理 /api/v1/jobs/{id}，返回回调任务的当前状态。
+func newJobsHandler(jobs *jobStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		job, ok := jobs.get(r.PathValue("id"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse(codeNotFound, "Unknown or expired job."))
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// TestCompatRSS2JSONGolden 将 testdata 中的 fixture 经完整 Handler 以 compat=rss2json 输出，
+// 与 model 包按 rss2json.com 文档手工整理的 golden 文件逐字比较（该文件并非抓取的真实响应），
+// 覆盖缩略图提取、日期格式化等 model 单元测试之外的环节。
+func TestCompatRSS2JSONGolden(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "rss2json_compat.xml"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	golden, err := os.ReadFile(filepath.Join("..", "..", "model", "testdata", "rss2json_compat.golden.json"))
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	restore := rss.WithHTTPClient(fakeDoer{body: string(fixture), status: http.StatusOK})
+	defer restore()
+
+	rr := httptest.NewRecorder()
+	target := "/api/v1/rss2json?compat=rss2json&url=" + url.QueryEscape("https://medium.com/feed/@janedoe")
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var got bytes.Buffer
+	if err := json.Indent(&got, rr.Body.Bytes(), "", "  "); err != nil {
+		t.Fatalf("indent: %v", err)
+	}
+	if got.String() != string(golden) {
+		t.Fatalf("compat output differs from golden file:\n%s", got.String())
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// newDebugHandler 处理 GET /api/v1/debug：按与主接口相同的规则解析查询参数与服务端配置，
+// 返回实际生效的选项而不抓取 feed，便于核对客户端请求。要求配置 API_KEY。
+func newDebugHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		query := r.URL.Query()
+		loc, ok := validateConvertQuery(w, query, opts)
+		if !ok {
+			return
+		}
+		convertOpts := resolveConvertOptions(query, opts)
+		format := "json"
+		if query.Get("format") == "xml" {
+			format = "xml"
+		}
+		result := model.DebugConfig{
+			Status:   "ok",
+			Version:  model.APIVersion,
+			URL:      feedURLParam(query),
+			Format:   format,
+			Timezone: loc.String(),
+			Options:  effectiveOptions(convertOpts),
+
+			FetchTimeoutSeconds: rss.FetchTimeout().Seconds(),
+			MaxBytes:            convertOpts.EffectiveMaxBytes(),
+		}
+		if convertOpts.Cache != nil && convertOpts.CacheTTL > 0 {
+			result.Cache = model.DebugCache{Enabled: true, TTLSeconds: int(convertOpts.CacheTTL.Seconds())}
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
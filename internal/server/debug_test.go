@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestDebugEndpointReflectsQuery(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute, MaxCount: 20, ForceStripTracking: true})
+	target := "/api/v1/debug?url=https://example.com/rss&count=50&sanitize=1&include_content=0&format=xml&tz=Asia/Shanghai"
+	rr := adminRequest(handler, http.MethodGet, target)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp model.DebugConfig
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if resp.URL != "https://example.com/rss" || resp.Format != "xml" || resp.Timezone != "Asia/Shanghai" {
+		t.Fatalf("unexpected request echo: %+v", resp)
+	}
+	if opts := resp.Options; opts == nil || opts.Count != 20 || !opts.Sanitize || !opts.StripTracking || !opts.OmitContent {
+		t.Fatalf("unexpected effective options: %+v", resp.Options)
+	}
+	if !resp.Cache.Enabled || resp.Cache.TTLSeconds != 60 {
+		t.Fatalf("unexpected cache info: %+v", resp.Cache)
+	}
+	if resp.FetchTimeoutSeconds <= 0 || resp.MaxBytes <= 0 {
+		t.Fatalf("expected resolved timeout and size limit, got %v and %d", resp.FetchTimeoutSeconds, resp.MaxBytes)
+	}
+
+	calls := &countingDoer{}
+	restore := rss.WithHTTPClient(calls)
+	defer restore()
+	if rr := adminRequest(handler, http.MethodGet, target); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if n := calls.calls.Load(); n != 0 {
+		t.Fatalf("expected no upstream fetch, got %d", n)
+	}
+}
+
+func TestDebugEndpointRequiresAPIKey(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/debug?url=https://example.com/rss", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without API_KEY, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	NewHandler(Options{APIKey: "admin-key"}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/debug?url=https://example.com/rss", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// encodeHTMLETagSuffix 附加在 encode_html=1 响应的 ETag 上，与未转义的表示区分。
+const encodeHTMLETagSuffix = "-html"
+
+// withEncodeHTML 在请求带 encode_html=1 时将 JSON 响应中的 <、>、& 转义为 \u003c、\u003e、\u0026，
+// 兼容无法处理原始 HTML 字符的旧客户端；默认输出保持不转义。合法 JSON 中这些字符只出现在字符串内，
+// 对整个响应体逐字节转义与 SetEscapeHTML(true) 等价，也覆盖模型自定义的 MarshalJSON 输出。
+// If-None-Match 中带后缀的 ETag 先还原再交给后续处理，304 协商照常生效。
+func withEncodeHTML(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !queryEnabled(r.URL.Query().Get("encode_html")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			r = r.Clone(r.Context())
+			r.Header.Set("If-None-Match", strings.ReplaceAll(inm, encodeHTMLETagSuffix+`"`, `"`))
+		}
+		next.ServeHTTP(&htmlEscapeWriter{ResponseWriter: w}, r)
+	})
+}
+
+// htmlEscapeWriter 转义 JSON 响应体中的 HTML 字符，其他内容类型原样输出。
+type htmlEscapeWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	escape      bool
+}
+
+func (h *htmlEscapeWriter) WriteHeader(status int) {
+	if h.wroteHeader {
+		return
+	}
+	h.wroteHeader = true
+	header := h.Header()
+	if etag := header.Get("ETag"); etag != "" {
+		header.Set("ETag", strings.TrimSuffix(etag, `"`)+encodeHTMLETagSuffix+`"`)
+	}
+	h.escape = strings.HasPrefix(header.Get("Content-Type"), "application/json")
+	h.ResponseWriter.WriteHeader(status)
+}
+
+func (h *htmlEscapeWriter) Write(p []byte) (int, error) {
+	if !h.wroteHeader {
+		h.WriteHeader(http.StatusOK)
+	}
+	if !h.escape {
+		return h.ResponseWriter.Write(p)
+	}
+	var buf bytes.Buffer
+	json.HTMLEscape(&buf, p)
+	if _, err := h.ResponseWriter.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Unwrap 供 http.ResponseController 访问底层连接。
+func (h *htmlEscapeWriter) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
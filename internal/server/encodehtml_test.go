@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+func TestEncodeHTMLEscapesJSONOutput(t *testing.T) {
+	feed := strings.Replace(sampleRSS, "<guid>abc123</guid>", "<guid>abc123</guid><description>&lt;p&gt;Tom &amp;amp; Jerry&lt;/p&gt;</description>", 1)
+	restore := rss.WithHTTPClient(fakeDoer{body: feed, status: http.StatusOK})
+	defer restore()
+	handler := NewHandler(Options{})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "<p>") {
+		t.Fatalf("expected unescaped HTML by default, got %d: %s", rr.Code, rr.Body.String())
+	}
+	plainETag := rr.Header().Get("ETag")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss&encode_html=1", nil))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, `\u003cp\u003e`) || !strings.Contains(body, `\u0026`) || strings.ContainsAny(body, "<>&") {
+		t.Fatalf("expected HTML-escaped output, got %d: %s", rr.Code, body)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" || etag == plainETag || !strings.HasSuffix(etag, encodeHTMLETagSuffix+`"`) {
+		t.Fatalf("expected distinct escaped ETag, got %q (plain %q)", etag, plainETag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss&encode_html=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for escaped ETag, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected escaped ETag not to match default output, got %d", rr.Code)
+	}
+}
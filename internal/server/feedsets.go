@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// feedSetFetchTimeout 为刷新集合时单个 feed 的抓取超时。
+const feedSetFetchTimeout = 15 * time.Second
+
+// ParseFeedSets 解析 FEED_SETS，格式为 name=url1,url2;name2=url3。
+// 集合名只能包含字母、数字、- 与 _，同名集合或空集合视为配置错误。
+func ParseFeedSets(raw string) (map[string][]string, error) {
+	sets := make(map[string][]string)
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validSetName(name) {
+			return nil, fmt.Errorf("invalid feed set %q: expected name=url1,url2", part)
+		}
+		if _, exists := sets[name]; exists {
+			return nil, fmt.Errorf("duplicate feed set %q", name)
+		}
+		var urls []string
+		for _, u := range strings.Split(list, ",") {
+			u = strings.TrimSpace(u)
+			if u == "" {
+				continue
+			}
+			if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				return nil, fmt.Errorf("invalid url %q in feed set %q", u, name)
+			}
+			urls = append(urls, u)
+		}
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("feed set %q has no urls", name)
+		}
+		sets[name] = urls
+	}
+	return sets, nil
+}
+
+func validSetName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// feedSetResult 为某个集合最近一次刷新后序列化好的响应。
+type feedSetResult struct {
+	body      []byte
+	updatedAt time.Time
+}
+
+// feedSets 持有服务端预置的集合及其最近结果。客户端只能按名称读取，不能指定任意 URL；
+// 结果在后台按 FeedSetRefresh 周期刷新，首次请求时同步加载。
+type feedSets struct {
+	opts    Options
+	sets    map[string][]string
+	mu      sync.Mutex
+	results map[string]feedSetResult
+	loading map[string]chan struct{}
+}
+
+func newFeedSets(opts Options) *feedSets {
+	return &feedSets{
+		opts:    opts,
+		sets:    opts.FeedSets,
+		results: make(map[string]feedSetResult),
+		loading: make(map[string]chan struct{}),
+	}
+}
+
+// names 返回按字母排序的集合名。
+func (s *feedSets) names() []string {
+	names := make([]string, 0, len(s.sets))
+	for name := range s.sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// get 返回集合结果，尚未加载时同步刷新；加载不随单个客户端断开而取消。
+func (s *feedSets) get(ctx context.Context, name string) (feedSetResult, error) {
+	s.mu.Lock()
+	result, ok := s.results[name]
+	s.mu.Unlock()
+	if ok {
+		return result, nil
+	}
+	return s.refresh(context.WithoutCancel(ctx), name)
+}
+
+// refresh 重新抓取集合内全部 feed 并替换结果，单个 feed 失败只记录在对应条目中；
+// 同一集合已在刷新时等待其完成并复用结果。
+func (s *feedSets) refresh(ctx context.Context, name string) (feedSetResult, error) {
+	s.mu.Lock()
+	if done, ok := s.loading[name]; ok {
+		s.mu.Unlock()
+		<-done
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if result, ok := s.results[name]; ok {
+			return result, nil
+		}
+		return feedSetResult{}, fmt.Errorf("feed set %q refresh failed", name)
+	}
+	done := make(chan struct{})
+	s.loading[name] = done
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.loading, name)
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	urls := s.sets[name]
+	entries := make([]model.FeedSetEntry, len(urls))
+	convertOpts := resolveConvertOptions(url.Values{}, s.opts)
+	var wg sync.WaitGroup
+	for i, feedURL := range urls {
+		wg.Add(1)
+		go func(i int, feedURL string) {
+			defer wg.Done()
+			fetchCtx, cancel := context.WithTimeout(ctx, feedSetFetchTimeout)
+			defer cancel()
+			entries[i] = model.FeedSetEntry{URL: feedURL, Status: "ok"}
+			resp, err := rss.ConvertWithOptions(fetchCtx, feedURL, convertOpts)
+			if err != nil {
+				_, message := mapError(err, s.opts.ErrorStatusStyle)
+				entries[i].Status = "error"
+				entries[i].Message = message
+				return
+			}
+			entries[i].Feed = resp.Feed
+			entries[i].Items = resp.Items
+		}(i, feedURL)
+	}
+	wg.Wait()
+
+	now := time.Now().UTC()
+	body, err := encodeJSON(model.FeedSet{
+		Status:    "ok",
+		Version:   model.APIVersion,
+		Set:       name,
+		UpdatedAt: now,
+		Feeds:     entries,
+	})
+	if err != nil {
+		return feedSetResult{}, err
+	}
+	result := feedSetResult{body: body, updatedAt: now}
+	s.mu.Lock()
+	s.results[name] = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+// run 按周期在后台刷新全部集合，直到 ctx 结束。
+func (s *feedSets) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, name := range s.names() {
+			if _, err := s.refresh(ctx, name); err != nil {
+				log.Printf("[feedsets] refresh %s failed: %v", name, err)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newFeedSetsHandler 处理 /api/v1/feeds：无 set 参数时列出集合，否则返回指定集合的分组结果。
+func newFeedSetsHandler(sets *feedSets, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.URL.Query().Get("set"))
+		if name == "" {
+			list := model.FeedSetList{Status: "ok", Version: model.APIVersion, Sets: []model.FeedSetInfo{}}
+			for _, n := range sets.names() {
+				list.Sets = append(list.Sets, model.FeedSetInfo{Name: n, URLs: sets.sets[n]})
+			}
+			writeJSON(w, http.StatusOK, list)
+			return
+		}
+		if _, ok := sets.sets[name]; !ok {
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, http.StatusNotFound, errorResponse(codeUnknownFeedSet, "Unknown feed set."))
+			return
+		}
+		result, err := sets.get(r.Context(), name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "Failed to encode response."))
+			return
+		}
+		maxAge := opts.FeedSetRefresh
+		if maxAge <= 0 {
+			maxAge = defaultResponseMaxAge
+		}
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+		w.Header().Set("Last-Modified", result.updatedAt.Format(http.TimeFormat))
+		writeJSONWithETag(w, r, result.body)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+func TestParseFeedSets(t *testing.T) {
+	sets, err := ParseFeedSets(" home = https://a.example.com/rss, https://b.example.com/rss ; tech=https://c.example.com/atom;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sets) != 2 || len(sets["home"]) != 2 || sets["tech"][0] != "https://c.example.com/atom" {
+		t.Fatalf("unexpected sets: %v", sets)
+	}
+
+	for _, raw := range []string{
+		"home",
+		"bad name=https://a.example.com/rss",
+		"home=",
+		"home=ftp://a.example.com/rss",
+		"home=https://a.example.com/rss;home=https://b.example.com/rss",
+	} {
+		if _, err := ParseFeedSets(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestFeedSetsListAndUnknownSet(t *testing.T) {
+	handler := NewHandler(Options{FeedSets: map[string][]string{
+		"home": {"https://a.example.com/rss"},
+		"tech": {"https://c.example.com/rss"},
+	}})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/feeds", nil))
+	var list struct {
+		Sets []struct {
+			Name string   `json:"name"`
+			URLs []string `json:"urls"`
+		} `json:"sets"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Sets) != 2 || list.Sets[0].Name != "home" || list.Sets[1].Name != "tech" {
+		t.Fatalf("unexpected set listing: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/feeds?set=https://evil.example.com/rss", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown set, got %d", rr.Code)
+	}
+}
+
+// hostDoer 对 down.example.com 返回 503，其余主机返回 sampleRSS。
+type hostDoer struct {
+	countingDoer
+}
+
+func (h *hostDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Hostname() == "down.example.com" {
+		h.calls.Add(1)
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	}
+	return h.countingDoer.Do(req)
+}
+
+func TestFeedSetsGroupedResultAndETag(t *testing.T) {
+	doer := &hostDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	handler := NewHandler(Options{FeedSets: map[string][]string{
+		"home": {"https://a.example.com/rss", "https://down.example.com/rss"},
+	}})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/feeds?set=home", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var payload struct {
+		Set   string `json:"set"`
+		Feeds []struct {
+			URL    string            `json:"url"`
+			Status string            `json:"status"`
+			Items  []json.RawMessage `json:"items"`
+		} `json:"feeds"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Set != "home" || len(payload.Feeds) != 2 {
+		t.Fatalf("unexpected grouped result: %s", rr.Body.String())
+	}
+	if payload.Feeds[0].Status != "ok" || len(payload.Feeds[0].Items) != 1 || payload.Feeds[1].Status != "error" {
+		t.Fatalf("expected per-feed status, got %s", rr.Body.String())
+	}
+
+	etag := rr.Header().Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/feeds?set=home", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", rr.Code)
+	}
+	if got := doer.calls.Load(); got != 2 {
+		t.Fatalf("expected cached set result, got %d upstream calls", got)
+	}
+}
+
+func TestFeedSetRefreshStopsOnShutdown(t *testing.T) {
+	doer := &countingDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	shutdown := make(chan struct{})
+	NewHandler(Options{
+		FeedSets:       map[string][]string{"home": {"https://refresh.example.com/rss"}},
+		FeedSetRefresh: 5 * time.Millisecond,
+		Shutdown:       shutdown,
+	})
+	waitFor(t, "background refresh", func() bool { return doer.calls.Load() >= 2 })
+	close(shutdown)
+	time.Sleep(20 * time.Millisecond)
+	stopped := doer.calls.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := doer.calls.Load(); got != stopped {
+		t.Fatalf("expected refresh to stop after shutdown, calls went from %d to %d", stopped, got)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// bigRSS 生成足够大、会触发压缩的 feed。
+func bigRSS() string {
+	return strings.Replace(sampleRSS, "</channel>", strings.Repeat("<item><title>Filler item</title><link>https://example.com/filler</link><description>Lorem ipsum dolor sit amet</description></item>", 40)+"</channel>", 1)
+}
+
+func TestGzipCompressesLargeJSON(t *testing.T) {
+	restore := rss.WithHTTPClient(fakeDoer{body: bigRSS(), status: http.StatusOK})
+	defer restore()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if rr.Header().Get("Content-Encoding") != "gzip" || !strings.Contains(rr.Header().Get("Vary"), "Accept-Encoding") {
+		t.Fatalf("expected gzip headers, got %v", rr.Header())
+	}
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if !strings.Contains(string(plain), `"status":"ok"`) || !strings.Contains(string(plain), "Filler item") {
+		t.Fatalf("unexpected decompressed body: %.200s", plain)
+	}
+}
+
+func TestGzipSkipsSmallBodiesAndNonGzipClients(t *testing.T) {
+	restore := rss.WithHTTPClient(fakeDoer{body: bigRSS(), status: http.StatusOK})
+	defer restore()
+	handler := NewHandler(Options{})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url=https://example.com/rss", nil))
+	if rr.Header().Get("Content-Encoding") != "" || !strings.Contains(rr.Body.String(), `"status":"ok"`) {
+		t.Fatalf("expected identity response without Accept-Encoding, got %v", rr.Header())
+	}
+
+	for _, accept := range []string{"gzip", "gzip;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Accept-Encoding", accept)
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Header().Get("Content-Encoding") != "" || !strings.Contains(rr.Body.String(), `"status":"ok"`) {
+			t.Fatalf("%s: expected small body uncompressed, got %v %q", accept, rr.Header(), rr.Body.String())
+		}
+	}
+}
@@ -1,80 +1,590 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"net"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/zdev0x/rss2json/internal/model"
 	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/internal/version"
+	"github.com/zdev0x/rss2json/model"
+	"golang.org/x/net/http/httpguts"
 )
 
 // serviceStart 记录服务启动时间，用于健康检查输出。
 var serviceStart = time.Now()
 
-// ConvertHandler 处理 /api/v1/rss2json 请求。
+// defaultMaxBytesLimit 为未配置 MAX_BYTES_LIMIT 时 max_bytes 参数允许的最大值。
+const defaultMaxBytesLimit = int64(50 << 20) // 50 MiB
+
+// maxSummaryLen 为 summary_len 参数允许的最大值。
+const maxSummaryLen = 1000
+
+// deniedRequestHeaders 列出不允许由单次请求覆盖的请求头：逐跳头、影响请求分帧或
+// 透明解压的头，以及凭据类头（Authorization 可由 ALLOW_REQUEST_AUTH_HEADER 放开）。
+var deniedRequestHeaders = map[string]bool{
+	"Host":                true,
+	"Content-Length":      true,
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Connection":    true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Accept-Encoding":     true,
+}
+
+// ConvertHandler 处理 /api/v1/rss2json 请求，使用默认选项。
 func ConvertHandler(w http.ResponseWriter, r *http.Request) {
-	// 固定使用查询参数 url。
-	rssURL := r.URL.Query().Get("url")
+	newConvertHandler(Options{})(w, r)
+}
+
+// newConvertHandler 按服务选项构造 /api/v1/rss2json 处理函数。
+func newConvertHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 固定使用查询参数 url。
+		query := r.URL.Query()
+		rssURL := feedURLParam(query)
+		loc, ok := validateConvertQuery(w, query, opts)
+		if !ok {
+			return
+		}
+		convertOpts := resolveConvertOptions(query, opts)
+		convertOpts.Location = loc
+		compat := compatMode(query.Get("compat"))
+		debug := queryEnabled(query.Get("debug_options"))
+		if urls := query["url"]; len(urls) > 1 {
+			if query.Get("format") == "xml" {
+				writeBadRequest(w, "format=xml supports a single url only.")
+				return
+			}
+			if compat {
+				writeBadRequest(w, "compat=rss2json supports a single url only.")
+				return
+			}
+			if convertOpts.Cursor != nil {
+				writeBadRequest(w, "cursor supports a single url only.")
+				return
+			}
+			writeMultiConvert(w, r, urls, convertOpts, opts, debug)
+			return
+		}
 
-	resp, err := rss.Convert(r.Context(), rssURL)
+		resp, err := rss.ConvertWithOptions(r.Context(), rssURL, convertOpts)
+		if clientGone(r, err) {
+			return
+		}
+		if err != nil {
+			writeError(w, err, opts.ErrorStatusStyle, debug)
+			return
+		}
+		setUpstreamTime(w, resp)
+		if debug {
+			resp.Meta.Options = effectiveOptions(convertOpts)
+		}
+
+		if query.Get("format") == "xml" {
+			writeRSS(w, resp)
+			return
+		}
+		if compat {
+			writeRSS2JSONCompat(w, r, resp, opts.ResponseMaxAge)
+			return
+		}
+		writeCacheableJSON(w, r, resp, opts.ResponseMaxAge)
+	}
+}
+
+// validateConvertQuery 校验 resolveConvertOptions 会静默忽略的取值（tz、prefer、compat、
+// content_format、cursor），不合法时输出 400 并返回 false；合法时返回 tz 对应的时区。
+// 同步转换、异步回调与 /api/v1/debug 共用，保证同一请求在各入口得到相同的校验结果。
+func validateConvertQuery(w http.ResponseWriter, query url.Values, opts Options) (*time.Location, bool) {
+	loc, err := requestLocation(query.Get("tz"))
 	if err != nil {
-		status, message := mapError(err)
-		writeJSON(w, status, model.Response{
-			Status:  "error",
-			Version: model.APIVersion,
-			Message: message,
-		})
+		writeBadRequest(w, "Invalid tz. Use an IANA time zone name such as Asia/Shanghai.")
+		return nil, false
+	}
+	if _, ok := requestPrefer(query.Get("prefer")); !ok {
+		writeBadRequest(w, "Invalid prefer. Use content or description.")
+		return nil, false
+	}
+	if _, ok := requestFieldMap(query.Get("compat"), opts.FieldMap); !ok {
+		writeBadRequest(w, "Invalid compat. Use rss2json, pubdate or none.")
+		return nil, false
+	}
+	if _, ok := requestContentFormat(query.Get("content_format")); !ok {
+		writeBadRequest(w, "Invalid content_format. Use html or markdown.")
+		return nil, false
+	}
+	if _, err := model.ParseCursor(query.Get("cursor")); err != nil {
+		writeBadRequest(w, "Invalid cursor. Pass meta.cursor from a previous response unchanged.")
+		return nil, false
+	}
+	return loc, true
+}
+
+// effectiveOptions 返回 debug_options=1 时附带在 meta.options 中的实际生效选项。
+func effectiveOptions(convertOpts rss.Options) *model.EffectiveOptions {
+	return &model.EffectiveOptions{
+		Count:             convertOpts.Count,
+		Sanitize:          convertOpts.Sanitize,
+		StripTracking:     convertOpts.StripTracking,
+		MinifyHTML:        convertOpts.MinifyHTML,
+		OmitContent:       convertOpts.OmitContent,
+		OmitFeed:          convertOpts.OmitFeed,
+		SummaryLen:        convertOpts.SummaryLen,
+		RawDC:             convertOpts.RawDC,
+		Prefer:            convertOpts.Prefer,
+		FieldMap:          convertOpts.FieldMap,
+		ContentFormat:     convertOpts.ContentFormat,
+		ResolveShortlinks: convertOpts.ResolveShortlinks,
+		MaxBytes:          convertOpts.MaxBytes,
+		Insecure:          convertOpts.Insecure,
+	}
+}
+
+// resolveConvertOptions 依次应用服务端默认值、客户端查询参数与服务端上限/强制项，
+// 得到本次转换实际生效的选项。
+func resolveConvertOptions(query url.Values, opts Options) rss.Options {
+	convertOpts := rss.Options{
+		Username: query.Get("feed_user"),
+		Password: query.Get("feed_pass"),
+		Count:    opts.DefaultCount,
+		Cache:    opts.Cache,
+		CacheTTL: opts.runtime().CacheTTL,
+
+		DisableDataURLs: opts.DisableDataURLs,
+	}
+	if opts.AllowRequestHeaders {
+		convertOpts.UserAgent = query.Get("user_agent")
+		convertOpts.Headers = requestHeaderOverrides(query["header"], opts.AllowRequestAuthHeader)
+	}
+
+	if count, err := strconv.Atoi(strings.TrimSpace(query.Get("count"))); err == nil && count > 0 {
+		convertOpts.Count = count
+	}
+	convertOpts.Sanitize = queryEnabled(query.Get("sanitize"))
+	convertOpts.StripTracking = queryEnabled(query.Get("strip_tracking")) || queryEnabled(query.Get("clean_urls"))
+	convertOpts.MinifyHTML = queryEnabled(query.Get("minify_html"))
+	convertOpts.OmitContent = queryDisabled(query.Get("include_content"))
+	convertOpts.OmitFeed = queryDisabled(query.Get("feed"))
+	convertOpts.SummaryLen = requestSummaryLen(query.Get("summary_len"))
+	convertOpts.RawDC = queryEnabled(query.Get("raw_dc"))
+	convertOpts.Prefer, _ = requestPrefer(query.Get("prefer"))
+	convertOpts.FieldMap, _ = requestFieldMap(query.Get("compat"), opts.FieldMap)
+	convertOpts.ContentFormat, _ = requestContentFormat(query.Get("content_format"))
+	convertOpts.Cursor, _ = model.ParseCursor(query.Get("cursor"))
+	convertOpts.ResolveShortlinks = queryEnabled(query.Get("resolve_shortlinks"))
+
+	if opts.AllowRequestMaxBytes {
+		convertOpts.MaxBytes = requestMaxBytes(query.Get("max_bytes"), opts.MaxBytesLimit)
+	}
+	if opts.AllowRequestInsecure {
+		convertOpts.Insecure = queryEnabled(query.Get("insecure"))
+	}
+
+	if opts.MaxCount > 0 && (convertOpts.Count <= 0 || convertOpts.Count > opts.MaxCount) {
+		convertOpts.Count = opts.MaxCount
+	}
+	if opts.ForceSanitize {
+		convertOpts.Sanitize = true
+	}
+	if opts.ForceStripTracking {
+		convertOpts.StripTracking = true
+	}
+	return convertOpts
+}
+
+// feedURLParam 返回本次请求的 feed 地址：未传 url 而传了 xml_b64 时，
+// 将 base64 编码的 feed 内容转为 data: URI，与 url=data:... 走同一条解析路径。
+func feedURLParam(query url.Values) string {
+	if rssURL := query.Get("url"); rssURL != "" {
+		return rssURL
+	}
+	if raw := strings.TrimSpace(query.Get("xml_b64")); raw != "" {
+		return "data:application/xml;base64," + raw
+	}
+	return ""
+}
+
+// requestLocation 解析 tz 参数（IANA 时区名），为空时返回 UTC。
+func requestLocation(raw string) (*time.Location, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(raw)
+}
+
+// requestMaxBytes 解析 max_bytes 参数并截断到服务端上限，不合法时返回 0（使用全局配置）。
+func requestMaxBytes(raw string, limit int64) int64 {
+	val, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || val <= 0 {
+		return 0
+	}
+	if limit <= 0 {
+		limit = defaultMaxBytesLimit
+	}
+	if val > limit {
+		return limit
+	}
+	return val
+}
+
+// requestSummaryLen 解析 summary_len 参数并截断到 maxSummaryLen，不合法时返回 0（使用默认长度）。
+func requestSummaryLen(raw string) int {
+	val, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || val <= 0 {
+		return 0
+	}
+	return min(val, maxSummaryLen)
+}
+
+// requestPrefer 解析 prefer 参数，未传时返回空字符串；取值不是 content/description 时返回 false。
+func requestPrefer(raw string) (string, bool) {
+	switch val := strings.ToLower(strings.TrimSpace(raw)); val {
+	case "":
+		return "", true
+	case model.PreferContent, model.PreferDescription:
+		return val, true
+	default:
+		return "", false
+	}
+}
+
+// requestContentFormat 解析 content_format 参数，未传或为 html 时返回空字符串（保持 HTML）；
+// 取值不是 html/markdown 时返回 false。
+func requestContentFormat(raw string) (string, bool) {
+	switch val := strings.ToLower(strings.TrimSpace(raw)); val {
+	case "", model.ContentFormatHTML:
+		return "", true
+	case model.ContentFormatMarkdown:
+		return val, true
+	default:
+		return "", false
+	}
+}
+
+// compatRSS2JSON 为 compat 参数中选择 rss2json.com 完整响应结构的取值。
+const compatRSS2JSON = "rss2json"
+
+// compatMode 判断 compat 参数是否要求输出 rss2json.com 完整结构。
+func compatMode(raw string) bool {
+	return strings.EqualFold(strings.TrimSpace(raw), compatRSS2JSON)
+}
+
+// requestFieldMap 解析 compat 参数：未传时使用服务端默认映射（FIELD_MAP），none 与 rss2json
+// （改用 rss2json.com 完整结构输出）不做重命名，其余取值须为预置映射名，否则返回 false。
+func requestFieldMap(raw string, def model.FieldMap) (model.FieldMap, bool) {
+	switch val := strings.ToLower(strings.TrimSpace(raw)); val {
+	case "":
+		return def, true
+	case "none", compatRSS2JSON:
+		return nil, true
+	default:
+		return model.FieldMapPreset(val)
+	}
+}
+
+// queryEnabled 判断布尔型查询参数是否开启，支持 1/true/on。
+func queryEnabled(val string) bool {
+	val = strings.ToLower(strings.TrimSpace(val))
+	return val == "1" || val == "true" || val == "on"
+}
+
+// queryDisabled 判断布尔型查询参数是否显式关闭，支持 0/false/off，未传时为 false。
+func queryDisabled(val string) bool {
+	val = strings.ToLower(strings.TrimSpace(val))
+	return val == "0" || val == "false" || val == "off"
+}
+
+// ValidateHandler 处理 /api/v1/validate 请求，仅校验 feed 是否可解析。
+func ValidateHandler(w http.ResponseWriter, r *http.Request) {
+	newValidateHandler(Options{})(w, r)
+}
+
+// newValidateHandler 按服务选项构造 /api/v1/validate 处理函数。
+func newValidateHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		validateOpts := rss.Options{
+			Username: query.Get("feed_user"),
+			Password: query.Get("feed_pass"),
+
+			DisableDataURLs: opts.DisableDataURLs,
+		}
+
+		result, err := rss.Validate(r.Context(), feedURLParam(query), validateOpts)
+		if err != nil {
+			writeError(w, err, opts.ErrorStatusStyle, queryEnabled(query.Get("debug_options")))
+			return
+		}
+
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// requestHeaderOverrides 解析重复的 header=Name:Value 参数，仅在第一个冒号处分割，
+// 以便 Referer 等值中包含 URL。名称、值不合法或位于黑名单中的请求头会被忽略。
+func requestHeaderOverrides(values []string, allowAuth bool) http.Header {
+	if len(values) == 0 {
+		return nil
+	}
+	headers := make(http.Header)
+	for _, raw := range values {
+		name, value, ok := strings.Cut(raw, ":")
+		if !ok {
+			continue
+		}
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if name == "" || !validHeaderName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			continue
+		}
+		if deniedRequestHeaders[name] && !(allowAuth && name == "Authorization") {
+			continue
+		}
+		headers.Add(name, value)
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// validHeaderName 检查请求头名称仅包含 token 字符。
+func validHeaderName(name string) bool {
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// upstreamTimeHeader 为成功响应中给出上游抓取与解析总耗时（毫秒）的响应头。
+const upstreamTimeHeader = "X-Upstream-Time"
+
+// setUpstreamTime 写出 X-Upstream-Time，便于在不解析响应体的情况下区分慢 feed 与服务自身的耗时。
+func setUpstreamTime(w http.ResponseWriter, resp model.Response) {
+	if resp.Meta == nil {
 		return
 	}
+	w.Header().Set(upstreamTimeHeader, strconv.FormatFloat(resp.Meta.FetchMs+resp.Meta.ParseMs, 'f', 3, 64))
+}
 
-	writeJSON(w, http.StatusOK, resp)
+// clientGone 判断错误是否因客户端断开导致，此时不再写响应，访问日志记为 client-aborted。
+func clientGone(r *http.Request, err error) bool {
+	return errors.Is(err, context.Canceled) && errors.Is(r.Context().Err(), context.Canceled)
 }
 
-func mapError(err error) (int, string) {
-	if rss.IsInvalidInput(err) {
-		// 情况 1: 输入参数缺失（422 是非常好的选择）
-		return http.StatusUnprocessableEntity, "Missing rss url."
+// writeError 将转换错误映射为统一错误响应，必要时附带 Retry-After；错误响应不允许缓存。
+// debug 为 true 时附带解析失败的响应体片段。
+func writeError(w http.ResponseWriter, err error, style string, debug bool) {
+	status, resp := errorBody(err, style, debug)
+	w.Header().Set("Cache-Control", "no-store")
+	if retryAfter := rss.RetryAfter(err); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 	}
+	writeJSON(w, status, resp)
+}
 
-	if isTimeout(err) {
-		// 情况 2: 抓取超时
-		// 建议：改用 408 (Request Timeout) 或直接用 400
-		// 这样 Cloudflare 会认为这是业务超时，而不是你的服务器宕机
-		return http.StatusRequestTimeout, "RSS fetch timeout. The target server responded too slowly."
+// errorBody 构造抓取错误对应的状态码与响应体，debug 时附带解析失败的响应体片段。
+func errorBody(err error, style string, debug bool) (int, model.Response) {
+	status, message := mapError(err, style)
+	info := &model.ErrorInfo{Code: string(rss.ErrorCodeOf(err))}
+	if upstream := rss.UpstreamStatus(err); upstream > 0 {
+		info.Details = &model.ErrorDetails{UpstreamStatus: upstream}
+	}
+	if snippet := rss.ErrorSnippet(err); debug && snippet != "" {
+		if info.Details == nil {
+			info.Details = &model.ErrorDetails{}
+		}
+		info.Details.BodySnippet = snippet
+	}
+	if retryAfter := rss.RetryAfter(err); retryAfter > 0 {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if rss.IsCachedFailure(err) {
+			message += fmt.Sprintf(" (cached failure, upstream will be retried in %ds)", seconds)
+		}
+		if info.Details == nil {
+			info.Details = &model.ErrorDetails{}
+		}
+		info.Details.RetryAfter = seconds
 	}
+	info.Message = message
+	return status, model.Response{
+		Status:  "error",
+		Version: model.APIVersion,
+		Message: message,
+		Error:   info,
+	}
+}
 
-	// 情况 3: 无法下载、DNS 解析失败、404 等
-	// 原代码返回 StatusBadGateway (502)，这是导致 Cloudflare 报错的罪魁祸首
-	// 建议：改用 400 Bad Request 或 422
-	return http.StatusBadRequest, "Cannot download this RSS feed. Please check if the URL is valid and accessible."
+// 服务端自身产生的错误码，与 rss 包中的抓取错误码一起构成 error.code 的取值。
+const (
+	codeInvalidParameter   = "invalid_parameter"
+	codeUnknownFeedSet     = "unknown_feed_set"
+	codeUnauthorized       = "unauthorized"
+	codeAdminDisabled      = "admin_disabled"
+	codeMethodNotAllowed   = "method_not_allowed"
+	codeCallbackNotAllowed = "callback_not_allowed"
+	codeTooManyStreams     = "too_many_streams"
+	codeOverloaded         = "overloaded"
+	codeInvalidConfig      = "invalid_config"
+	codeBodyTooLarge       = "body_too_large"
+	codeNotFound           = "not_found"
+	codeInternal           = "internal_error"
+)
+
+// errorResponse 构造带错误码的错误响应，message 同时写入顶层 message 字段。
+func errorResponse(code, message string) model.Response {
+	return model.Response{
+		Status:  "error",
+		Version: model.APIVersion,
+		Message: message,
+		Error:   &model.ErrorInfo{Code: code, Message: message},
+	}
+}
+
+// writeBadRequest 输出参数不合法的 400 错误。
+func writeBadRequest(w http.ResponseWriter, message string) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusBadRequest, errorResponse(codeInvalidParameter, message))
+}
+
+// ERROR_STATUS_STYLE 的取值：strict 为默认映射；legacy 沿用早期版本的状态码，
+// 抓取失败一律返回 4xx（内容无法解析为 422，与下载失败的 400 区分），供部署在 Cloudflare 等会拦截 5xx 的代理之后的用户使用。
+const (
+	ErrorStatusStrict = "strict"
+	ErrorStatusLegacy = "legacy"
+)
+
+// errorMapping 为错误码对应的 HTTP 状态码与提示信息，legacy 为 ERROR_STATUS_STYLE=legacy 时的状态码。
+type errorMapping struct {
+	status  int
+	legacy  int
+	message string
 }
 
-func isTimeout(err error) bool {
-	if errors.Is(err, context.DeadlineExceeded) {
-		return true
+// errorMappings 按错误码映射 HTTP 状态（strict）：
+// 请求参数问题 400，url 超长或含空白、控制字符 422；域名无法解析、上游不可达、返回错误或内容无法解析 422；抓取超时 504；
+// 上游限流 503 并转发其 Retry-After；502 仅用于出站代理本身故障。
+var errorMappings = map[rss.ErrorCode]errorMapping{
+	rss.CodeMissingURL:          {http.StatusBadRequest, http.StatusUnprocessableEntity, "Missing rss url."},
+	rss.CodeInvalidURL:          {http.StatusBadRequest, http.StatusUnprocessableEntity, "Invalid rss url. Only absolute http(s) URLs are supported."},
+	rss.CodeMalformedURL:        {http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, "Malformed rss url. It is too long or contains whitespace or control characters."},
+	rss.CodeRateLimited:         {http.StatusTooManyRequests, http.StatusTooManyRequests, "Too many requests to this feed host. Please try again later."},
+	rss.CodeRobotsDisallowed:    {http.StatusForbidden, http.StatusForbidden, "This RSS feed is blocked by robots.txt of the target site."},
+	rss.CodeFetchTimeout:        {http.StatusGatewayTimeout, http.StatusRequestTimeout, "RSS fetch timeout. The target server responded too slowly."},
+	rss.CodeFetchFailed:         {http.StatusUnprocessableEntity, http.StatusBadRequest, "Cannot download this RSS feed. Please check if the URL is valid and accessible."},
+	rss.CodeDNSError:            {http.StatusUnprocessableEntity, http.StatusBadRequest, "The feed hostname could not be resolved. Please check the domain name."},
+	rss.CodeUpstreamStatus:      {http.StatusUnprocessableEntity, http.StatusBadRequest, "Cannot download this RSS feed. The target server returned an error status."},
+	rss.CodeUpstreamRateLimited: {http.StatusServiceUnavailable, http.StatusTooManyRequests, "The feed host is rate limiting requests. Please retry after the indicated delay."},
+	rss.CodeParseFailed:         {http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, "Downloaded the URL but it is not a valid RSS/Atom/JSON feed."},
+	rss.CodeHTMLPage:            {http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, "URL returned an HTML page, not a feed."},
+	rss.CodeTooLarge:            {http.StatusUnprocessableEntity, http.StatusBadRequest, "This RSS feed exceeds the size limit."},
+	rss.CodeProxyFailed:         {http.StatusBadGateway, http.StatusBadRequest, "The outbound proxy is unavailable."},
+}
+
+// mapError 按错误码与状态码风格将错误映射为 HTTP 状态码与提示信息，未知错误码按下载失败处理。
+func mapError(err error, style string) (int, string) {
+	return mapErrorCode(rss.ErrorCodeOf(err), style)
+}
+
+// mapErrorCode 按错误码返回 HTTP 状态与提示信息，未登记的错误码按抓取失败处理。
+func mapErrorCode(code rss.ErrorCode, style string) (int, string) {
+	mapping, ok := errorMappings[code]
+	if !ok {
+		mapping = errorMappings[rss.CodeFetchFailed]
 	}
-	var netErr net.Error
-	return errors.As(err, &netErr) && netErr.Timeout()
+	if style == ErrorStatusLegacy {
+		return mapping.legacy, mapping.message
+	}
+	return mapping.status, mapping.message
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
+	body, _ := encodeJSON(payload)
+	_, _ = w.Write(body)
+}
+
+// writeRSS 以 RSS 2.0 输出规范化后的 feed，供 format=xml 使用。
+func writeRSS(w http.ResponseWriter, resp model.Response) {
+	body, err := rss.RenderRSS(resp)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "Failed to encode response."))
+		return
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// encodeJSON 序列化响应体，与 writeJSON 输出一致，供计算 ETag 使用。
+func encodeJSON(payload interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
 	enc.SetEscapeHTML(false) // 保留 HTML 字符，避免被转义为 \u003c 之类的形式。
-	_ = enc.Encode(payload)
+	if err := enc.Encode(payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
+// canaryTimeout 定义深度健康检查拉取 canary feed 的超时时间。
+const canaryTimeout = 3 * time.Second
+
 // 健康检查就接口
 func HealthHandler(w http.ResponseWriter, r *http.Request) {
-	_ = r
+	newHealthHandler(Options{})(w, r)
+}
+
+// newHealthHandler 构造健康检查处理函数；deep=1 且配置了 canary 时额外探测出站网络。
+func newHealthHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]interface{}{
+			"status": "ok",
+			"uptime": time.Since(serviceStart).Seconds(),
+		}
+		canary := strings.TrimSpace(opts.HealthCanaryURL)
+		if canary == "" || r.URL.Query().Get("deep") != "1" {
+			writeJSON(w, http.StatusOK, payload)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), canaryTimeout)
+		defer cancel()
+		if _, err := rss.Convert(ctx, canary); err != nil {
+			payload["status"] = "fail"
+			payload["upstream"] = "fail"
+			writeJSON(w, http.StatusServiceUnavailable, payload)
+			return
+		}
+		payload["upstream"] = "ok"
+		writeJSON(w, http.StatusOK, payload)
+	}
+}
+
+// VersionHandler 处理 /version 请求，返回服务版本与抓取上游时默认使用的 User-Agent。
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"status": "ok",
-		"uptime": time.Since(serviceStart).Seconds(),
+		"status":      "ok",
+		"version":     version.Version,
+		"api_version": model.APIVersion,
+		"user_agent":  rss.DefaultUserAgent(),
 	})
-
 }
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/zdev0x/rss2json/internal/version"
+	"github.com/zdev0x/rss2json/model"
+)
+
+// landingEndpoints 为根路径列出的主要接口，键为路径，值为简要说明。
+var landingEndpoints = map[string]string{
+	"/api/v1/rss2json":     "convert a feed to JSON (url=...)",
+	"/api/v1/validate":     "validate a feed without converting",
+	"/api/v1/stream":       "server-sent events for feed updates",
+	"/api/v1/feeds":        "merged items of a preconfigured feed set",
+	"/api/v1/openapi.json": "OpenAPI 3 description",
+	"/health":              "liveness check",
+	"/health/ready":        "readiness check",
+	"/version":             "service version",
+	"/metrics":             "Prometheus metrics",
+}
+
+// landingHandler 处理根路径请求，返回服务名称、版本与主要接口列表，便于发现 API。
+func landingHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "ok",
+		"name":        "rss2json",
+		"version":     version.Version,
+		"api_version": model.APIVersion,
+		"docs":        "/api/v1/openapi.json",
+		"endpoints":   landingEndpoints,
+	})
+}
+
+// faviconHandler 以 204 响应 /favicon.ico，避免浏览器访问时产生 404 日志，并允许长期缓存。
+func faviconHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusNoContent)
+}
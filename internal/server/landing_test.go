@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/version"
+)
+
+func TestLandingDocument(t *testing.T) {
+	handler := NewHandler(Options{})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var doc struct {
+		Name      string            `json:"name"`
+		Version   string            `json:"version"`
+		Endpoints map[string]string `json:"endpoints"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Name != "rss2json" || doc.Version != version.Version {
+		t.Fatalf("unexpected landing doc %s", rr.Body.String())
+	}
+	if _, ok := doc.Endpoints["/api/v1/rss2json"]; !ok {
+		t.Fatalf("expected convert endpoint to be listed, got %v", doc.Endpoints)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST /, got %d", rr.Code)
+	}
+}
+
+func TestFavicon(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if rr.Code != http.StatusNoContent || rr.Body.Len() != 0 {
+		t.Fatalf("expected empty 204, got %d %q", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Cache-Control") == "" {
+		t.Fatal("expected favicon response to be cacheable")
+	}
+}
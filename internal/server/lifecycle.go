@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// State 为服务生命周期状态，只能按 starting → ready → draining 单向推进。
+type State string
+
+const (
+	// StateStarting 表示正在加载配置、构建缓存，尚未开始服务。
+	StateStarting State = "starting"
+	// StateReady 表示已开始监听并可以接收流量。
+	StateReady State = "ready"
+	// StateDraining 表示收到退出信号，就绪检查失败但仍处理进行中与新到达的请求。
+	StateDraining State = "draining"
+)
+
+// stateOrder 用于保证状态只能前进。
+var stateOrder = map[State]int{StateStarting: 0, StateReady: 1, StateDraining: 2}
+
+// Lifecycle 记录服务生命周期状态，由 main 在启动、监听与收到信号时推进，
+// 通过 /health/ready 暴露给编排系统。
+type Lifecycle struct {
+	mu       sync.Mutex
+	state    State
+	onChange func(from, to State)
+}
+
+// NewLifecycle 返回处于 starting 状态的 Lifecycle；onChange 非空时在每次状态变化后调用。
+func NewLifecycle(onChange func(from, to State)) *Lifecycle {
+	return &Lifecycle{state: StateStarting, onChange: onChange}
+}
+
+// State 返回当前状态。
+func (l *Lifecycle) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// SetReady 将状态推进到 ready，已处于 ready 或 draining 时不变。
+func (l *Lifecycle) SetReady() bool {
+	return l.advance(StateReady)
+}
+
+// Drain 将状态推进到 draining，等待 delay 让负载均衡摘除本实例后调用 shutdown。
+// 等待期间就绪检查返回 503，但请求照常处理，避免退出信号之后仍有流量到达的竞争。
+func (l *Lifecycle) Drain(delay time.Duration, shutdown func() error) error {
+	l.advance(StateDraining)
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return shutdown()
+}
+
+// advance 仅在目标状态晚于当前状态时切换，返回是否发生了变化。
+func (l *Lifecycle) advance(to State) bool {
+	l.mu.Lock()
+	from := l.state
+	if stateOrder[to] <= stateOrder[from] {
+		l.mu.Unlock()
+		return false
+	}
+	l.state = to
+	l.mu.Unlock()
+	if l.onChange != nil {
+		l.onChange(from, to)
+	}
+	return true
+}
+
+// newReadyHandler 处理 /health/ready：仅在 ready 状态返回 200，starting 与 draining 返回 503。
+// 未配置 Lifecycle 时视为始终就绪。
+func newReadyHandler(lifecycle *Lifecycle) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state := StateReady
+		if lifecycle != nil {
+			state = lifecycle.State()
+		}
+		if state != StateReady {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "fail", "state": state})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "state": state})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLifecycleTransitions(t *testing.T) {
+	var changes []string
+	lc := NewLifecycle(func(from, to State) { changes = append(changes, string(from)+"->"+string(to)) })
+	if lc.State() != StateStarting {
+		t.Fatalf("expected starting, got %s", lc.State())
+	}
+	if !lc.SetReady() || lc.SetReady() {
+		t.Fatal("expected exactly one transition to ready")
+	}
+	called := false
+	if err := lc.Drain(0, func() error { called = true; return nil }); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if !called || lc.State() != StateDraining {
+		t.Fatalf("expected shutdown after draining, called=%v state=%s", called, lc.State())
+	}
+	if lc.SetReady() {
+		t.Fatal("expected draining to be final")
+	}
+	if len(changes) != 2 || changes[0] != "starting->ready" || changes[1] != "ready->draining" {
+		t.Fatalf("unexpected transitions %v", changes)
+	}
+}
+
+func readyState(t *testing.T, baseURL string) (int, State) {
+	t.Helper()
+	resp, err := http.Get(baseURL + "/health/ready")
+	if err != nil {
+		t.Fatalf("ready request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var payload struct {
+		State State `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	return resp.StatusCode, payload.State
+}
+
+func TestReadyEndpointDrainsBeforeShutdown(t *testing.T) {
+	lc := NewLifecycle(nil)
+	srv := httptest.NewServer(NewHandler(Options{Lifecycle: lc}))
+	defer srv.Close()
+
+	if code, state := readyState(t, srv.URL); code != http.StatusServiceUnavailable || state != StateStarting {
+		t.Fatalf("expected 503 starting, got %d %s", code, state)
+	}
+	lc.SetReady()
+	if code, state := readyState(t, srv.URL); code != http.StatusOK || state != StateReady {
+		t.Fatalf("expected 200 ready, got %d %s", code, state)
+	}
+
+	// 模拟 main 收到 SIGTERM 后的处理：先进入 draining，延迟结束后再关闭服务。
+	shutdownCalled := make(chan struct{})
+	drained := make(chan error, 1)
+	go func() {
+		drained <- lc.Drain(200*time.Millisecond, func() error {
+			close(shutdownCalled)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			return srv.Config.Shutdown(ctx)
+		})
+	}()
+	deadline := time.Now().Add(time.Second)
+	for lc.State() != StateDraining && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if code, state := readyState(t, srv.URL); code != http.StatusServiceUnavailable || state != StateDraining {
+		t.Fatalf("expected 503 draining, got %d %s", code, state)
+	}
+	resp, err := http.Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatalf("expected requests to be served while draining: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 while draining, got %d", resp.StatusCode)
+	}
+	select {
+	case <-shutdownCalled:
+		t.Fatal("shutdown ran before the drain delay elapsed")
+	default:
+	}
+
+	if err := <-drained; err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if _, err := http.Get(srv.URL + "/health"); err == nil {
+		t.Fatal("expected server to stop accepting requests after shutdown")
+	}
+}
+
+func TestReadyEndpointWithoutLifecycle(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 without lifecycle, got %d", rr.Code)
+	}
+}
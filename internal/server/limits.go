@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxBodyBytes 为未配置 MAX_BODY_BYTES 时请求体的大小上限。
+	defaultMaxBodyBytes = int64(1 << 20) // 1 MiB
+	// defaultReadHeaderTimeout 为未配置 READ_HEADER_TIMEOUT 时读取请求头的超时，避免慢速发送请求头的客户端长期占用连接。
+	defaultReadHeaderTimeout = 10 * time.Second
+	// defaultReadTimeout 为未配置 READ_TIMEOUT 时读取整个请求（含请求体）的超时。
+	defaultReadTimeout = 30 * time.Second
+	// defaultIdleTimeout 为 keep-alive 连接的空闲超时。
+	defaultIdleTimeout = 120 * time.Second
+	// defaultMaxHeaderBytes 为未配置 MAX_HEADER_BYTES 时请求头的总大小上限，超过时返回 431。
+	defaultMaxHeaderBytes = 64 << 10
+)
+
+// NewServer 按选项构造 http.Server，统一设置请求头、请求体的读取超时与大小上限。
+// 不设置 WriteTimeout，以免中断 SSE 等长连接响应。
+func NewServer(opts Options) *http.Server {
+	return NewLimitedServer(NewHandler(opts), opts)
+}
+
+// NewLimitedServer 以与 NewServer 相同的超时与请求头上限构造承载 handler 的 http.Server，
+// 供 HTTP 跳转 HTTPS 等附属监听使用。
+func NewLimitedServer(handler http.Handler, opts Options) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: durationOr(opts.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       durationOr(opts.ReadTimeout, defaultReadTimeout),
+		IdleTimeout:       defaultIdleTimeout,
+		MaxHeaderBytes:    intOr(opts.MaxHeaderBytes, defaultMaxHeaderBytes),
+	}
+}
+
+func intOr(val, fallback int) int {
+	if val > 0 {
+		return val
+	}
+	return fallback
+}
+
+func durationOr(val, fallback time.Duration) time.Duration {
+	if val > 0 {
+		return val
+	}
+	return fallback
+}
+
+// withBodyLimit 以 http.MaxBytesReader 限制所有请求体的大小，超限后的读取返回 *http.MaxBytesError。
+func withBodyLimit(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readLimitedBody 读取受 withBodyLimit 限制的请求体，超限时输出 413 JSON 错误并返回 false；
+// POST 接口即使不使用请求体也应调用，以拒绝超大请求。
+func readLimitedBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Body == nil {
+		return nil, true
+	}
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		return body, true
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse(codeBodyTooLarge, "Request body exceeds the limit of "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes."))
+		return nil, false
+	}
+	writeBadRequest(w, "Failed to read request body.")
+	return nil, false
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestOversizedBodyRejected(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key", MaxBodyBytes: 16})
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", strings.NewReader(strings.Repeat("x", 64)))
+	req.Header.Set("Authorization", "Bearer admin-key")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp model.Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if resp.Status != "error" || resp.Error == nil || resp.Error.Code != codeBodyTooLarge {
+		t.Fatalf("expected body_too_large error, got %+v", resp)
+	}
+	if !strings.Contains(resp.Error.Message, "16 bytes") {
+		t.Fatalf("expected limit in message, got %q", resp.Error.Message)
+	}
+
+	small := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", strings.NewReader("{}"))
+	small.Header.Set("Authorization", "Bearer admin-key")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, small)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected small body to pass, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestOversizedHeadersRejected(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config = NewServer(Options{MaxHeaderBytes: 4 << 10})
+	srv.Start()
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		req.Header.Add("X-Filler", strings.Repeat("a", 100))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatalf("expected 431, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewServerDefaults(t *testing.T) {
+	srv := NewServer(Options{})
+	if srv.ReadHeaderTimeout != defaultReadHeaderTimeout || srv.ReadTimeout != defaultReadTimeout {
+		t.Fatalf("unexpected timeouts: header=%v read=%v", srv.ReadHeaderTimeout, srv.ReadTimeout)
+	}
+	if srv.WriteTimeout != 0 {
+		t.Fatalf("expected no write timeout for streams, got %v", srv.WriteTimeout)
+	}
+	if srv.MaxHeaderBytes != defaultMaxHeaderBytes {
+		t.Fatalf("unexpected header limit %d", srv.MaxHeaderBytes)
+	}
+
+	limited := NewLimitedServer(http.NotFoundHandler(), Options{ReadHeaderTimeout: time.Second, ReadTimeout: 2 * time.Second, MaxHeaderBytes: 1 << 10})
+	if limited.ReadHeaderTimeout != time.Second || limited.ReadTimeout != 2*time.Second || limited.MaxHeaderBytes != 1<<10 || limited.IdleTimeout != defaultIdleTimeout {
+		t.Fatalf("expected the configured limits on the limited server, got %+v", limited)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// readOnly 包装只读接口：仅允许 GET 与 HEAD，HEAD 请求照常处理但不输出响应体。
+func readOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			next(w, r)
+		case http.MethodHead:
+			next(headResponseWriter{w}, r)
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodHead)
+		}
+	}
+}
+
+// writeMethodNotAllowed 输出带 Allow 头的 405 JSON 错误。
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse(codeMethodNotAllowed, "Method not allowed."))
+}
+
+// notFoundHandler 为未注册路径返回统一的 JSON 404，替代默认的纯文本页面。
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusNotFound, errorResponse(codeNotFound, "Not found."))
+}
+
+// headResponseWriter 保留状态码与响应头，丢弃响应体。
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (h headResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// newMetricsHandler 处理 /metrics，以 Prometheus 文本格式输出上游内容缓存的命中、未命中计数、
+// 出站连接的新建与复用次数，以及缓存当前条目数；未启用缓存或后端不支持统计（如 Redis）时不输出条目数。
+// 启用准入控制（MAX_ACTIVE）时另输出处理中与排队中的请求数及拒绝次数。
+func newMetricsHandler(opts Options, adm *admission) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hits, misses := rss.CacheStats()
+		var b strings.Builder
+		writeMetric(&b, "rss2json_cache_hits_total", "counter", "Upstream cache lookups served from the cache.", hits)
+		writeMetric(&b, "rss2json_cache_misses_total", "counter", "Upstream cache lookups that required a fetch.", misses)
+		created, reused := rss.ConnStats()
+		writeMetric(&b, "rss2json_upstream_connections_created_total", "counter", "Upstream requests that opened a new connection.", created)
+		writeMetric(&b, "rss2json_upstream_connections_reused_total", "counter", "Upstream requests that reused an idle pooled connection.", reused)
+		if opts.Cache != nil {
+			if size, ok := cache.Len(opts.Cache); ok {
+				writeMetric(&b, "rss2json_cache_entries", "gauge", "Entries currently held by the upstream cache.", int64(size))
+			}
+		}
+		if adm != nil {
+			active, queued, rejected, timeouts := adm.stats()
+			writeMetric(&b, "rss2json_queue_active", "gauge", "Requests currently being served under admission control.", int64(active))
+			writeMetric(&b, "rss2json_queue_depth", "gauge", "Requests currently waiting for a slot.", int64(queued))
+			writeMetric(&b, "rss2json_queue_rejected_total", "counter", "Requests rejected because the queue was full.", rejected)
+			writeMetric(&b, "rss2json_queue_timeouts_total", "counter", "Requests rejected after waiting QUEUE_TIMEOUT for a slot.", timeouts)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte(b.String()))
+	}
+}
+
+func writeMetric(b *strings.Builder, name, kind, help string, value int64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
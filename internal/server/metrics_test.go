@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// scrapeMetrics 请求 /metrics 并按指标名返回数值。
+func scrapeMetrics(t *testing.T, handler http.Handler) map[string]int64 {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, raw, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("malformed metric line %q", line)
+		}
+		val, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			t.Fatalf("malformed metric value %q", line)
+		}
+		values[name] = val
+	}
+	return values
+}
+
+func TestMetricsCacheCounters(t *testing.T) {
+	restore := rss.WithHTTPClient(&countingDoer{})
+	defer restore()
+
+	handler := NewHandler(Options{CacheTTL: time.Minute})
+	before := scrapeMetrics(t, handler)
+	if before["rss2json_cache_entries"] != 0 {
+		t.Fatalf("expected empty cache, got %v", before)
+	}
+
+	target := "/api/v1/rss2json?url=" + url.QueryEscape("https://metrics.example.com/rss")
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	after := scrapeMetrics(t, handler)
+	if got := after["rss2json_cache_misses_total"] - before["rss2json_cache_misses_total"]; got != 1 {
+		t.Fatalf("expected 1 miss, got %d", got)
+	}
+	if got := after["rss2json_cache_hits_total"] - before["rss2json_cache_hits_total"]; got != 1 {
+		t.Fatalf("expected 1 hit, got %d", got)
+	}
+	if after["rss2json_cache_entries"] != 1 {
+		t.Fatalf("expected 1 cache entry, got %d", after["rss2json_cache_entries"])
+	}
+	for _, name := range []string{"rss2json_upstream_connections_created_total", "rss2json_upstream_connections_reused_total"} {
+		if _, ok := after[name]; !ok {
+			t.Fatalf("expected metric %s in output", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+const (
+	// defaultMaxURLs 为未配置 MAX_URLS 时单次请求允许的 url 个数。
+	defaultMaxURLs = 5
+	// multiURLConcurrency 为多 url 请求同时抓取的上限，避免单个请求占满出站连接。
+	multiURLConcurrency = 3
+)
+
+// writeMultiConvert 并发转换多个 url，按请求顺序输出各自的结果或错误；整体状态始终为 200。
+// 全部成功时按最短的 max-age 缓存，任一失败时不允许缓存。
+func writeMultiConvert(w http.ResponseWriter, r *http.Request, urls []string, convertOpts rss.Options, opts Options, debug bool) {
+	limit := opts.MaxURLs
+	if limit <= 0 {
+		limit = defaultMaxURLs
+	}
+	if len(urls) > limit {
+		writeBadRequest(w, "Too many url values. At most "+strconv.Itoa(limit)+" are allowed.")
+		return
+	}
+
+	results := make([]model.MultiResult, len(urls))
+	failed := make([]bool, len(urls))
+	sem := make(chan struct{}, multiURLConcurrency)
+	var wg sync.WaitGroup
+	for i, feedURL := range urls {
+		wg.Add(1)
+		go func(i int, feedURL string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			resp, err := rss.ConvertWithOptions(r.Context(), feedURL, convertOpts)
+			if err != nil {
+				_, resp = errorBody(err, opts.ErrorStatusStyle, debug)
+				failed[i] = true
+			} else if debug {
+				resp.Meta.Options = effectiveOptions(convertOpts)
+			}
+			results[i] = model.MultiResult{URL: feedURL, Response: resp}
+		}(i, feedURL)
+	}
+	wg.Wait()
+	if r.Context().Err() != nil {
+		return
+	}
+
+	var maxAge time.Duration
+	for i, result := range results {
+		if failed[i] {
+			maxAge = 0
+			break
+		}
+		if age := responseMaxAge(result.Response, opts.ResponseMaxAge); i == 0 || age < maxAge {
+			maxAge = age
+		}
+	}
+	body, err := encodeJSON(model.MultiResponse{Status: "ok", Version: model.APIVersion, Results: results})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "Failed to encode response."))
+		return
+	}
+	stable := make([]model.MultiResult, len(results))
+	for i, result := range results {
+		stable[i] = model.MultiResult{URL: result.URL, Response: withoutFetchTime(result.Response)}
+	}
+	etag := jsonETag(body)
+	if stableBody, err := encodeJSON(model.MultiResponse{Status: "ok", Version: model.APIVersion, Results: stable}); err == nil {
+		etag = jsonETag(stableBody)
+	}
+	if maxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	writeJSONTagged(w, r, body, etag)
+}
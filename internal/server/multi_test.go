@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+func TestConvertMultipleURLsKeepsOrder(t *testing.T) {
+	t.Setenv("NEGATIVE_CACHE_TTL", "0")
+	restore := rss.WithHTTPClient(&hostDoer{})
+	defer restore()
+
+	urls := []string{"https://down.example.com/rss", "https://a.example.com/rss", "not a url", "https://b.example.com/rss"}
+	query := url.Values{"url": urls}
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?"+query.Encode(), nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected uncacheable 200, got %d %q", rr.Code, rr.Header().Get("Cache-Control"))
+	}
+	var body struct {
+		Status  string `json:"status"`
+		Results []struct {
+			URL    string `json:"url"`
+			Status string `json:"status"`
+			Feed   *struct {
+				Title string `json:"title"`
+			} `json:"feed"`
+			Error *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if body.Status != "ok" || len(body.Results) != len(urls) {
+		t.Fatalf("unexpected body %s", rr.Body.String())
+	}
+	wantCodes := []string{"upstream_status", "", "malformed_url", ""}
+	for i, result := range body.Results {
+		if result.URL != urls[i] {
+			t.Fatalf("result %d: expected url %q, got %q", i, urls[i], result.URL)
+		}
+		if wantCodes[i] == "" {
+			if result.Status != "ok" || result.Feed == nil || result.Feed.Title != "Sample Feed" {
+				t.Fatalf("result %d: expected feed, got %s", i, rr.Body.String())
+			}
+			continue
+		}
+		if result.Status != "error" || result.Error == nil || result.Error.Code != wantCodes[i] {
+			t.Fatalf("result %d: expected %s, got %s", i, wantCodes[i], rr.Body.String())
+		}
+	}
+}
+
+func TestConvertSingleURLShapeUnchanged(t *testing.T) {
+	restore := rss.WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape("https://single.example.com/rss"), nil))
+	if strings.Contains(rr.Body.String(), `"results"`) || !strings.Contains(rr.Body.String(), `"items"`) {
+		t.Fatalf("expected single-url shape, got %s", rr.Body.String())
+	}
+}
+
+func TestConvertMultipleURLsLimit(t *testing.T) {
+	query := url.Values{"url": {"https://a.example.com/rss", "https://b.example.com/rss", "https://c.example.com/rss"}}
+	rr := httptest.NewRecorder()
+	NewHandler(Options{MaxURLs: 2}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rss2json?"+query.Encode(), nil))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"code":"invalid_parameter"`) {
+		t.Fatalf("expected 400 invalid_parameter, got %d %s", rr.Code, rr.Body.String())
+	}
+}
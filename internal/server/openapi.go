@@ -0,0 +1,19 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec 为 /api/v1/openapi.json 返回的 OpenAPI 3 文档，修改 model.Response 或查询参数时需同步更新。
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPIHandler 处理 /api/v1/openapi.json 请求，返回内嵌的 OpenAPI 文档。
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPISpec)
+}
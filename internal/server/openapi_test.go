@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("expected OpenAPI 3 document, got %q", doc.OpenAPI)
+	}
+	for _, path := range []string{"/api/v1/rss2json", "/api/v1/validate"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Fatalf("missing path %s", path)
+		}
+	}
+
+	// Response 的每个 JSON 字段都应出现在文档中，避免模型变更后文档过期。
+	props := doc.Components.Schemas["Response"].Properties
+	typ := reflect.TypeOf(model.Response{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := props[name]; !ok {
+			t.Fatalf("Response field %q missing from openapi schema", name)
+		}
+	}
+}
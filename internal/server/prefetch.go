@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+const (
+	// defaultPrefetchInterval 为未配置 PREFETCH_INTERVAL 时每个 feed 的预热周期。
+	defaultPrefetchInterval = 5 * time.Minute
+	// prefetchTick 为调度器检查到期 feed 的间隔。
+	prefetchTick = time.Second
+	// prefetchMaxBackoffShift 限制连续失败时的退避倍数，最长为周期的 32 倍。
+	prefetchMaxBackoffShift = 5
+	// prefetchJitterRatio 为每次重新调度时附加的随机延迟占周期的比例上限。
+	prefetchJitterRatio = 10
+)
+
+// ParsePrefetchURLs 解析 PREFETCH_URLS（逗号分隔），只接受 http/https 地址。
+func ParsePrefetchURLs(raw string) ([]string, error) {
+	var urls []string
+	for _, u := range strings.Split(raw, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("invalid prefetch url %q", u)
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// prefetchTarget 为单个预热 feed 的调度状态。
+type prefetchTarget struct {
+	url           string
+	next          time.Time
+	running       bool
+	failures      int
+	lastSuccess   time.Time
+	lastError     time.Time
+	lastErrorCode string
+	lastMessage   string
+}
+
+// prefetcher 按周期在后台转换 PREFETCH_URLS 中的 feed，将上游内容写入缓存，使用户请求总能命中。
+// 启动时各 feed 在一个周期内随机错开，避免重启后同时请求上游；同一 feed 上一次仍在运行时跳过；
+// 失败后按连续失败次数指数退避。
+type prefetcher struct {
+	opts     Options
+	interval time.Duration
+	now      func() time.Time
+	// jitter 返回 [0, max) 内的随机延迟，测试中可替换为固定值。
+	jitter func(max time.Duration) time.Duration
+	// convert 执行一次预热，测试中可替换。
+	convert func(ctx context.Context, feedURL string) error
+
+	mu      sync.Mutex
+	targets []*prefetchTarget
+	wg      sync.WaitGroup
+}
+
+func newPrefetcher(opts Options) *prefetcher {
+	p := &prefetcher{
+		opts:     opts,
+		interval: durationOr(opts.PrefetchInterval, defaultPrefetchInterval),
+		now:      time.Now,
+		jitter:   randomJitter,
+	}
+	p.convert = p.convertFeed
+	return p
+}
+
+// randomJitter 返回 [0, max) 内均匀分布的随机时长。
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
+
+// schedule 登记全部 feed，首次运行时间在一个周期内随机分布。
+func (p *prefetcher) schedule(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for _, u := range urls {
+		p.targets = append(p.targets, &prefetchTarget{url: u, next: now.Add(p.jitter(p.interval))})
+	}
+}
+
+// tick 启动所有已到期且未在运行的 feed 的预热，不等待其完成。
+func (p *prefetcher) tick(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for _, target := range p.targets {
+		if target.running || now.Before(target.next) {
+			continue
+		}
+		target.running = true
+		p.wg.Add(1)
+		go p.runTarget(ctx, target)
+	}
+}
+
+// runTarget 预热单个 feed 并按结果安排下一次运行。
+func (p *prefetcher) runTarget(ctx context.Context, target *prefetchTarget) {
+	defer p.wg.Done()
+	fetchCtx, cancel := context.WithTimeout(ctx, feedSetFetchTimeout)
+	err := p.convert(fetchCtx, target.url)
+	cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	target.running = false
+	delay := p.interval
+	if err != nil {
+		target.failures++
+		target.lastError = now
+		target.lastErrorCode = string(rss.ErrorCodeOf(err))
+		_, target.lastMessage = mapError(err, p.opts.ErrorStatusStyle)
+		delay = p.interval << min(target.failures, prefetchMaxBackoffShift)
+		log.Printf("[prefetch] %s failed (%d in a row, retry in %s): %v", target.url, target.failures, delay, err)
+	} else {
+		target.failures = 0
+		target.lastSuccess = now
+	}
+	target.next = now.Add(delay + p.jitter(p.interval/prefetchJitterRatio))
+}
+
+// convertFeed 按默认参数转换 feed，跳过缓存读取以确保写入最新内容。
+func (p *prefetcher) convertFeed(ctx context.Context, feedURL string) error {
+	convertOpts := resolveConvertOptions(url.Values{}, p.opts)
+	convertOpts.Refresh = true
+	_, err := rss.ConvertWithOptions(ctx, feedURL, convertOpts)
+	return err
+}
+
+// run 按 prefetchTick 检查到期的 feed，直到 ctx 结束。
+func (p *prefetcher) run(ctx context.Context) {
+	ticker := time.NewTicker(prefetchTick)
+	defer ticker.Stop()
+	for {
+		p.tick(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// status 返回各 feed 的预热状态，顺序与 PREFETCH_URLS 一致。
+func (p *prefetcher) status() []model.PrefetchFeed {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	feeds := make([]model.PrefetchFeed, 0, len(p.targets))
+	for _, target := range p.targets {
+		feed := model.PrefetchFeed{
+			URL:           target.url,
+			Running:       target.running,
+			LastErrorCode: target.lastErrorCode,
+			LastError:     target.lastMessage,
+			Failures:      target.failures,
+		}
+		if !target.running {
+			next := target.next.UTC()
+			feed.NextRunAt = &next
+		}
+		if !target.lastSuccess.IsZero() {
+			lastSuccess := target.lastSuccess.UTC()
+			feed.LastSuccessAt = &lastSuccess
+		}
+		if !target.lastError.IsZero() {
+			lastError := target.lastError.UTC()
+			feed.LastErrorAt = &lastError
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds
+}
+
+// newPrefetchHandler 处理 GET /admin/prefetch：返回各预热 feed 最近一次成功与失败的时间、
+// 连续失败次数与下一次计划运行时间。要求配置 API_KEY。
+func newPrefetchHandler(p *prefetcher, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAPIKeyConfigured(w, opts) {
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, model.PrefetchStatus{
+			Status:  "ok",
+			Version: model.APIVersion,
+			Feeds:   p.status(),
+		})
+	}
+}
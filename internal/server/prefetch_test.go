@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// prefetchDoer 按主机计数上游请求，down 中的主机返回连接错误。
+type prefetchDoer struct {
+	mu    sync.Mutex
+	calls map[string]int
+	down  map[string]bool
+}
+
+func (h *prefetchDoer) Do(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls[req.URL.Host]++
+	if h.down[req.URL.Host] {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(sampleRSS))}, nil
+}
+
+func (h *prefetchDoer) count(host string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls[host]
+}
+
+func TestPrefetchWarmsCacheAndBacksOff(t *testing.T) {
+	doer := &prefetchDoer{calls: map[string]int{}, down: map[string]bool{"down.prefetch.example": true}}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	opts := Options{Cache: cache.NewMemory(0), CacheTTL: time.Hour, PrefetchInterval: time.Minute}
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newPrefetcher(opts)
+	p.now = func() time.Time { return clock }
+	p.jitter = func(max time.Duration) time.Duration { return max / 2 }
+	feeds := []string{"https://up.prefetch.example/rss", "https://down.prefetch.example/rss"}
+	p.schedule(feeds)
+	cycle := func(at time.Duration) {
+		clock = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(at)
+		p.tick(context.Background())
+		p.wg.Wait()
+	}
+
+	// 启动抖动为周期的一半：30s 前不运行。
+	cycle(29 * time.Second)
+	if doer.count("up.prefetch.example") != 0 {
+		t.Fatal("expected no prefetch before the jittered start")
+	}
+
+	// 第一轮：两个 feed 都运行，成功的写入缓存，用户请求直接命中。
+	cycle(30 * time.Second)
+	if doer.count("up.prefetch.example") != 1 || doer.count("down.prefetch.example") != 1 {
+		t.Fatalf("expected one fetch per feed, got %v", doer.calls)
+	}
+	rr := adminRequest(NewHandler(opts), http.MethodGet, "/api/v1/rss2json?url="+url.QueryEscape(feeds[0]))
+	if rr.Code != http.StatusOK || doer.count("up.prefetch.example") != 1 {
+		t.Fatalf("expected user request served from the warm cache, got %d with %v", rr.Code, doer.calls)
+	}
+
+	status := p.status()
+	if status[0].LastSuccessAt == nil || status[0].Failures != 0 || status[0].NextRunAt.Sub(clock) != time.Minute+3*time.Second {
+		t.Fatalf("unexpected status for healthy feed: %+v", status[0])
+	}
+	if status[1].Failures != 1 || status[1].LastErrorCode != "fetch_failed" || status[1].NextRunAt.Sub(clock) != 2*time.Minute+3*time.Second {
+		t.Fatalf("expected failed feed to back off 2x, got %+v", status[1])
+	}
+
+	// 第二轮：健康的 feed 按周期刷新（跳过缓存读取），失败的 feed 仍在退避中。
+	cycle(30*time.Second + time.Minute + 3*time.Second)
+	if doer.count("up.prefetch.example") != 2 || doer.count("down.prefetch.example") != 1 {
+		t.Fatalf("expected only the healthy feed refreshed, got %v", doer.calls)
+	}
+	cycle(30*time.Second + 2*time.Minute + 3*time.Second)
+	if doer.count("down.prefetch.example") != 2 {
+		t.Fatalf("expected failed feed retried after backoff, got %v", doer.calls)
+	}
+	if status := p.status(); status[1].Failures != 2 || status[1].NextRunAt.Sub(clock) != 4*time.Minute+3*time.Second {
+		t.Fatalf("expected backoff to double, got %+v", status[1])
+	}
+}
+
+func TestPrefetchSkipsFeedStillInFlight(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newPrefetcher(Options{PrefetchInterval: time.Minute})
+	p.now = func() time.Time { return clock }
+	p.jitter = func(time.Duration) time.Duration { return 0 }
+	release := make(chan struct{})
+	var calls atomic.Int32
+	p.convert = func(ctx context.Context, feedURL string) error {
+		calls.Add(1)
+		<-release
+		return nil
+	}
+	p.schedule([]string{"https://slow.prefetch.example/rss"})
+
+	p.tick(context.Background())
+	waitFor(t, "first run to start", func() bool { return calls.Load() == 1 })
+	clock = clock.Add(time.Hour)
+	p.tick(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected the in-flight feed to be skipped, got %d runs", got)
+	}
+	if status := p.status(); !status[0].Running || status[0].NextRunAt != nil {
+		t.Fatalf("expected running status without next run, got %+v", status[0])
+	}
+	close(release)
+	p.wg.Wait()
+	if status := p.status(); status[0].Running || status[0].LastSuccessAt == nil {
+		t.Fatalf("expected completed run, got %+v", status[0])
+	}
+}
+
+func TestPrefetchEndpoint(t *testing.T) {
+	handler := NewHandler(Options{APIKey: "admin-key", CacheTTL: time.Minute, PrefetchURLs: []string{"https://a.prefetch.example/rss"}, PrefetchInterval: time.Hour})
+	rr := adminRequest(handler, http.MethodGet, "/admin/prefetch")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"url":"https://a.prefetch.example/rss"`) || !strings.Contains(rr.Body.String(), `"next_run_at"`) {
+		t.Fatalf("unexpected prefetch status %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := adminRequest(NewHandler(Options{APIKey: "admin-key"}), http.MethodGet, "/admin/prefetch"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without PREFETCH_URLS, got %d", rr.Code)
+	}
+}
+
+func TestParsePrefetchURLs(t *testing.T) {
+	urls, err := ParsePrefetchURLs(" https://a.example.com/rss, ,http://b.example.com/feed ")
+	if err != nil || strings.Join(urls, ",") != "https://a.example.com/rss,http://b.example.com/feed" {
+		t.Fatalf("unexpected urls %v err=%v", urls, err)
+	}
+	if _, err := ParsePrefetchURLs("ftp://example.com/rss"); err == nil {
+		t.Fatal("expected error for non-http url")
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// panicsTotal 统计被恢复的 handler panic 次数，开启 DEBUG_ENDPOINTS 后可在 /debug/vars 查看。
+var panicsTotal = expvar.NewInt("http_panics_total")
+
+// withRecover 捕获 handler 中的 panic，记录堆栈与请求 ID 后返回 500 JSON 错误，
+// 避免单个异常 feed 直接断开连接。http.ErrAbortHandler 为主动中止，继续向上抛出。
+func withRecover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			panicsTotal.Add(1)
+			log.Printf("[panic] id=%s %s %s: %v\n%s", rss.RequestID(r.Context()), r.Method, r.URL.Path, v, debug.Stack())
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, http.StatusInternalServerError, errorResponse(codeInternal, "internal error"))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecoverReturnsJSON500AndKeepsServing(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var resp *http.Response
+		_ = resp.StatusCode
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	srv := httptest.NewServer(withRequestID(withRecover(mux)))
+	defer srv.Close()
+
+	before := panicsTotal.Value()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/panic", nil)
+	req.Header.Set("X-Request-Id", "req-panic-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected a response, got %v", err)
+	}
+	var body struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Error   struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || body.Status != "error" || body.Message != "internal error" || body.Error.Code != "internal_error" {
+		t.Fatalf("unexpected response %d %+v", resp.StatusCode, body)
+	}
+	if got := panicsTotal.Value() - before; got != 1 {
+		t.Fatalf("expected panic counted once, got %d", got)
+	}
+	if !strings.Contains(buf.String(), "id=req-panic-1") || !strings.Contains(buf.String(), "goroutine") {
+		t.Fatalf("expected stack logged with request id, got %q", buf.String())
+	}
+
+	resp, err = http.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatalf("server stopped serving: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(data), `"ok"`) {
+		t.Fatalf("unexpected follow-up response %d %s", resp.StatusCode, data)
+	}
+}
+
+func TestRecoverRethrowsAbortHandler(t *testing.T) {
+	handler := withRecover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("expected ErrAbortHandler to propagate, got %v", v)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Fatal("expected panic")
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+// RuntimeConfig 为运行中可热更新的配置项。
+type RuntimeConfig struct {
+	APIKey            string
+	CallbackAllowlist []string
+	CacheTTL          time.Duration
+}
+
+// Runtime 持有当前生效的 RuntimeConfig。请求处理时读取最新值，Store 整体原子替换，
+// 同一请求内不会读到新旧配置混用的状态。
+type Runtime struct {
+	cur atomic.Pointer[RuntimeConfig]
+}
+
+// NewRuntime 返回以 cfg 为初始配置的 Runtime。
+func NewRuntime(cfg RuntimeConfig) *Runtime {
+	rt := &Runtime{}
+	rt.Store(cfg)
+	return rt
+}
+
+// Load 返回当前配置。
+func (rt *Runtime) Load() RuntimeConfig {
+	return *rt.cur.Load()
+}
+
+// Store 原子替换当前配置，之后到达的请求使用新配置。
+func (rt *Runtime) Store(cfg RuntimeConfig) {
+	rt.cur.Store(&cfg)
+}
+
+// runtime 返回当前生效的可热更新配置，未配置 Runtime 时取 Options 中的静态值。
+func (o Options) runtime() RuntimeConfig {
+	if o.Runtime != nil {
+		return o.Runtime.Load()
+	}
+	return RuntimeConfig{APIKey: o.APIKey, CallbackAllowlist: o.CallbackAllowlist, CacheTTL: o.CacheTTL}
+}
+
+// newReloadHandler 处理 POST /admin/reload：调用 opts.Reload 重新读取并校验配置，
+// 校验失败时返回 422 invalid_config，原配置保持生效。要求配置 API_KEY。
+func newReloadHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdmin(w, r, opts, http.MethodPost) {
+			return
+		}
+		if _, ok := readLimitedBody(w, r); !ok {
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		result, err := opts.Reload()
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse(codeInvalidConfig, "Configuration not reloaded: "+err.Error()))
+			return
+		}
+		result.Status = "ok"
+		result.Version = model.APIVersion
+		writeJSON(w, http.StatusOK, result)
+	}
+}
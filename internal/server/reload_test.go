@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zdev0x/rss2json/model"
+)
+
+func TestRuntimeSwapTakesEffect(t *testing.T) {
+	rt := NewRuntime(RuntimeConfig{APIKey: "admin-key", CallbackAllowlist: []string{"a.example.com"}})
+	handler := NewHandler(Options{Runtime: rt, AllowCallbacks: true, CallbackSecret: "s3cret"})
+	// 非法的 tz 在白名单检查之后才校验：允许的主机得到 400，不会在后台启动任务。
+	callback := func(host string) int {
+		target := "/api/v1/rss2json?tz=Invalid/Zone&url=" + url.QueryEscape("https://reload.example.com/rss") + "&callback_url=" + url.QueryEscape("https://"+host+"/hook")
+		return adminRequest(handler, http.MethodPost, target).Code
+	}
+	if callback("a.example.com") != http.StatusBadRequest || callback("b.example.com") != http.StatusForbidden {
+		t.Fatal("unexpected callback allowlist before swap")
+	}
+
+	rt.Store(RuntimeConfig{APIKey: "admin-key", CallbackAllowlist: []string{"b.example.com"}})
+	if callback("a.example.com") != http.StatusForbidden || callback("b.example.com") != http.StatusBadRequest {
+		t.Fatal("expected the swapped allowlist to take effect")
+	}
+
+	rt.Store(RuntimeConfig{APIKey: "rotated-key"})
+	if rr := adminRequest(handler, http.MethodGet, "/health"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the old key to be rejected after rotation, got %d", rr.Code)
+	}
+}
+
+func TestReloadEndpoint(t *testing.T) {
+	var next error
+	handler := NewHandler(Options{APIKey: "admin-key", Reload: func() (model.ConfigReload, error) {
+		return model.ConfigReload{Applied: []string{"API_KEY"}, RestartRequired: []string{}}, next
+	}})
+
+	if rr := adminRequest(handler, http.MethodGet, "/admin/reload"); rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rr.Code)
+	}
+	rr := adminRequest(handler, http.MethodPost, "/admin/reload")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"applied":["API_KEY"]`) {
+		t.Fatalf("unexpected reload response %d %s", rr.Code, rr.Body.String())
+	}
+
+	next = errors.New("CACHE_TTL must be a non-negative number of seconds")
+	rr = adminRequest(handler, http.MethodPost, "/admin/reload")
+	if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), `"code":"invalid_config"`) || !strings.Contains(rr.Body.String(), "CACHE_TTL") {
+		t.Fatalf("expected invalid_config, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := adminRequest(NewHandler(Options{APIKey: "admin-key"}), http.MethodPost, "/admin/reload"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected reload to be unavailable without a reloader, got %d", rr.Code)
+	}
+}
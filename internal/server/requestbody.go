@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// convertRequestBody 为 POST /api/v1/rss2json 的 JSON 请求体，查询参数与 GET 相同。
+type convertRequestBody struct {
+	// Headers 为单次请求的出站请求头，与 header 查询参数一样经过过滤。
+	Headers map[string]string `json:"headers"`
+}
+
+// withRequestBody 在开启 ALLOW_REQUEST_HEADERS 时允许以 POST 调用主接口，
+// 通过 JSON 请求体的 headers 字段传入单次请求的请求头；未开启时接口保持只读。
+func withRequestBody(next http.HandlerFunc, opts Options) http.HandlerFunc {
+	if !opts.AllowRequestHeaders {
+		return readOnly(next)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			next(w, r)
+		case http.MethodHead:
+			next(headResponseWriter{w}, r)
+		case http.MethodPost:
+			body, ok := readLimitedBody(w, r)
+			if !ok || !mergeBodyHeaders(w, r, body) {
+				return
+			}
+			next(w, r)
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodHead, http.MethodPost)
+		}
+	}
+}
+
+// mergeBodyHeaders 将请求体 headers 字段追加为 header 查询参数，之后与查询参数走同一套过滤；
+// 请求体不是合法 JSON 时输出 400 并返回 false，空请求体视为没有额外请求头。
+func mergeBodyHeaders(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	if len(body) == 0 {
+		return true
+	}
+	var req convertRequestBody
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeBadRequest(w, "Invalid JSON body. Expected {\"headers\": {\"Name\": \"Value\"}}.")
+		return false
+	}
+	if len(req.Headers) == 0 {
+		return true
+	}
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	query := r.URL.Query()
+	for _, name := range names {
+		query.Add("header", name+":"+req.Headers[name])
+	}
+	r.URL.RawQuery = query.Encode()
+	return true
+}
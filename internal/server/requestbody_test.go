@@ -0,0 +1,84 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// headerDoer 记录最近一次出站请求的请求头。
+type headerDoer struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+func (h *headerDoer) Do(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.header = req.Header.Clone()
+	h.mu.Unlock()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(sampleRSS))}, nil
+}
+
+func (h *headerDoer) last() http.Header {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.header
+}
+
+func TestPostBodyHeaders(t *testing.T) {
+	doer := &headerDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	handler := NewHandler(Options{AllowRequestHeaders: true})
+	target := "/api/v1/rss2json?url=" + url.QueryEscape("https://headers.example.com/rss")
+	body := `{"headers": {"Referer": "https://example.com/page", "Cookie": "session=abc", "Connection": "close", "Transfer-Encoding": "chunked", "X-Bad": "a\r\nInjected: yes"}}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	sent := doer.last()
+	if got := sent.Get("Referer"); got != "https://example.com/page" {
+		t.Fatalf("expected per-request Referer, got %q", got)
+	}
+	if got := sent.Get("Cookie"); got != "session=abc" {
+		t.Fatalf("expected per-request Cookie, got %q", got)
+	}
+	for _, name := range []string{"Connection", "Transfer-Encoding", "X-Bad", "Injected"} {
+		if sent.Get(name) != "" {
+			t.Fatalf("expected %s stripped, got %v", name, sent)
+		}
+	}
+}
+
+func TestPostBodyHeadersRejected(t *testing.T) {
+	restore := rss.WithHTTPClient(&headerDoer{})
+	defer restore()
+
+	target := "/api/v1/rss2json?url=" + url.QueryEscape("https://headers.example.com/rss")
+	cases := []struct {
+		name   string
+		opts   Options
+		body   string
+		status int
+	}{
+		{"disabled", Options{}, `{"headers": {"Referer": "x"}}`, http.StatusMethodNotAllowed},
+		{"invalid json", Options{AllowRequestHeaders: true}, `{"headers": [`, http.StatusBadRequest},
+		{"empty body", Options{AllowRequestHeaders: true}, ``, http.StatusOK},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		NewHandler(tc.opts).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(tc.body)))
+		if rr.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.status, rr.Code, rr.Body.String())
+		}
+	}
+}
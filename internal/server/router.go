@@ -1,51 +1,215 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/zdev0x/rss2json/internal/model"
+	"github.com/zdev0x/rss2json/internal/cache"
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
 )
 
 // Options 定义 HTTP 服务相关选项。
 type Options struct {
 	APIKey           string
 	EnableRequestLog bool
+	// AllowRequestHeaders 允许通过 user_agent/header 查询参数覆盖出站请求头。
+	AllowRequestHeaders bool
+	// AllowRequestAuthHeader 允许覆盖 Authorization，仅在 AllowRequestHeaders 开启时生效。
+	AllowRequestAuthHeader bool
+	// HealthCanaryURL 为 /health?deep=1 探测出站网络时拉取的 feed 地址。
+	HealthCanaryURL string
+	// ResponseMaxAge 为成功响应的 Cache-Control max-age，0 表示按 feed 的 ttl 或默认值。
+	ResponseMaxAge time.Duration
+	// DefaultCount 为客户端未指定 count 时返回的条目数，0 表示全部。
+	DefaultCount int
+	// MaxCount 为条目数上限，客户端请求更多时截断，0 表示不限制。
+	MaxCount int
+	// ForceSanitize/ForceStripTracking 无视客户端参数强制开启对应处理。
+	ForceSanitize      bool
+	ForceStripTracking bool
+	// AllowRequestMaxBytes 允许通过 max_bytes 查询参数覆盖单次请求的内容上限。
+	AllowRequestMaxBytes bool
+	// MaxBytesLimit 为 max_bytes 的上限，超过时截断；0 表示使用 defaultMaxBytesLimit。
+	MaxBytesLimit int64
+	// AllowRequestInsecure 允许通过 insecure=1 跳过单次请求的上游证书校验。
+	AllowRequestInsecure bool
+	// Cache/CacheTTL 缓存上游 feed 内容，CacheTTL 为 0 时关闭；Cache 为空时使用内存缓存。
+	Cache    cache.Store
+	CacheTTL time.Duration
+	// DebugEndpoints 挂载 /debug/pprof/ 与 /debug/vars，始终要求 API_KEY 鉴权。
+	DebugEndpoints bool
+	// FeedSets 为服务端预置的 feed 集合（名称到 URL 列表），通过 /api/v1/feeds?set=<name> 读取。
+	FeedSets map[string][]string
+	// FeedSetRefresh 为集合后台刷新周期，0 表示不在后台刷新，只在首次请求时加载。
+	FeedSetRefresh time.Duration
+	// PrefetchURLs 为后台定期预热到缓存的 feed，需启用缓存；非空时挂载 GET /admin/prefetch。
+	PrefetchURLs []string
+	// PrefetchInterval 为每个 feed 的预热周期，0 时使用 defaultPrefetchInterval。
+	PrefetchInterval time.Duration
+	// MaxURLs 为主接口单次请求允许的 url 参数个数，0 表示使用 defaultMaxURLs。
+	MaxURLs int
+	// AllowCallbacks 允许以 POST 携带 callback_url 异步转换，结果签名后投递到回调地址。
+	AllowCallbacks bool
+	// CallbackAllowlist 为允许的回调主机（含子域名），为空时拒绝全部回调。
+	CallbackAllowlist []string
+	// CallbackSecret 为回调签名 X-Rss2json-Signature 使用的 HMAC-SHA256 密钥。
+	CallbackSecret string
+	// MinStreamInterval 为 /api/v1/stream 允许的最短轮询间隔，0 表示使用 defaultMinStreamInterval。
+	MinStreamInterval time.Duration
+	// MaxStreams 为同时保持的 SSE 流数量上限，0 表示使用 defaultMaxStreams。
+	MaxStreams int
+	// StreamShutdown 关闭时结束全部 SSE 流，应在 http.Server 关闭时关闭，避免长连接阻塞 Shutdown。
+	StreamShutdown <-chan struct{}
+	// Shutdown 关闭时停止后台任务（feed 集合定时刷新与预取），应在 http.Server 关闭时关闭；
+	// nil 时后台任务随进程一直运行。
+	Shutdown <-chan struct{}
+	// MaxBodyBytes 为请求体大小上限，0 表示使用 defaultMaxBodyBytes。
+	MaxBodyBytes int64
+	// MaxHeaderBytes/ReadHeaderTimeout/ReadTimeout 由 NewServer 应用，0 表示使用默认值。
+	MaxHeaderBytes    int
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// DisableDataURLs 拒绝 url=data:... 与 xml_b64 内联传入的 feed。
+	DisableDataURLs bool
+	// ErrorStatusStyle 为错误响应的状态码风格（strict/legacy），空值按 strict 处理。
+	ErrorStatusStyle string
+	// FieldMap 为未传 compat 参数时默认应用的条目字段重命名映射，nil 表示不重命名。
+	FieldMap model.FieldMap
+	// Lifecycle 为服务生命周期状态，决定 /health/ready 的结果；nil 时视为始终就绪。
+	Lifecycle *Lifecycle
+	// Runtime 为可热更新的配置（API_KEY、CALLBACK_ALLOWLIST、CACHE_TTL），nil 时 NewHandler
+	// 按 APIKey、CallbackAllowlist 与 CacheTTL 构造，配置之后以 Runtime 为准。
+	Runtime *Runtime
+	// Reload 重新读取并校验配置、成功后应用到 Runtime，非 nil 时挂载 POST /admin/reload。
+	Reload func() (model.ConfigReload, error)
+	// MaxActive 为同时处理的请求数上限，0 表示不限制；超出的请求最多 MaxQueue 个排队，
+	// 等待超过 QueueTimeout（0 表示使用 defaultQueueTimeout）或队列已满时返回 503。
+	MaxActive    int
+	MaxQueue     int
+	QueueTimeout time.Duration
 }
 
 // NewHandler 构造带路由与中间件的 HTTP Handler。
 func NewHandler(opts Options) http.Handler {
+	if opts.Runtime == nil {
+		opts.Runtime = NewRuntime(opts.runtime())
+	}
+	if opts.runtime().CacheTTL > 0 && opts.Cache == nil {
+		opts.Cache = cache.NewMemory(cache.DefaultMaxEntries)
+	}
+	adm := newAdmission(opts)
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/rss2json", ConvertHandler)
-	mux.HandleFunc("/health", HealthHandler)
+	mux.HandleFunc("/", notFoundHandler)
+	mux.HandleFunc("/{$}", readOnly(landingHandler))
+	mux.HandleFunc("/favicon.ico", readOnly(faviconHandler))
+	background := backgroundContext(opts.Shutdown)
+	jobs := newJobStore(defaultJobTTL)
+	mux.HandleFunc("/api/v1/rss2json", withCallbacks(withRequestBody(newConvertHandler(opts), opts), jobs, background, opts))
+	mux.HandleFunc("/api/v1/jobs/{id}", readOnly(newJobsHandler(jobs)))
+	mux.HandleFunc("/api/v1/stream", readOnly(newStreamHandler(newStreamHub(opts), opts.StreamShutdown)))
+	mux.HandleFunc("/api/v1/validate", readOnly(newValidateHandler(opts)))
+	mux.HandleFunc("/api/v1/debug", readOnly(newDebugHandler(opts)))
+	mux.HandleFunc("/api/v1/openapi.json", readOnly(OpenAPIHandler))
+	mux.HandleFunc("/health", readOnly(newHealthHandler(opts)))
+	mux.HandleFunc("/health/ready", readOnly(newReadyHandler(opts.Lifecycle)))
+	mux.HandleFunc("/version", readOnly(VersionHandler))
+	mux.HandleFunc("/metrics", readOnly(newMetricsHandler(opts, adm)))
+	mux.HandleFunc("/admin/cache", newCachePurgeHandler(opts))
+	mux.HandleFunc("/admin/cache/all", newCachePurgeAllHandler(opts))
+	mux.HandleFunc("/admin/cache/flush", newCacheFlushHandler(opts))
+	mux.HandleFunc("/admin/cache/stats", readOnly(newCacheStatsHandler(opts)))
+	mux.HandleFunc("/admin/cache/keys", readOnly(newCacheKeysHandler(opts)))
+	mux.HandleFunc("/admin/stats", readOnly(newStatsHandler(opts)))
+	if opts.Reload != nil {
+		mux.HandleFunc("/admin/reload", newReloadHandler(opts))
+	}
+	sets := newFeedSets(opts)
+	mux.HandleFunc("/api/v1/feeds", readOnly(newFeedSetsHandler(sets, opts)))
+	if len(opts.FeedSets) > 0 && opts.FeedSetRefresh > 0 {
+		go sets.run(background, opts.FeedSetRefresh)
+	}
+	if len(opts.PrefetchURLs) > 0 {
+		prefetch := newPrefetcher(opts)
+		prefetch.schedule(opts.PrefetchURLs)
+		mux.HandleFunc("/admin/prefetch", readOnly(newPrefetchHandler(prefetch, opts)))
+		go prefetch.run(background)
+	}
+	if opts.DebugEndpoints {
+		registerDebugEndpoints(mux, opts)
+	}
 
-	var handler http.Handler = mux
+	var handler http.Handler = withRecover(withGzip(withEncodeHTML(withBodyLimit(mux, opts.MaxBodyBytes))))
+	handler = withAdmission(handler, adm)
 	if opts.EnableRequestLog {
 		handler = withRequestLog(handler)
 	}
-	if key := strings.TrimSpace(opts.APIKey); key != "" {
-		handler = withAPIKeyAuth(handler, key)
-	}
+	handler = withAPIKeyAuth(handler, opts.Runtime)
+	handler = withRequestID(handler)
+	handler = withTracing(handler)
 
 	return handler
 }
 
-// withAPIKeyAuth 启用基于 Authorization: Bearer <API_KEY> 的简单鉴权。
-func withAPIKeyAuth(next http.Handler, key string) http.Handler {
-	token := strings.TrimSpace(key)
-	expected := []byte("bearer " + strings.ToLower(token))
+// backgroundContext 返回 done 关闭时取消的 context，供后台任务使用；done 为 nil 时永不取消。
+func backgroundContext(done <-chan struct{}) context.Context {
+	if done == nil {
+		return context.Background()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+	return ctx
+}
+
+// withRequestID 为每个请求分配 ID（优先沿用 X-Request-Id），写入响应头与 ctx，便于关联出站日志。
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get("X-Request-Id"))
+		if id == "" || len(id) > 128 || !validHeaderName(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-Id", id)
+		next.ServeHTTP(w, r.WithContext(rss.WithRequestID(r.Context(), id)))
+	})
+}
+
+// newRequestID 生成 16 位十六进制随机 ID。
+func newRequestID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// withAPIKeyAuth 启用基于 Authorization: Bearer <API_KEY> 或 X-Api-Key: <API_KEY> 的简单鉴权，
+// 任一方式匹配即放行。每个请求读取 rt 中当前的 API_KEY，为空时不鉴权。
+func withAPIKeyAuth(next http.Handler, rt *Runtime) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(rt.Load().APIKey)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		expected := []byte("bearer " + strings.ToLower(token))
 		auth := strings.ToLower(strings.TrimSpace(r.Header.Get("Authorization")))
-		if subtle.ConstantTimeCompare([]byte(auth), expected) != 1 {
-			writeJSON(w, http.StatusUnauthorized, model.Response{
-				Status:  "error",
-				Version: model.APIVersion,
-				Message: "unauthorized",
-			})
+		bearerOK := subtle.ConstantTimeCompare([]byte(auth), expected) == 1
+		apiKeyOK := subtle.ConstantTimeCompare([]byte(strings.TrimSpace(r.Header.Get("X-Api-Key"))), []byte(token)) == 1
+		if !bearerOK && !apiKeyOK {
+			writeJSON(w, http.StatusUnauthorized, errorResponse(codeUnauthorized, "unauthorized"))
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -56,23 +220,69 @@ func withAPIKeyAuth(next http.Handler, key string) http.Handler {
 func withRequestLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		rec := &statusRecorder{ResponseWriter: w}
 		next.ServeHTTP(rec, r)
-		log.Printf("[request] %s %s %d %s ip=%s", r.Method, r.URL.RequestURI(), rec.status, time.Since(start), clientIP(r))
+		status := strconv.Itoa(rec.status)
+		if rec.status == 0 {
+			status = strconv.Itoa(http.StatusOK)
+			if errors.Is(r.Context().Err(), context.Canceled) {
+				status = "client-aborted"
+			}
+		}
+		log.Printf("[request] %s %s %s %s ip=%s id=%s", r.Method, redactedRequestURI(r), status, time.Since(start), clientIP(r), rss.RequestID(r.Context()))
 	})
 }
 
-// statusRecorder 记录响应状态码。
+// redactedRequestURI 返回脱敏后的请求 URI，隐藏 url 参数中的 userinfo 以及 feed_user/feed_pass。
+func redactedRequestURI(r *http.Request) string {
+	query := r.URL.Query()
+	changed := false
+	if raw := query.Get("url"); raw != "" {
+		if u, err := url.Parse(raw); err == nil && u.User != nil {
+			u.User = url.User("xxxxx")
+			query.Set("url", u.String())
+			changed = true
+		}
+	}
+	for _, key := range []string{"feed_user", "feed_pass"} {
+		if query.Get(key) != "" {
+			query.Set(key, "xxxxx")
+			changed = true
+		}
+	}
+	if !changed {
+		return r.URL.RequestURI()
+	}
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// statusRecorder 记录响应状态码，status 为 0 表示未写出任何响应。
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
 }
 
 func (s *statusRecorder) WriteHeader(statusCode int) {
-	s.status = statusCode
+	if s.status == 0 {
+		s.status = statusCode
+	}
 	s.ResponseWriter.WriteHeader(statusCode)
 }
 
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+// Unwrap 供 http.ResponseController 访问底层连接（如 SSE 的 Flush）。
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
 // clientIP 提取请求端 IP，优先使用 X-Forwarded-For。
 func clientIP(r *http.Request) string {
 	xff := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-For"), ",")[0])
@@ -83,5 +293,9 @@ func clientIP(r *http.Request) string {
 	if err == nil && host != "" {
 		return host
 	}
+	// Unix 域套接字连接的 RemoteAddr 为空或 "@"。
+	if r.RemoteAddr == "" || r.RemoteAddr == "@" {
+		return "unix"
+	}
 	return r.RemoteAddr
 }
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"github.com/zdev0x/rss2json/model"
+)
+
+const (
+	// defaultStreamInterval 为客户端未指定 interval 时的轮询间隔。
+	defaultStreamInterval = 60 * time.Second
+	// defaultMinStreamInterval 为未配置 MIN_STREAM_INTERVAL 时允许的最短轮询间隔。
+	defaultMinStreamInterval = 30 * time.Second
+	// defaultMaxStreams 为未配置 MAX_STREAMS 时同时保持的流数量上限。
+	defaultMaxStreams = 100
+	// streamFetchTimeout 为流轮询时单次抓取的超时。
+	streamFetchTimeout = 15 * time.Second
+)
+
+// streamPingInterval 为 event: ping 保活事件的间隔，避免代理因空闲断开连接。
+var streamPingInterval = 15 * time.Second
+
+// streamHub 记录活跃流数量，并让同一 feed 的多个流共享抓取结果：
+// 距上次抓取不足最短间隔一半时直接复用，抓取进行中时等待其完成。
+type streamHub struct {
+	opts   Options
+	mu     sync.Mutex
+	polls  map[string]*streamPoll
+	active int
+}
+
+// streamPoll 为一次共享抓取，done 关闭后 resp/err 可读。
+type streamPoll struct {
+	done chan struct{}
+	at   time.Time
+	resp model.Response
+	err  error
+}
+
+func newStreamHub(opts Options) *streamHub {
+	return &streamHub{opts: opts, polls: make(map[string]*streamPoll)}
+}
+
+func (h *streamHub) minInterval() time.Duration {
+	if h.opts.MinStreamInterval > 0 {
+		return h.opts.MinStreamInterval
+	}
+	return defaultMinStreamInterval
+}
+
+// acquire 占用一个流名额，已达 MAX_STREAMS 时返回 false。
+func (h *streamHub) acquire() bool {
+	limit := h.opts.MaxStreams
+	if limit <= 0 {
+		limit = defaultMaxStreams
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.active >= limit {
+		return false
+	}
+	h.active++
+	return true
+}
+
+func (h *streamHub) release() {
+	h.mu.Lock()
+	h.active--
+	h.mu.Unlock()
+}
+
+// fetch 返回 feed 的最新转换结果，与其他流共享抓取；抓取不随单个客户端断开而取消。
+func (h *streamHub) fetch(ctx context.Context, feedURL string) (model.Response, error) {
+	h.mu.Lock()
+	now := time.Now()
+	for key, p := range h.polls {
+		if !p.at.IsZero() && now.Sub(p.at) >= h.minInterval()/2 {
+			delete(h.polls, key)
+		}
+	}
+	p, shared := h.polls[feedURL]
+	if !shared {
+		p = &streamPoll{done: make(chan struct{})}
+		h.polls[feedURL] = p
+	}
+	h.mu.Unlock()
+
+	if !shared {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), streamFetchTimeout)
+		resp, err := rss.ConvertWithOptions(fetchCtx, feedURL, resolveConvertOptions(url.Values{}, h.opts))
+		cancel()
+		h.mu.Lock()
+		p.resp, p.err, p.at = resp, err, time.Now()
+		h.mu.Unlock()
+		close(p.done)
+	}
+	select {
+	case <-p.done:
+		return p.resp, p.err
+	case <-ctx.Done():
+		return model.Response{}, ctx.Err()
+	}
+}
+
+// newStreamHandler 处理 /api/v1/stream：保持连接，按 interval 轮询 feed，仅以 event: items
+// 推送相对本连接上一次轮询新增的条目（首个事件包含全部条目与 feed 信息），并定期发送 event: ping。
+// 服务关闭时发送 event: close 后结束。
+func newStreamHandler(hub *streamHub, shutdown <-chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		feedURL := query.Get("url")
+		interval := defaultStreamInterval
+		if raw := strings.TrimSpace(query.Get("interval")); raw != "" {
+			seconds, err := strconv.ParseFloat(raw, 64)
+			if err != nil || seconds <= 0 {
+				writeBadRequest(w, "Invalid interval. Use a positive number of seconds.")
+				return
+			}
+			interval = time.Duration(seconds * float64(time.Second))
+		}
+		interval = max(interval, hub.minInterval())
+
+		if !hub.acquire() {
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, http.StatusServiceUnavailable, errorResponse(codeTooManyStreams, "Too many open streams. Please try again later."))
+			return
+		}
+		defer hub.release()
+
+		resp, err := hub.fetch(r.Context(), feedURL)
+		if clientGone(r, err) {
+			return
+		}
+		if err != nil {
+			writeError(w, err, hub.opts.ErrorStatusStyle, false)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream; charset=utf-8")
+		h.Set("Cache-Control", "no-store")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodHead {
+			return
+		}
+		rc := http.NewResponseController(w)
+		seen := streamItemKeys(resp.Items)
+		first := model.Response{Status: "ok", Version: model.APIVersion, Feed: resp.Feed, Items: resp.Items}
+		if writeEvent(w, rc, "items", first) != nil {
+			return
+		}
+
+		poll := time.NewTicker(interval)
+		defer poll.Stop()
+		ping := time.NewTicker(streamPingInterval)
+		defer ping.Stop()
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case <-shutdown:
+				_ = writeEvent(w, rc, "close", struct{}{})
+				return
+			case <-ping.C:
+				err = writeEvent(w, rc, "ping", struct{}{})
+			case <-poll.C:
+				resp, fetchErr := hub.fetch(r.Context(), feedURL)
+				if fetchErr != nil {
+					if r.Context().Err() != nil {
+						return
+					}
+					_, body := errorBody(fetchErr, hub.opts.ErrorStatusStyle, false)
+					err = writeEvent(w, rc, "error", body)
+					break
+				}
+				var fresh []*model.ItemMeta
+				for _, item := range resp.Items {
+					if key := streamItemKey(item); key != "" && !seen[key] {
+						fresh = append(fresh, item)
+					}
+				}
+				seen = streamItemKeys(resp.Items)
+				if len(fresh) > 0 {
+					err = writeEvent(w, rc, "items", model.Response{Status: "ok", Version: model.APIVersion, Items: fresh})
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamItemKeys 返回条目去重键的集合。
+func streamItemKeys(items []*model.ItemMeta) map[string]bool {
+	keys := make(map[string]bool, len(items))
+	for _, item := range items {
+		if key := streamItemKey(item); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// streamItemKey 按 GUID、link、标题的顺序取条目的去重键。
+func streamItemKey(item *model.ItemMeta) string {
+	if item == nil || item.Item == nil {
+		return ""
+	}
+	switch {
+	case item.GUID != "":
+		return "guid:" + item.GUID
+	case item.Link != "":
+		return "link:" + item.Link
+	default:
+		return "title:" + item.Title
+	}
+}
+
+// writeEvent 以 SSE 格式写出一个事件并立即 flush。
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event string, payload interface{}) error {
+	data, err := encodeJSON(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bytes.TrimSpace(data)); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
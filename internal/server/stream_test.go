@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+)
+
+// growingDoer 每次被调用时多返回一个条目，模拟持续更新的 feed。
+type growingDoer struct {
+	calls atomic.Int32
+}
+
+func (g *growingDoer) Do(req *http.Request) (*http.Response, error) {
+	n := int(g.calls.Add(1))
+	var b strings.Builder
+	b.WriteString(`<rss version="2.0"><channel><title>Live</title>`)
+	for i := n; i >= 1; i-- {
+		fmt.Fprintf(&b, "<item><title>post %d</title><guid>post-%d</guid></item>", i, i)
+	}
+	b.WriteString(`</channel></rss>`)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(b.String()))}, nil
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents 在后台解析 SSE 事件，连接结束时关闭返回的 channel。
+func readEvents(body io.Reader) <-chan sseEvent {
+	events := make(chan sseEvent, 64)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(body)
+		var current sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				current.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				current.data = strings.TrimPrefix(line, "data: ")
+			case line == "":
+				events <- current
+				current = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent, name string) sseEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatalf("stream closed while waiting for %s", name)
+			}
+			if ev.name == name {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+}
+
+func TestStreamEmitsOnlyNewItems(t *testing.T) {
+	streamPingInterval = 10 * time.Millisecond
+	defer func() { streamPingInterval = 15 * time.Second }()
+	restore := rss.WithHTTPClient(&growingDoer{})
+	defer restore()
+
+	srv := httptest.NewServer(NewHandler(Options{MinStreamInterval: 20 * time.Millisecond}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/stream?interval=0.001&url="+url.QueryEscape("https://live.example.com/rss"), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	events := readEvents(resp.Body)
+
+	first := nextEvent(t, events, "items")
+	if !strings.Contains(first.data, `"guid":"post-1"`) || !strings.Contains(first.data, `"title":"Live"`) {
+		t.Fatalf("expected initial snapshot with feed, got %s", first.data)
+	}
+	update := nextEvent(t, events, "items")
+	if !strings.Contains(update.data, `"guid":"post-2"`) || strings.Contains(update.data, `"guid":"post-1"`) || strings.Contains(update.data, `"feed"`) {
+		t.Fatalf("expected only the new item, got %s", update.data)
+	}
+	nextEvent(t, events, "ping")
+}
+
+func TestStreamSharesUpstreamFetches(t *testing.T) {
+	doer := &countingDoer{}
+	restore := rss.WithHTTPClient(doer)
+	defer restore()
+
+	hub := newStreamHub(Options{MinStreamInterval: time.Minute})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := hub.fetch(context.Background(), "https://shared.example.com/rss"); err != nil {
+				t.Errorf("fetch: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := doer.calls.Load(); got != 1 {
+		t.Fatalf("expected one shared upstream fetch, got %d", got)
+	}
+}
+
+func TestStreamLimitAndShutdown(t *testing.T) {
+	restore := rss.WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	shutdown := make(chan struct{})
+	srv := httptest.NewServer(NewHandler(Options{MaxStreams: 1, StreamShutdown: shutdown}))
+	defer srv.Close()
+	target := srv.URL + "/api/v1/stream?url=" + url.QueryEscape("https://limit.example.com/rss")
+
+	resp, err := http.Get(target)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	events := readEvents(resp.Body)
+	nextEvent(t, events, "items")
+
+	second, err := http.Get(target)
+	if err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 over the stream limit, got %d", second.StatusCode)
+	}
+
+	close(shutdown)
+	nextEvent(t, events, "close")
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected stream to end after close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after shutdown")
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/zdev0x/rss2json/internal/rss"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestConvertTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	restore := rss.WithHTTPClient(fakeDoer{body: sampleRSS, status: http.StatusOK})
+	defer restore()
+
+	handler := NewHandler(Options{})
+	target := "/api/v1/rss2json?url=" + url.QueryEscape("https://trace.example.com/rss")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, ok := spans["GET /api/v1/rss2json"]
+	if !ok {
+		t.Fatalf("expected server span, got %v", spanNames(recorder.Ended()))
+	}
+	for _, name := range []string{"fetch", "parse", "serialize"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("expected %s span, got %v", name, spanNames(recorder.Ended()))
+		}
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Fatalf("expected %s span to be a child of the server span", name)
+		}
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans["fetch"].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["server.address"].AsString(); got != "trace.example.com" {
+		t.Fatalf("expected server.address on fetch span, got %q", got)
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusOK {
+		t.Fatalf("expected upstream status code on fetch span, got %d", got)
+	}
+}
+
+func TestTracingSpanStatus(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	cases := []struct {
+		name   string
+		write  func(w http.ResponseWriter)
+		status int64
+		code   codes.Code
+	}{
+		{"server error", func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }, http.StatusBadGateway, codes.Error},
+		{"client error", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }, http.StatusNotFound, codes.Unset},
+		{"implicit ok", func(w http.ResponseWriter) {}, http.StatusOK, codes.Unset},
+	}
+	for _, tc := range cases {
+		recorder.Reset()
+		handler := withTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { tc.write(w) }))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+		ended := recorder.Ended()
+		if len(ended) != 1 {
+			t.Fatalf("%s: expected one span, got %v", tc.name, spanNames(ended))
+		}
+		var status int64
+		for _, kv := range ended[0].Attributes() {
+			if kv.Key == "http.response.status_code" {
+				status = kv.Value.AsInt64()
+			}
+		}
+		if status != tc.status || ended[0].Status().Code != tc.code {
+			t.Fatalf("%s: expected status %d/%v, got %d/%v", tc.name, tc.status, tc.code, status, ended[0].Status().Code)
+		}
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
@@ -0,0 +1,6 @@
+// Package version 保存构建时注入的服务版本号。
+package version
+
+// Version 为服务版本，构建时通过
+// -ldflags "-X github.com/zdev0x/rss2json/internal/version.Version=v1.2.3" 注入，未注入时为 dev。
+var Version = "dev"
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// rss2jsonDateLayout 为 rss2json.com 输出 pubDate 使用的格式（UTC）。
+const rss2jsonDateLayout = "2006-01-02 15:04:05"
+
+// RSS2JSONResponse 为与 rss2json.com 完全一致的响应结构，供 compat=rss2json 使用，
+// 现有面向 rss2json.com 的客户端无需修改即可接入。
+type RSS2JSONResponse struct {
+	Status string         `json:"status"`
+	Feed   RSS2JSONFeed   `json:"feed"`
+	Items  []RSS2JSONItem `json:"items"`
+}
+
+// RSS2JSONFeed 为 rss2json.com 的 feed 信息块。
+type RSS2JSONFeed struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Author      string `json:"author"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+// RSS2JSONItem 为 rss2json.com 的条目结构，缺失的字段输出空串，没有 enclosure 时输出 {}。
+type RSS2JSONItem struct {
+	Title       string            `json:"title"`
+	PubDate     string            `json:"pubDate"`
+	Link        string            `json:"link"`
+	GUID        string            `json:"guid"`
+	Author      string            `json:"author"`
+	Thumbnail   string            `json:"thumbnail"`
+	Description string            `json:"description"`
+	Content     string            `json:"content"`
+	Enclosure   RSS2JSONEnclosure `json:"enclosure"`
+	Categories  []string          `json:"categories"`
+}
+
+// RSS2JSONEnclosure 为 rss2json.com 的 enclosure，地址字段名为 link。
+type RSS2JSONEnclosure struct {
+	Link   string `json:"link,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Length int64  `json:"length,omitempty"`
+}
+
+// NewRSS2JSONResponse 将转换结果映射为 rss2json.com 结构：pubDate 为 UTC 的
+// "2006-01-02 15:04:05"，content 缺失时取 description，作者缺失时取 dc:creator。
+// OmitContent 的条目 description 与 content 输出空串，以保持字段齐全。
+func NewRSS2JSONResponse(resp Response) RSS2JSONResponse {
+	out := RSS2JSONResponse{Status: "ok", Items: make([]RSS2JSONItem, 0, len(resp.Items))}
+	if resp.Feed != nil && resp.Feed.Feed != nil {
+		out.Feed = newRSS2JSONFeed(resp.Feed)
+	}
+	for _, item := range resp.Items {
+		if item == nil || item.Item == nil {
+			continue
+		}
+		out.Items = append(out.Items, newRSS2JSONItem(item))
+	}
+	return out
+}
+
+func newRSS2JSONFeed(meta *FeedMeta) RSS2JSONFeed {
+	feed := meta.Feed
+	out := RSS2JSONFeed{
+		URL:         meta.RequestedURL,
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+	}
+	if out.URL == "" {
+		out.URL = feed.FeedLink
+	}
+	if feed.Author != nil {
+		out.Author = feed.Author.Name
+	} else if len(feed.Authors) > 0 && feed.Authors[0] != nil {
+		out.Author = feed.Authors[0].Name
+	}
+	switch {
+	case meta.ImageInfo != nil:
+		out.Image = meta.ImageInfo.URL
+	case feed.Image != nil:
+		out.Image = feed.Image.URL
+	}
+	return out
+}
+
+func newRSS2JSONItem(meta *ItemMeta) RSS2JSONItem {
+	item := meta.Item
+	out := RSS2JSONItem{
+		Title:      item.Title,
+		Link:       item.Link,
+		GUID:       item.GUID,
+		Thumbnail:  meta.Thumbnail,
+		Categories: make([]string, 0, len(item.Categories)),
+	}
+	switch {
+	case item.PublishedParsed != nil:
+		out.PubDate = item.PublishedParsed.In(time.UTC).Format(rss2jsonDateLayout)
+	case item.UpdatedParsed != nil:
+		out.PubDate = item.UpdatedParsed.In(time.UTC).Format(rss2jsonDateLayout)
+	}
+	switch {
+	case item.Author != nil && item.Author.Name != "":
+		out.Author = item.Author.Name
+	case item.DublinCoreExt != nil:
+		out.Author = firstNonEmpty(item.DublinCoreExt.Creator)
+	}
+	if !meta.OmitContent {
+		out.Description = item.Description
+		out.Content = item.Content
+		if strings.TrimSpace(out.Content) == "" {
+			out.Content = item.Description
+		}
+	}
+	if enclosure := primaryEnclosure(item.Enclosures); enclosure != nil {
+		out.Enclosure = RSS2JSONEnclosure{Link: enclosure.URL, Type: enclosure.Type, Length: enclosure.Length}
+	}
+	for _, category := range item.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			out.Categories = append(out.Categories, category)
+		}
+	}
+	return out
+}
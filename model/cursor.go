@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Cursor 记录客户端上次看到的最新条目（GUID 与发布时间），用于只返回其后的新条目。
+// 对外以不透明字符串传递，客户端不应解析其内容。
+type Cursor struct {
+	ID   string
+	Time time.Time
+}
+
+// cursorPayload 为 Cursor 编码前的 JSON 结构，时间以 Unix 纳秒保存，无日期时省略。
+type cursorPayload struct {
+	ID   string `json:"i"`
+	Time int64  `json:"t,omitempty"`
+}
+
+// Encode 将游标编码为 URL 安全的 base64 字符串。
+func (c Cursor) Encode() string {
+	payload := cursorPayload{ID: c.ID}
+	if !c.Time.IsZero() {
+		payload.Time = c.Time.UnixNano()
+	}
+	raw, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseCursor 解析 Encode 生成的游标，空串返回 nil；内容不合法或缺少条目 ID 时返回错误。
+func ParseCursor(raw string) (*Cursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errors.New("invalid cursor encoding")
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.New("invalid cursor payload")
+	}
+	if payload.ID == "" {
+		return nil, errors.New("cursor has no item id")
+	}
+	c := &Cursor{ID: payload.ID}
+	if payload.Time != 0 {
+		c.Time = time.Unix(0, payload.Time).UTC()
+	}
+	return c, nil
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FieldMap 将条目输出的字段名（键）重命名为目标名（值），用于兼容其他转换服务的输出结构。
+// 只作用于条目的顶层字段，字段值保持不变。
+type FieldMap map[string]string
+
+// CompatPubDate 为仅将 published 重命名为 pubDate 的预置映射名。需要与 rss2json.com
+// 完全一致的响应结构时应使用 compat=rss2json，而不是此映射。
+const CompatPubDate = "pubdate"
+
+// fieldMapPresets 为 compat 参数可选的预置映射。
+var fieldMapPresets = map[string]FieldMap{
+	// 保留本服务的结构与取值，只把 published 改名为多数转换服务使用的 pubDate。
+	// 其他服务（如 rssapi.org）的结构未提供预置映射，可通过 FIELD_MAP 自行配置。
+	CompatPubDate: {"published": "pubDate"},
+}
+
+// FieldMapPreset 返回给定名称的预置映射，名称不区分大小写。
+func FieldMapPreset(name string) (FieldMap, bool) {
+	preset, ok := fieldMapPresets[strings.ToLower(strings.TrimSpace(name))]
+	return preset, ok
+}
+
+// ParseFieldMap 解析 JSON 对象形式的字段映射，如 {"published":"pubDate"}；空串返回 nil。
+// 键或目标名为空、两个字段映射到同一目标名时视为配置错误。
+func ParseFieldMap(raw string) (FieldMap, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var m FieldMap
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("invalid field map: %w", err)
+	}
+	targets := make(map[string]string, len(m))
+	for from, to := range m {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("invalid field map entry %q: %q", from, to)
+		}
+		if prev, dup := targets[to]; dup {
+			return nil, fmt.Errorf("fields %q and %q both map to %q", prev, from, to)
+		}
+		targets[to] = from
+	}
+	return m, nil
+}
+
+// apply 按映射重命名 payload 中的字段：先取出全部源字段再统一写入，
+// 因此 a→b 与 b→c 同时存在时互不影响；目标名已存在时被覆盖。
+func (m FieldMap) apply(payload map[string]interface{}) {
+	if len(m) == 0 {
+		return
+	}
+	moved := make(map[string]interface{}, len(m))
+	for from, to := range m {
+		if v, ok := payload[from]; ok {
+			moved[to] = v
+			delete(payload, from)
+		}
+	}
+	for to, v := range moved {
+		payload[to] = v
+	}
+}
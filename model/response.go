@@ -0,0 +1,751 @@
+// Package model 定义 rss2json 对外输出的响应结构，服务端与 client 包共用。
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// APIVersion 定义对外响应结构版本。
+const APIVersion = "v1"
+
+// Feed 表示 RSS/Atom 源的原始结构，直接使用 gofeed.Feed。
+type Feed = gofeed.Feed
+
+// Item 表示 RSS/Atom 文章的原始结构，直接使用 gofeed.Item。
+type Item = gofeed.Item
+
+// FeedMeta 表示去除 items 的 Feed 结构，用于顶层 items 输出。
+type FeedMeta struct {
+	*Feed
+	// ImageInfo 为频道 <image> 的完整信息，gofeed 只保留 url 与 title。
+	ImageInfo *Image
+	// RequestedURL 为规范化后实际抓取的 feed 地址，输出为 requestedUrl。
+	RequestedURL string
+	// Location 为 updated_rfc3339 与 latest_item_date 使用的时区，nil 时为 UTC。
+	Location *time.Location
+	// Stats 为按返回条目计算的汇总信息，nil 时不输出。
+	Stats *FeedStats
+	// AuthorURL 为频道作者的主页（Atom <author><uri>），输出为 author_url，为空时不输出。
+	AuthorURL string
+	// PaymentURL 为频道级 <link rel="payment"> 的地址，输出为 payment_url，为空时不输出。
+	PaymentURL string
+}
+
+// FeedStats 为频道级汇总：条目数、主 enclosure 的总字节数与最新条目时间。
+type FeedStats struct {
+	ItemCount int
+	// TotalEnclosureBytes 为各条目主 enclosure 声明的 length 之和，均未声明时为 0 且不输出。
+	TotalEnclosureBytes int64
+	// LatestItemDate 为条目 published/updated 中最晚的时间，均缺失时为 nil。
+	LatestItemDate *time.Time
+}
+
+// NewFeedStats 汇总返回给客户端的条目。
+func NewFeedStats(items []*ItemMeta) *FeedStats {
+	stats := &FeedStats{ItemCount: len(items)}
+	for _, item := range items {
+		if item == nil || item.Item == nil {
+			continue
+		}
+		if enclosure := primaryEnclosure(item.Enclosures); enclosure != nil {
+			stats.TotalEnclosureBytes += enclosure.Length
+		}
+		for _, ts := range []*time.Time{item.PublishedParsed, item.UpdatedParsed} {
+			if ts != nil && (stats.LatestItemDate == nil || ts.After(*stats.LatestItemDate)) {
+				stats.LatestItemDate = ts
+			}
+		}
+	}
+	return stats
+}
+
+// Image 表示频道 <image> 元素。
+type Image struct {
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Link   string `json:"link,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// hasDetails 判断除 url 外是否还有其他字段。
+func (i *Image) hasDetails() bool {
+	return i != nil && (i.Title != "" || i.Link != "" || i.Width > 0 || i.Height > 0)
+}
+
+// NewFeedMeta 构造 FeedMeta。
+func NewFeedMeta(feed *Feed) *FeedMeta {
+	if feed == nil {
+		return nil
+	}
+	return &FeedMeta{Feed: feed}
+}
+
+// MarshalJSON 移除 items 字段，避免与顶层 items 重复，并附加 requestedUrl。image 仅有 url 时输出字符串，
+// 带有 title/link/width/height 时输出对象。updated_rfc3339 取 updated（RSS lastBuildDate、Atom updated），
+// 缺失时取 published（RSS pubDate）。Stats 非空时附加 item_count、total_enclosure_bytes 与 latest_item_date。
+func (f FeedMeta) MarshalJSON() ([]byte, error) {
+	if f.Feed == nil {
+		return []byte("null"), nil
+	}
+	raw, err := json.Marshal(f.Feed)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	delete(payload, "items")
+	addDublinCore(payload, f.DublinCoreExt)
+	if f.RequestedURL != "" {
+		payload["requestedUrl"] = f.RequestedURL
+	}
+	if f.AuthorURL != "" {
+		payload["author_url"] = f.AuthorURL
+	}
+	if f.PaymentURL != "" {
+		payload["payment_url"] = f.PaymentURL
+	}
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if updated := f.latestTimestamp(); updated != nil {
+		payload["updated_rfc3339"] = updated.In(loc).Format(time.RFC3339)
+	}
+	if f.Stats != nil {
+		payload["item_count"] = f.Stats.ItemCount
+		if f.Stats.TotalEnclosureBytes > 0 {
+			payload["total_enclosure_bytes"] = f.Stats.TotalEnclosureBytes
+		}
+		if f.Stats.LatestItemDate != nil {
+			payload["latest_item_date"] = f.Stats.LatestItemDate.In(loc).Format(time.RFC3339)
+		}
+	}
+	if image, ok := payload["image"].(map[string]interface{}); ok {
+		if f.ImageInfo.hasDetails() {
+			info := *f.ImageInfo
+			if url, ok := image["url"].(string); ok && url != "" {
+				info.URL = url
+			}
+			payload["image"] = info
+		} else if url, ok := image["url"].(string); ok {
+			payload["image"] = url
+		} else {
+			payload["image"] = ""
+		}
+	}
+	return marshalJSONNoEscape(payload)
+}
+
+// UnmarshalJSON 解析 MarshalJSON 的输出，供 Go 客户端解码响应：image 可为字符串或对象，
+// requestedUrl、author_url、payment_url 与 item_count 等汇总字段还原到对应字段。
+func (f *FeedMeta) UnmarshalJSON(data []byte) error {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	if payload == nil {
+		*f = FeedMeta{}
+		return nil
+	}
+	var extra struct {
+		RequestedURL        string     `json:"requestedUrl"`
+		AuthorURL           string     `json:"author_url"`
+		PaymentURL          string     `json:"payment_url"`
+		ItemCount           *int       `json:"item_count"`
+		TotalEnclosureBytes int64      `json:"total_enclosure_bytes"`
+		LatestItemDate      *time.Time `json:"latest_item_date"`
+	}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	var image *Image
+	if raw, ok := payload["image"]; ok {
+		var url string
+		if err := json.Unmarshal(raw, &url); err == nil {
+			if url != "" {
+				image = &Image{URL: url}
+			}
+		} else {
+			image = &Image{}
+			if err := json.Unmarshal(raw, image); err != nil {
+				return err
+			}
+		}
+		delete(payload, "image")
+	}
+	feed := &Feed{}
+	if err := remarshal(payload, feed); err != nil {
+		return err
+	}
+	*f = FeedMeta{Feed: feed, RequestedURL: extra.RequestedURL, AuthorURL: extra.AuthorURL, PaymentURL: extra.PaymentURL}
+	if image != nil {
+		feed.Image = &gofeed.Image{URL: image.URL, Title: image.Title}
+		if image.hasDetails() {
+			f.ImageInfo = image
+		}
+	}
+	if extra.ItemCount != nil {
+		f.Stats = &FeedStats{ItemCount: *extra.ItemCount, TotalEnclosureBytes: extra.TotalEnclosureBytes, LatestItemDate: extra.LatestItemDate}
+	}
+	return nil
+}
+
+// latestTimestamp 返回频道级最可信的更新时间，优先 updated，其次 published。
+func (f FeedMeta) latestTimestamp() *time.Time {
+	if f.UpdatedParsed != nil {
+		return f.UpdatedParsed
+	}
+	return f.PublishedParsed
+}
+
+// Link 表示 Atom entry 中携带 rel/type 的链接。
+type Link struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// Enclosure 表示条目主附件（第一个 enclosure）的规范化结构。
+type Enclosure struct {
+	URL    string `json:"url"`
+	Type   string `json:"type,omitempty"`
+	Length int64  `json:"length,omitempty"`
+}
+
+// ItemMeta 表示对外保留字段的 Item 结构。
+type ItemMeta struct {
+	*Item
+	Thumbnail string
+	Links     []Link
+	// AuthorURL 为条目作者的主页（Atom <author><uri>），输出为 author_url，为空时不输出。
+	AuthorURL string
+	// Location 为 published_rfc3339/updated_rfc3339 使用的时区，nil 时为 UTC。
+	Location *time.Location
+	// OmitContent 为 true 时不输出 content（含 content:encoded）与 description。
+	OmitContent bool
+	// Summary 为去除 HTML 后截断的单行摘要，为空时不输出。
+	Summary string
+	// RawDC 为 true 时附加原始的 dc:creator 与 dc:date（dcCreator、dcDate）。
+	RawDC bool
+	// Prefer 为 PreferContent 或 PreferDescription 时输出统一的 body 字段，为空时不输出。
+	Prefer string
+	// FieldMap 非空时按映射重命名输出字段，在其余字段生成之后应用。
+	FieldMap FieldMap
+}
+
+// body 字段的来源偏好。
+const (
+	PreferContent     = "content"
+	PreferDescription = "description"
+)
+
+// content_format 的取值：条目 content 与 description 的输出格式。
+const (
+	ContentFormatHTML     = "html"
+	ContentFormatMarkdown = "markdown"
+)
+
+// preferredBody 按 Prefer 取 content 或 description，首选为空时取另一个。
+func (i ItemMeta) preferredBody() string {
+	first, second := i.Content, i.Description
+	if i.Prefer == PreferDescription {
+		first, second = second, first
+	}
+	if strings.TrimSpace(first) != "" {
+		return first
+	}
+	return second
+}
+
+// NewItemMeta 构造 ItemMeta。
+func NewItemMeta(item *Item, thumbnail string) *ItemMeta {
+	if item == nil {
+		return nil
+	}
+	return &ItemMeta{Item: item, Thumbnail: thumbnail}
+}
+
+// MarshalJSON 将 author 扁平化为字符串，以带 rel/type 的结构覆盖 links，
+// 将解析后的时间输出为 published_unix/updated_unix 秒级时间戳与按 Location 换算的
+// published_rfc3339/updated_rfc3339，并提取主 enclosure；OmitContent 时省略正文字段，
+// RawDC 时附加原始 dcCreator 与 dcDate；Prefer 非空时附加按偏好选取的 body，不受 OmitContent 影响；
+// 最后按 FieldMap 重命名字段。
+func (i ItemMeta) MarshalJSON() ([]byte, error) {
+	if i.Item == nil {
+		return []byte("null"), nil
+	}
+	raw, err := json.Marshal(i.Item)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	if author, ok := payload["author"]; ok {
+		if authorMap, ok := author.(map[string]interface{}); ok {
+			if name, ok := authorMap["name"].(string); ok {
+				payload["author"] = name
+			} else {
+				payload["author"] = ""
+			}
+		}
+	}
+	delete(payload, "publishedParsed")
+	delete(payload, "updatedParsed")
+	if i.Prefer != "" {
+		if body := i.preferredBody(); strings.TrimSpace(body) != "" {
+			payload["body"] = body
+		}
+	}
+	if i.OmitContent {
+		delete(payload, "content")
+		delete(payload, "description")
+	}
+	loc := i.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if i.PublishedParsed != nil {
+		payload["published_unix"] = i.PublishedParsed.Unix()
+		payload["published_rfc3339"] = i.PublishedParsed.In(loc).Format(time.RFC3339)
+	}
+	if i.UpdatedParsed != nil {
+		payload["updated_unix"] = i.UpdatedParsed.Unix()
+		payload["updated_rfc3339"] = i.UpdatedParsed.In(loc).Format(time.RFC3339)
+	}
+	addDublinCore(payload, i.DublinCoreExt)
+	if i.RawDC && i.DublinCoreExt != nil {
+		if creator := firstNonEmpty(i.DublinCoreExt.Creator); creator != "" {
+			payload["dcCreator"] = creator
+		}
+		if date := firstNonEmpty(i.DublinCoreExt.Date); date != "" {
+			payload["dcDate"] = date
+		}
+	}
+	if i.Summary != "" {
+		payload["summary"] = i.Summary
+	}
+	if strings.TrimSpace(i.Thumbnail) != "" {
+		payload["thumbnail"] = i.Thumbnail
+	}
+	if len(i.Links) > 0 {
+		payload["links"] = i.Links
+	}
+	if i.AuthorURL != "" {
+		payload["author_url"] = i.AuthorURL
+	}
+	if enclosure := primaryEnclosure(i.Enclosures); enclosure != nil {
+		payload["enclosure"] = enclosure
+	}
+	i.FieldMap.apply(payload)
+	return marshalJSONNoEscape(payload)
+}
+
+// UnmarshalJSON 解析 MarshalJSON 的输出，供 Go 客户端解码响应：author 字符串还原为 Person，
+// links 还原为 Links 与 Item.Links，published_rfc3339/updated_rfc3339 还原为解析后的时间。
+// 按 FieldMap 重命名的字段无法还原，保持为空。
+func (i *ItemMeta) UnmarshalJSON(data []byte) error {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	if payload == nil {
+		*i = ItemMeta{}
+		return nil
+	}
+	var extra struct {
+		Thumbnail string     `json:"thumbnail"`
+		Summary   string     `json:"summary"`
+		AuthorURL string     `json:"author_url"`
+		Published *time.Time `json:"published_rfc3339"`
+		Updated   *time.Time `json:"updated_rfc3339"`
+	}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	var author *gofeed.Person
+	if raw, ok := payload["author"]; ok {
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			if name != "" {
+				author = &gofeed.Person{Name: name}
+			}
+		} else {
+			author = &gofeed.Person{}
+			if err := json.Unmarshal(raw, author); err != nil {
+				return err
+			}
+		}
+		delete(payload, "author")
+	}
+	var links []Link
+	if raw, ok := payload["links"]; ok {
+		if err := json.Unmarshal(raw, &links); err != nil {
+			var hrefs []string
+			if err := json.Unmarshal(raw, &hrefs); err != nil {
+				return err
+			}
+			links = nil
+			for _, href := range hrefs {
+				links = append(links, Link{Href: href})
+			}
+		}
+		delete(payload, "links")
+	}
+	item := &Item{}
+	if err := remarshal(payload, item); err != nil {
+		return err
+	}
+	item.Author = author
+	item.PublishedParsed = extra.Published
+	item.UpdatedParsed = extra.Updated
+	for _, link := range links {
+		item.Links = append(item.Links, link.Href)
+	}
+	*i = ItemMeta{Item: item, Thumbnail: extra.Thumbnail, Links: links, Summary: extra.Summary, AuthorURL: extra.AuthorURL}
+	return nil
+}
+
+// remarshal 将剩余字段重新编码后解码到 out，用于跳过已单独处理的字段。
+func remarshal(payload map[string]json.RawMessage, out interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// addDublinCore 将 dc:subject、dc:publisher、dc:rights 提升为 subjects、publisher、rights 字段，
+// 便于客户端分类；空值不输出。
+func addDublinCore(payload map[string]interface{}, dc *ext.DublinCoreExtension) {
+	if dc == nil {
+		return
+	}
+	var subjects []string
+	for _, subject := range dc.Subject {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) > 0 {
+		payload["subjects"] = subjects
+	}
+	if publisher := firstNonEmpty(dc.Publisher); publisher != "" {
+		payload["publisher"] = publisher
+	}
+	if rights := firstNonEmpty(dc.Rights); rights != "" {
+		payload["rights"] = rights
+	}
+}
+
+func firstNonEmpty(values []string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// primaryEnclosure 返回第一个带 URL 的 enclosure，length 无法解析时省略。
+func primaryEnclosure(enclosures []*gofeed.Enclosure) *Enclosure {
+	for _, enc := range enclosures {
+		if enc == nil || strings.TrimSpace(enc.URL) == "" {
+			continue
+		}
+		length, _ := strconv.ParseInt(strings.TrimSpace(enc.Length), 10, 64)
+		if length < 0 {
+			length = 0
+		}
+		return &Enclosure{URL: strings.TrimSpace(enc.URL), Type: strings.TrimSpace(enc.Type), Length: length}
+	}
+	return nil
+}
+
+func marshalJSONNoEscape(payload interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	if len(data) > 0 && data[len(data)-1] == '\n' {
+		data = data[:len(data)-1]
+	}
+	return data, nil
+}
+
+// Response 表示 API 的统一返回结构。
+type Response struct {
+	Status  string      `json:"status"`
+	Version string      `json:"version"`
+	Feed    *FeedMeta   `json:"feed,omitempty"`
+	Items   []*ItemMeta `json:"items,omitempty"`
+	Message string      `json:"message,omitempty"`
+	// Error 为错误响应的机器可读信息；Message 保留以兼容旧客户端。
+	Error *ErrorInfo `json:"error,omitempty"`
+	// Partial 表示 feed 内容被截断，仅返回截断前能解析的条目。
+	Partial bool `json:"partial,omitempty"`
+	// Warnings 为解析成功但不规范的问题提示，便于反馈给 feed 发布方。
+	Warnings []string `json:"warnings,omitempty"`
+	Meta     *Meta    `json:"meta,omitempty"`
+	// TTL 为 feed 通过 <ttl> 或 sy:updatePeriod 声明的更新间隔，仅用于生成缓存响应头。
+	TTL time.Duration `json:"-"`
+	// PollAfterSeconds 为建议的下次轮询间隔（秒），取 feed <ttl> 与上游 Cache-Control max-age 中较大者。
+	PollAfterSeconds int `json:"poll_after_seconds,omitempty"`
+}
+
+// ErrorInfo 描述错误响应，Code 为稳定的错误码。
+type ErrorInfo struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Details *ErrorDetails `json:"details,omitempty"`
+}
+
+// ErrorDetails 为错误的附加信息，按错误类型出现。
+type ErrorDetails struct {
+	// UpstreamStatus 为上游返回的 HTTP 状态码（upstream_status）。
+	UpstreamStatus int `json:"upstream_status,omitempty"`
+	// RetryAfter 为建议重试前等待的秒数，与 Retry-After 响应头一致。
+	RetryAfter int `json:"retry_after,omitempty"`
+	// BodySnippet 为内容无法解析时响应体开头的片段，仅在 debug_options=1 时返回。
+	BodySnippet string `json:"body_snippet,omitempty"`
+}
+
+// Meta 表示转换成功时附带的元信息；Options 仅在 debug_options=1 时输出。
+type Meta struct {
+	// TotalItems 为按 count 截断前解析出的条目数，ReturnedItems 为实际返回的条目数。
+	TotalItems    int `json:"totalItems"`
+	ReturnedItems int `json:"returnedItems"`
+	// FeedBytes 为下载的 feed 原始字节数，缓存命中时为缓存内容的大小。
+	FeedBytes int `json:"feedBytes"`
+	// FetchMs 为抓取上游（或读取缓存）的耗时，单位毫秒，带小数。
+	FetchMs float64 `json:"fetchMs"`
+	// ParseMs 为解析 feed 的耗时（含截断恢复），单位毫秒，带小数；与 FetchMs 之和即上游耗时。
+	ParseMs float64 `json:"parseMs"`
+	// Cursor 指向本次返回的最新条目，下次请求以 cursor 参数传回即只返回其后的新条目。
+	Cursor  string            `json:"cursor,omitempty"`
+	Options *EffectiveOptions `json:"options,omitempty"`
+}
+
+// EffectiveOptions 表示合并服务端默认值、客户端参数与服务端强制项之后实际生效的转换选项。
+type EffectiveOptions struct {
+	Count             int      `json:"count"`
+	Sanitize          bool     `json:"sanitize"`
+	StripTracking     bool     `json:"strip_tracking"`
+	MinifyHTML        bool     `json:"minify_html,omitempty"`
+	OmitContent       bool     `json:"omit_content,omitempty"`
+	OmitFeed          bool     `json:"omit_feed,omitempty"`
+	SummaryLen        int      `json:"summary_len,omitempty"`
+	RawDC             bool     `json:"raw_dc,omitempty"`
+	Prefer            string   `json:"prefer,omitempty"`
+	FieldMap          FieldMap `json:"field_map,omitempty"`
+	ContentFormat     string   `json:"content_format,omitempty"`
+	ResolveShortlinks bool     `json:"resolve_shortlinks,omitempty"`
+	MaxBytes          int64    `json:"max_bytes,omitempty"`
+	Insecure          bool     `json:"insecure,omitempty"`
+}
+
+// DebugConfig 表示 /api/v1/debug 的结果：按查询参数与服务端配置解析出的实际生效选项，不抓取 feed。
+type DebugConfig struct {
+	Status   string            `json:"status"`
+	Version  string            `json:"version"`
+	URL      string            `json:"url"`
+	Format   string            `json:"format"`
+	Timezone string            `json:"tz"`
+	Options  *EffectiveOptions `json:"options"`
+	// FetchTimeoutSeconds 为出站抓取的整体超时（秒），0 表示未设置。
+	FetchTimeoutSeconds float64 `json:"fetch_timeout_seconds"`
+	// MaxBytes 为实际生效的内容大小上限（max_bytes 或 RSS_MAX_BYTES）。
+	MaxBytes int64      `json:"max_bytes"`
+	Cache    DebugCache `json:"cache"`
+}
+
+// DebugCache 描述上游缓存是否开启及其 TTL。
+type DebugCache struct {
+	Enabled    bool `json:"enabled"`
+	TTLSeconds int  `json:"ttl_seconds,omitempty"`
+}
+
+// CacheFlush 表示 /admin/cache/flush 的清理结果。
+type CacheFlush struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Removed int    `json:"removed"`
+}
+
+// CacheStats 表示 /admin/cache/stats 的结果。Entries 与 Bytes 在缓存后端不支持统计时省略；
+// Hits、Misses 为进程启动以来的累计次数，HitRatio 为命中率（0～1）。
+type CacheStats struct {
+	Status   string  `json:"status"`
+	Version  string  `json:"version"`
+	Enabled  bool    `json:"enabled"`
+	Entries  *int    `json:"entries,omitempty"`
+	Bytes    *int64  `json:"bytes,omitempty"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// CacheKeys 表示 /admin/cache/keys 的一页结果，Next 非空时作为 after 参数获取下一页。
+type CacheKeys struct {
+	Status  string   `json:"status"`
+	Version string   `json:"version"`
+	Keys    []string `json:"keys"`
+	Next    string   `json:"next,omitempty"`
+}
+
+// ConfigReload 表示 /admin/reload 的结果：Applied 为已热更新的配置项，
+// RestartRequired 为已变化但需要重启才能生效的配置项。
+type ConfigReload struct {
+	Status          string   `json:"status"`
+	Version         string   `json:"version"`
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// AdminStats 表示 /admin/stats 的结果：进程启动以来按上游主机统计的转换结果。
+type AdminStats struct {
+	Status  string     `json:"status"`
+	Version string     `json:"version"`
+	Hosts   []HostStat `json:"hosts"`
+}
+
+// PrefetchStatus 表示 /admin/prefetch 的结果：PREFETCH_URLS 中各 feed 的预热状态。
+type PrefetchStatus struct {
+	Status  string         `json:"status"`
+	Version string         `json:"version"`
+	Feeds   []PrefetchFeed `json:"feeds"`
+}
+
+// PrefetchFeed 为单个预热 feed 的最近结果。Failures 为连续失败次数，成功后清零；
+// NextRunAt 为下一次计划预热的时间，运行中时为 nil。
+type PrefetchFeed struct {
+	URL           string     `json:"url"`
+	Running       bool       `json:"running"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastErrorCode string     `json:"last_error_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	Failures      int        `json:"failures"`
+}
+
+// HostStat 为单个上游主机的转换计数，ErrorsByCode 按错误码（如 fetch_timeout）分类失败次数。
+type HostStat struct {
+	Host          string           `json:"host"`
+	Requests      int64            `json:"requests"`
+	Successes     int64            `json:"successes"`
+	Errors        int64            `json:"errors"`
+	ErrorsByCode  map[string]int64 `json:"errors_by_code,omitempty"`
+	LastErrorAt   *time.Time       `json:"last_error_at,omitempty"`
+	LastErrorCode string           `json:"last_error_code,omitempty"`
+}
+
+// Validation 表示 /api/v1/validate 的校验摘要，不包含条目内容。
+type Validation struct {
+	Status    string   `json:"status"`
+	Version   string   `json:"version"`
+	Valid     bool     `json:"valid"`
+	FeedType  string   `json:"feed_type,omitempty"`
+	ItemCount int      `json:"item_count"`
+	Errors    []string `json:"errors"`
+	Warnings  []string `json:"warnings,omitempty"`
+	// Diagnostics 为抓取与解析过程中记录的诊断信息，便于排查无法解析的 feed。
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+}
+
+// Diagnostics 描述上游内容的类型、编码、XML 良构检查与解析结果。
+type Diagnostics struct {
+	ContentType string `json:"content_type,omitempty"`
+	Bytes       int    `json:"bytes"`
+	// Encoding 为检测到的字符编码，EncodingSource 为其来源：bom、content-type、xml-declaration 或 default。
+	Encoding       string `json:"encoding"`
+	EncodingSource string `json:"encoding_source"`
+	// FeedTypeGuess 为按内容开头推测的类型：rss、atom、json、html 或 unknown。
+	FeedTypeGuess string `json:"feed_type_guess"`
+	// WellFormed 为按 XML 逐个 token 扫描的结果，JSON Feed 不做检查。
+	WellFormed bool             `json:"well_formed"`
+	XMLError   *DiagnosticError `json:"xml_error,omitempty"`
+	ParseError *DiagnosticError `json:"parse_error,omitempty"`
+	// FeedMissing 为频道缺少的必需元素，ItemsMissing 为缺少各元素的条目数。
+	FeedMissing  []string       `json:"feed_missing,omitempty"`
+	ItemsMissing map[string]int `json:"items_missing,omitempty"`
+}
+
+// DiagnosticError 为带位置信息的错误，位置未知时为 0。
+type DiagnosticError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// FeedSetList 表示 /api/v1/feeds 返回的服务端预置 feed 集合列表。
+type FeedSetList struct {
+	Status  string        `json:"status"`
+	Version string        `json:"version"`
+	Sets    []FeedSetInfo `json:"sets"`
+}
+
+// FeedSetInfo 描述一个预置集合包含的 feed。
+type FeedSetInfo struct {
+	Name string   `json:"name"`
+	URLs []string `json:"urls"`
+}
+
+// FeedSet 表示 /api/v1/feeds?set=<name> 的分组结果，每个 feed 单独给出成功或失败。
+type FeedSet struct {
+	Status    string         `json:"status"`
+	Version   string         `json:"version"`
+	Set       string         `json:"set"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Feeds     []FeedSetEntry `json:"feeds"`
+}
+
+// MultiResponse 为主接口传入多个 url 时的分组结果，Results 与请求中 url 的顺序一致。
+type MultiResponse struct {
+	Status  string        `json:"status"`
+	Version string        `json:"version"`
+	Results []MultiResult `json:"results"`
+}
+
+// MultiResult 为单个 url 的转换结果，字段与单 url 响应相同，失败时带 error。
+type MultiResult struct {
+	URL string `json:"url"`
+	Response
+}
+
+// Job 表示回调任务的状态，State 为 pending、delivered 或 failed。
+type Job struct {
+	Status      string    `json:"status"`
+	Version     string    `json:"version"`
+	ID          string    `json:"id"`
+	State       string    `json:"state"`
+	URL         string    `json:"url"`
+	CallbackURL string    `json:"callback_url"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FeedSetEntry 为集合中单个 feed 的转换结果，失败时只有 status 与 message。
+type FeedSetEntry struct {
+	URL     string      `json:"url"`
+	Status  string      `json:"status"`
+	Message string      `json:"message,omitempty"`
+	Feed    *FeedMeta   `json:"feed,omitempty"`
+	Items   []*ItemMeta `json:"items,omitempty"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// itemMarshalAllocBudget 为单个典型条目序列化允许的最大分配次数（当前约 125 次，留约三成余量）。
+// MarshalJSON 先序列化 gofeed.Item 再解码为 map 调整字段，分配数随字段数线性增长；
+// 超出预算说明序列化路径出现了回归，确有必要时再调整预算。
+const itemMarshalAllocBudget = 160
+
+func benchmarkItem() *ItemMeta {
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &ItemMeta{
+		Item: &gofeed.Item{
+			Title:           "Item title",
+			Description:     `<p>Summary with <a href="https://example.com/a">a link</a>.</p>`,
+			Content:         `<div><p>Paragraph one.</p><p>Paragraph two.</p></div>`,
+			Link:            "https://example.com/posts/1",
+			Links:           []string{"https://example.com/posts/1"},
+			Published:       "Mon, 01 Jan 2024 00:00:00 GMT",
+			PublishedParsed: &published,
+			Author:          &gofeed.Person{Name: "Jane Doe"},
+			Authors:         []*gofeed.Person{{Name: "Jane Doe"}},
+			GUID:            "https://example.com/posts/1",
+			Categories:      []string{"go", "rss"},
+			Enclosures:      []*gofeed.Enclosure{{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: "1000"}},
+		},
+		Thumbnail: "https://example.com/thumb.jpg",
+		Summary:   "Summary with a link.",
+	}
+}
+
+func BenchmarkItemMetaMarshal(b *testing.B) {
+	item := benchmarkItem()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(item); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestItemMetaMarshalAllocations(t *testing.T) {
+	item := benchmarkItem()
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := json.Marshal(item); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > itemMarshalAllocBudget {
+		t.Fatalf("item marshaling allocated %.0f times per run, budget is %d", allocs, itemMarshalAllocBudget)
+	}
+}
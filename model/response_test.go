@@ -0,0 +1,370 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestItemMetaMarshalJSONFlattensAuthor(t *testing.T) {
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title:  "Hello",
+			Author: &gofeed.Person{Name: "张三"},
+		},
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if payload["author"] != "张三" {
+		t.Fatalf("expected author to be flattened, got %v", payload["author"])
+	}
+}
+
+func TestItemMetaMarshalJSONAddsThumbnail(t *testing.T) {
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title: "Hello",
+		},
+		Thumbnail: "https://example.com/thumb.jpg",
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if payload["thumbnail"] != "https://example.com/thumb.jpg" {
+		t.Fatalf("expected thumbnail to be set, got %v", payload["thumbnail"])
+	}
+}
+
+func TestItemMetaMarshalJSONDropsParsedTimes(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title:           "Hello",
+			PublishedParsed: &now,
+			UpdatedParsed:   &now,
+		},
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if _, ok := payload["publishedParsed"]; ok {
+		t.Fatalf("publishedParsed should be removed")
+	}
+	if _, ok := payload["updatedParsed"]; ok {
+		t.Fatalf("updatedParsed should be removed")
+	}
+}
+
+func TestItemMetaMarshalJSONUnixTimestamps(t *testing.T) {
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title:           "Hello",
+			Published:       "Mon, 01 Jan 2024 00:00:00 GMT",
+			PublishedParsed: &published,
+			Updated:         "not a date",
+		},
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if got, ok := payload["published_unix"].(float64); !ok || int64(got) != published.Unix() {
+		t.Fatalf("expected published_unix %d, got %v", published.Unix(), payload["published_unix"])
+	}
+	if _, ok := payload["updated_unix"]; ok {
+		t.Fatalf("updated_unix should be omitted for unparseable dates")
+	}
+	if got := payload["published_rfc3339"]; got != "2024-01-01T00:00:00Z" {
+		t.Fatalf("expected published_rfc3339 in UTC, got %v", got)
+	}
+	if _, ok := payload["updated_rfc3339"]; ok {
+		t.Fatalf("updated_rfc3339 should be omitted for unparseable dates")
+	}
+}
+
+func TestItemMetaMarshalJSONPrimaryEnclosure(t *testing.T) {
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title: "Episode 1",
+			Enclosures: []*gofeed.Enclosure{
+				{URL: "https://example.com/ep1.mp3", Type: "audio/mpeg", Length: "12345678"},
+				{URL: "https://example.com/ep1.ogg", Type: "audio/ogg", Length: "999"},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var payload struct {
+		Enclosure *Enclosure `json:"enclosure"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	want := Enclosure{URL: "https://example.com/ep1.mp3", Type: "audio/mpeg", Length: 12345678}
+	if payload.Enclosure == nil || *payload.Enclosure != want {
+		t.Fatalf("unexpected enclosure: %+v", payload.Enclosure)
+	}
+
+	raw, err = json.Marshal(ItemMeta{Item: &gofeed.Item{Title: "No media"}})
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var empty map[string]interface{}
+	if err := json.Unmarshal(raw, &empty); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if _, ok := empty["enclosure"]; ok {
+		t.Fatalf("enclosure should be omitted without enclosures")
+	}
+}
+
+func TestItemMetaMarshalJSONPreferredBody(t *testing.T) {
+	cases := []struct {
+		name        string
+		prefer      string
+		content     string
+		description string
+		omit        bool
+		want        interface{}
+	}{
+		{"content preferred", PreferContent, "<p>Full</p>", "Short", false, "<p>Full</p>"},
+		{"description preferred", PreferDescription, "<p>Full</p>", "Short", false, "Short"},
+		{"content falls back", PreferContent, "  ", "Short", false, "Short"},
+		{"description falls back", PreferDescription, "<p>Full</p>", "", false, "<p>Full</p>"},
+		{"both empty", PreferContent, "", "", false, nil},
+		{"no preference", "", "<p>Full</p>", "Short", false, nil},
+		{"kept when content omitted", PreferDescription, "<p>Full</p>", "Short", true, "Short"},
+	}
+	for _, tc := range cases {
+		meta := ItemMeta{
+			Item:        &gofeed.Item{Title: "Hello", Content: tc.content, Description: tc.description},
+			Prefer:      tc.prefer,
+			OmitContent: tc.omit,
+		}
+		raw, err := json.Marshal(meta)
+		if err != nil {
+			t.Fatalf("%s: marshal error: %v", tc.name, err)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			t.Fatalf("%s: unmarshal error: %v", tc.name, err)
+		}
+		if payload["body"] != tc.want {
+			t.Fatalf("%s: expected body %v, got %v", tc.name, tc.want, payload["body"])
+		}
+		if tc.omit {
+			if _, ok := payload["description"]; ok {
+				t.Fatalf("%s: expected description omitted", tc.name)
+			}
+		} else if tc.description != "" && payload["description"] != tc.description {
+			t.Fatalf("%s: expected description kept, got %v", tc.name, payload["description"])
+		}
+	}
+}
+
+func TestItemMetaMarshalJSONCompatPreset(t *testing.T) {
+	preset, ok := FieldMapPreset("PubDate")
+	if !ok {
+		t.Fatal("expected pubdate preset")
+	}
+	published := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	meta := ItemMeta{
+		Item: &gofeed.Item{
+			Title:           "Hello",
+			Link:            "https://example.com/1",
+			GUID:            "https://example.com/1",
+			Description:     "Short",
+			Content:         "<p>Full</p>",
+			Published:       "Tue, 02 Jan 2024 15:04:05 GMT",
+			PublishedParsed: &published,
+			Categories:      []string{"go"},
+			Enclosures:      []*gofeed.Enclosure{{URL: "https://example.com/a.mp3", Type: "audio/mpeg"}},
+		},
+		Thumbnail: "https://example.com/t.jpg",
+		FieldMap:  preset,
+	}
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	for _, key := range []string{"title", "pubDate", "link", "guid", "thumbnail", "description", "content", "enclosure", "categories"} {
+		if _, ok := payload[key]; !ok {
+			t.Fatalf("expected key %q in %s", key, raw)
+		}
+	}
+	if _, ok := payload["published"]; ok {
+		t.Fatalf("expected published to be renamed, got %s", raw)
+	}
+	if payload["pubDate"] != "Tue, 02 Jan 2024 15:04:05 GMT" {
+		t.Fatalf("expected pubDate to keep the original value, got %v", payload["pubDate"])
+	}
+}
+
+func TestParseFieldMap(t *testing.T) {
+	m, err := ParseFieldMap(`{"published":"pubDate","enclosure":"media"}`)
+	if err != nil || m["enclosure"] != "media" || len(m) != 2 {
+		t.Fatalf("unexpected result %v %v", m, err)
+	}
+	if m, err := ParseFieldMap("  "); err != nil || m != nil {
+		t.Fatalf("expected empty input to yield nil, got %v %v", m, err)
+	}
+	for _, raw := range []string{`["published"]`, `{"published":""}`, `{"a":"x","b":"x"}`} {
+		if _, err := ParseFieldMap(raw); err == nil {
+			t.Fatalf("expected error for %s", raw)
+		}
+	}
+
+	// 交换两个字段名时互不覆盖。
+	payload := map[string]interface{}{"a": 1, "b": 2}
+	FieldMap{"a": "b", "b": "a"}.apply(payload)
+	if payload["a"] != 2 || payload["b"] != 1 {
+		t.Fatalf("expected swapped fields, got %v", payload)
+	}
+}
+
+func TestFeedMetaMarshalJSONImage(t *testing.T) {
+	feed := &gofeed.Feed{Title: "Feed", Image: &gofeed.Image{URL: "https://example.com/logo.png"}}
+
+	raw, err := json.Marshal(NewFeedMeta(feed))
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if payload["image"] != "https://example.com/logo.png" {
+		t.Fatalf("expected url-only image to stay a string, got %v", payload["image"])
+	}
+
+	meta := NewFeedMeta(feed)
+	meta.ImageInfo = &Image{URL: "https://example.com/logo.png", Width: 88, Height: 31}
+	raw, err = json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	payload = nil
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	image, ok := payload["image"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected image object, got %v", payload["image"])
+	}
+	if image["url"] != "https://example.com/logo.png" || image["width"] != float64(88) || image["height"] != float64(31) {
+		t.Fatalf("unexpected image object: %v", image)
+	}
+}
+
+func TestFeedMetaMarshalJSONUpdatedLocation(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 4, 30, 0, 0, time.UTC)
+	meta := NewFeedMeta(&gofeed.Feed{Title: "Feed", UpdatedParsed: &updated})
+	meta.Location = time.FixedZone("CST", 8*3600)
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if payload["updated_rfc3339"] != "2024-01-01T12:30:00+08:00" {
+		t.Fatalf("expected updated_rfc3339 in location, got %v", payload["updated_rfc3339"])
+	}
+}
+
+func TestResponseUnmarshalJSONRoundTrip(t *testing.T) {
+	published := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	resp := Response{
+		Status:  "ok",
+		Version: APIVersion,
+		Feed: &FeedMeta{
+			Feed:         &gofeed.Feed{Title: "Feed", Image: &gofeed.Image{URL: "https://example.com/logo.png"}},
+			ImageInfo:    &Image{URL: "https://example.com/logo.png", Width: 88},
+			RequestedURL: "https://example.com/rss",
+			AuthorURL:    "https://example.com/me",
+			Stats:        &FeedStats{ItemCount: 1},
+		},
+		Items: []*ItemMeta{{
+			Item: &gofeed.Item{
+				Title:           "Hello",
+				Author:          &gofeed.Person{Name: "张三"},
+				PublishedParsed: &published,
+			},
+			Links:     []Link{{Href: "https://example.com/post", Rel: "alternate"}},
+			Thumbnail: "https://example.com/thumb.jpg",
+			Summary:   "Hi",
+		}},
+	}
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var decoded Response
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	feed := decoded.Feed
+	if feed == nil || feed.Title != "Feed" || feed.RequestedURL != "https://example.com/rss" || feed.AuthorURL != "https://example.com/me" {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	if feed.ImageInfo == nil || feed.ImageInfo.Width != 88 || feed.Image.URL != "https://example.com/logo.png" || feed.Stats == nil || feed.Stats.ItemCount != 1 {
+		t.Fatalf("unexpected feed image or stats: %+v %+v", feed.ImageInfo, feed.Stats)
+	}
+	item := decoded.Items[0]
+	if item.Author == nil || item.Author.Name != "张三" || item.Thumbnail != "https://example.com/thumb.jpg" || item.Summary != "Hi" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	if len(item.Links) != 1 || item.Links[0].Rel != "alternate" || item.Item.Links[0] != "https://example.com/post" {
+		t.Fatalf("unexpected links %+v / %v", item.Links, item.Item.Links)
+	}
+	if item.PublishedParsed == nil || !item.PublishedParsed.Equal(published) {
+		t.Fatalf("expected published time restored, got %v", item.PublishedParsed)
+	}
+
+	again, err := json.Marshal(decoded)
+	if err != nil || string(again) != string(raw) {
+		t.Fatalf("expected re-encoding to match:\n%s\n%s", raw, again)
+	}
+}